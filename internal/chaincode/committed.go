@@ -0,0 +1,108 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultEndorsementPolicy is the channel configuration policy used by Fabric when a chaincode definition does not
+// specify an endorsement policy.
+const defaultEndorsementPolicy = "/Channel/Application/Endorsement"
+
+// Committed is a chaincode definition reported by a committed chaincode definition query.
+type Committed interface {
+	GetSequence() int64
+	GetVersion() string
+	GetEndorsementPlugin() string
+	GetValidationPlugin() string
+	GetValidationParameter() []byte
+	GetCollections() *peer.CollectionConfigPackage
+	GetInitRequired() bool
+}
+
+// Differences describes how a committed chaincode definition differs from this definition, ignoring the sequence
+// number. Unspecified plugins, endorsement policy and collections are treated as the Fabric defaults.
+func (d *Definition) Differences(committed Committed) ([]string, error) {
+	var results []string
+
+	if committed.GetVersion() != d.Version {
+		results = append(results, fmt.Sprintf("version %s != %s", committed.GetVersion(), d.Version))
+	}
+
+	committedEndorsementPlugin := (&Definition{EndorsementPlugin: committed.GetEndorsementPlugin()}).EndorsementPluginOrDefault()
+	if committedEndorsementPlugin != d.EndorsementPluginOrDefault() {
+		results = append(results, "endorsement plugin changed")
+	}
+
+	committedValidationPlugin := (&Definition{ValidationPlugin: committed.GetValidationPlugin()}).ValidationPluginOrDefault()
+	if committedValidationPlugin != d.ValidationPluginOrDefault() {
+		results = append(results, "validation plugin changed")
+	}
+
+	policyChanged, err := d.endorsementPolicyChanged(committed.GetValidationParameter())
+	if err != nil {
+		return nil, err
+	}
+	if policyChanged {
+		results = append(results, "endorsement policy changed")
+	}
+
+	if !collectionsEqual(committed.GetCollections(), d.Collections) {
+		results = append(results, "collections changed")
+	}
+
+	if committed.GetInitRequired() != d.InitRequired {
+		results = append(results, "init required changed")
+	}
+
+	return results, nil
+}
+
+func (d *Definition) endorsementPolicyChanged(committedParameter []byte) (bool, error) {
+	committedPolicy, err := applicationPolicyOrDefault(committedParameter)
+	if err != nil {
+		return false, fmt.Errorf("failed to deserialize committed endorsement policy: %w", err)
+	}
+
+	validationParameter, err := d.ValidationParameter()
+	if err != nil {
+		return false, err
+	}
+
+	policy, err := applicationPolicyOrDefault(validationParameter)
+	if err != nil {
+		return false, err
+	}
+
+	return !proto.Equal(committedPolicy, policy), nil
+}
+
+func applicationPolicyOrDefault(validationParameter []byte) (*peer.ApplicationPolicy, error) {
+	if len(validationParameter) == 0 {
+		return &peer.ApplicationPolicy{
+			Type: &peer.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: defaultEndorsementPolicy},
+		}, nil
+	}
+
+	policy := &peer.ApplicationPolicy{}
+	if err := proto.Unmarshal(validationParameter, policy); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func collectionsEqual(a *peer.CollectionConfigPackage, b *peer.CollectionConfigPackage) bool {
+	if len(a.GetConfig()) == 0 || len(b.GetConfig()) == 0 {
+		return len(a.GetConfig()) == len(b.GetConfig())
+	}
+
+	return proto.Equal(a, b)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chaincode
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bestbeforetoday/fabric-admin/pkg/policydsl"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultEndorsementPlugin = "escc"
+	defaultValidationPlugin  = "vscc"
+)
+
+// Definition of a chaincode, as approved by organizations and committed to a channel.
+type Definition struct {
+	Name                string
+	Version             string
+	Sequence            int64
+	EndorsementPlugin   string
+	ValidationPlugin    string
+	SignaturePolicy     string
+	ChannelConfigPolicy string
+	ApplicationPolicy   *peer.ApplicationPolicy
+	Collections         *peer.CollectionConfigPackage
+	InitRequired        bool
+}
+
+// Validate that the definition contains the required fields.
+func (d *Definition) Validate() error {
+	if d.Name == "" {
+		return errors.New("no chaincode name supplied")
+	}
+	if d.Version == "" {
+		return errors.New("no chaincode version supplied")
+	}
+	if d.Sequence < 1 {
+		return fmt.Errorf("invalid sequence number: %d", d.Sequence)
+	}
+	if d.policyCount() > 1 {
+		return errors.New("more than one endorsement policy supplied")
+	}
+
+	return nil
+}
+
+// HasEndorsementPolicy returns true if an endorsement policy is specified.
+func (d *Definition) HasEndorsementPolicy() bool {
+	return d.policyCount() > 0
+}
+
+func (d *Definition) policyCount() int {
+	count := 0
+	if d.SignaturePolicy != "" {
+		count++
+	}
+	if d.ChannelConfigPolicy != "" {
+		count++
+	}
+	if d.ApplicationPolicy != nil {
+		count++
+	}
+
+	return count
+}
+
+// EndorsementPluginOrDefault returns the endorsement plugin name, or the default system plugin if none is specified.
+func (d *Definition) EndorsementPluginOrDefault() string {
+	if d.EndorsementPlugin == "" {
+		return defaultEndorsementPlugin
+	}
+	return d.EndorsementPlugin
+}
+
+// ValidationPluginOrDefault returns the validation plugin name, or the default system plugin if none is specified.
+func (d *Definition) ValidationPluginOrDefault() string {
+	if d.ValidationPlugin == "" {
+		return defaultValidationPlugin
+	}
+	return d.ValidationPlugin
+}
+
+// ValidationParameter returns the serialized application endorsement policy, or nil if no policy is specified, in
+// which case the channel's default endorsement policy is used.
+func (d *Definition) ValidationParameter() ([]byte, error) {
+	if d.ApplicationPolicy != nil {
+		return proto.Marshal(d.ApplicationPolicy)
+	}
+
+	policy := &peer.ApplicationPolicy{}
+
+	switch {
+	case d.SignaturePolicy != "":
+		signaturePolicy, err := policydsl.FromString(d.SignaturePolicy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature policy: %w", err)
+		}
+		policy.Type = &peer.ApplicationPolicy_SignaturePolicy{
+			SignaturePolicy: signaturePolicy,
+		}
+	case d.ChannelConfigPolicy != "":
+		policy.Type = &peer.ApplicationPolicy_ChannelConfigPolicyReference{
+			ChannelConfigPolicyReference: d.ChannelConfigPolicy,
+		}
+	default:
+		return nil, nil
+	}
+
+	return proto.Marshal(policy)
+}
@@ -5,7 +5,10 @@ SPDX-License-Identifier: Apache-2.0
 
 package common
 
-const LifecycleChaincodeName = "_lifecycle"
+const (
+	LifecycleChaincodeName     = "_lifecycle"
+	ConfigurationChaincodeName = "cscc"
+)
 
 func ApplyOptions[T any, O ~func(*T) error](target *T, options ...O) error {
 	for _, option := range options {
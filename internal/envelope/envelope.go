@@ -0,0 +1,138 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package envelope
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Creator returns the serialized identity used as the creator of messages signed by the supplied identity.
+func Creator(id gatewayid.Identity) ([]byte, error) {
+	serializedIdentity := &msp.SerializedIdentity{
+		Mspid:   id.MspID(),
+		IdBytes: id.Credentials(),
+	}
+	return proto.Marshal(serializedIdentity)
+}
+
+// NewNonce creates a random nonce.
+func NewNonce() ([]byte, error) {
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return nonce, nil
+}
+
+// TransactionID returns the transaction ID derived from the nonce and creator of the supplied signature header.
+func TransactionID(signatureHeader *common.SignatureHeader) string {
+	saltedCreator := append(append([]byte{}, signatureHeader.GetNonce()...), signatureHeader.GetCreator()...)
+	rawTransactionID := sha256.Sum256(saltedCreator)
+	return hex.EncodeToString(rawTransactionID[:])
+}
+
+// NewSignatureHeader creates a signature header containing the creator and a random nonce.
+func NewSignatureHeader(id gatewayid.Identity) (*common.SignatureHeader, error) {
+	nonce, err := NewNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	creator, err := Creator(id)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureHeader := &common.SignatureHeader{
+		Creator: creator,
+		Nonce:   nonce,
+	}
+	return signatureHeader, nil
+}
+
+// NewChannelHeader creates a channel header of the specified type for the named channel.
+func NewChannelHeader(headerType common.HeaderType, channelName string) *common.ChannelHeader {
+	return &common.ChannelHeader{
+		Type:      int32(headerType),
+		Timestamp: timestamppb.Now(),
+		ChannelId: channelName,
+		Epoch:     0,
+	}
+}
+
+// NewSigned creates an envelope containing the supplied channel header and data, signed by the supplied identity.
+func NewSigned(ctx context.Context, signingID identity.SigningIdentity, channelHeader *common.ChannelHeader, data proto.Message) (*common.Envelope, error) {
+	signatureHeader, err := NewSignatureHeader(signingID)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadBytes, err := payloadBytes(channelHeader, signatureHeader, data)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := identity.SignContext(ctx, signingID, payloadBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := &common.Envelope{
+		Payload:   payloadBytes,
+		Signature: signature,
+	}
+	return envelope, nil
+}
+
+// NewUnsigned creates an envelope containing the supplied headers and data, with no signature.
+func NewUnsigned(channelHeader *common.ChannelHeader, signatureHeader *common.SignatureHeader, data proto.Message) (*common.Envelope, error) {
+	payloadBytes, err := payloadBytes(channelHeader, signatureHeader, data)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := &common.Envelope{
+		Payload: payloadBytes,
+	}
+	return envelope, nil
+}
+
+func payloadBytes(channelHeader *common.ChannelHeader, signatureHeader *common.SignatureHeader, data proto.Message) ([]byte, error) {
+	channelHeaderBytes, err := proto.Marshal(channelHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureHeaderBytes, err := proto.Marshal(signatureHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	dataBytes, err := proto.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &common.Payload{
+		Header: &common.Header{
+			ChannelHeader:   channelHeaderBytes,
+			SignatureHeader: signatureHeaderBytes,
+		},
+		Data: dataBytes,
+	}
+	return proto.Marshal(payload)
+}
@@ -7,20 +7,21 @@ package proposal
 
 import (
 	admincommon "github.com/bestbeforetoday/fabric-admin/internal/common"
-	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
 	"github.com/hyperledger/fabric-protos-go-apiv2/common"
 	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// New creates a proposal, created by the supplied identity, to invoke a chaincode transaction.
 func New(
-	signingID identity.SigningIdentity,
+	id gatewayid.Identity,
 	chaincodeName string,
 	transactionName string,
 	options ...Option,
 ) (*peer.Proposal, error) {
-	transactionCtx, err := newTransactionContext(signingID)
+	transactionCtx, err := newTransactionContext(id)
 	if err != nil {
 		return nil, err
 	}
@@ -45,6 +46,7 @@ type builder struct {
 	transactionCtx  *transactionContext
 	transient       map[string][]byte
 	args            [][]byte
+	tlsCertHash     []byte
 }
 
 func (b *builder) build() (*peer.Proposal, error) {
@@ -94,12 +96,13 @@ func (b *builder) channelHeaderBytes() ([]byte, error) {
 	}
 
 	channelHeader := &common.ChannelHeader{
-		Type:      int32(common.HeaderType_ENDORSER_TRANSACTION),
-		Timestamp: timestamppb.Now(),
-		ChannelId: b.channelName,
-		TxId:      b.transactionCtx.TransactionID,
-		Epoch:     0,
-		Extension: extensionBytes,
+		Type:        int32(common.HeaderType_ENDORSER_TRANSACTION),
+		Timestamp:   timestamppb.Now(),
+		ChannelId:   b.channelName,
+		TxId:        b.transactionCtx.TransactionID,
+		Epoch:       0,
+		Extension:   extensionBytes,
+		TlsCertHash: b.tlsCertHash,
 	}
 	return proto.Marshal(channelHeader)
 }
@@ -177,3 +180,12 @@ func WithChannel(channelName string) Option {
 		return nil
 	}
 }
+
+// WithTLSCertHash specifies the hash of the client TLS certificate, which binds a transaction proposal to a mutual TLS
+// connection.
+func WithTLSCertHash(hash []byte) Option {
+	return func(b *builder) error {
+		b.tlsCertHash = hash
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proposal
+
+import (
+	"context"
+
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// Bytes returns the deterministic serialization of a proposal, which is the message that must be signed.
+func Bytes(proposal *peer.Proposal) ([]byte, error) {
+	return proto.MarshalOptions{Deterministic: true}.Marshal(proposal)
+}
+
+// NewSigned creates a signed proposal from a proposal and a signature of its serialized bytes.
+func NewSigned(proposal *peer.Proposal, signature []byte) (*peer.SignedProposal, error) {
+	proposalBytes, err := Bytes(proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	signedProposal := &peer.SignedProposal{
+		ProposalBytes: proposalBytes,
+		Signature:     signature,
+	}
+	return signedProposal, nil
+}
+
+// Sign a proposal using the supplied signing identity.
+func Sign(ctx context.Context, signingID identity.SigningIdentity, proposal *peer.Proposal) (*peer.SignedProposal, error) {
+	proposalBytes, err := Bytes(proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := identity.SignContext(ctx, signingID, proposalBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signedProposal := &peer.SignedProposal{
+		ProposalBytes: proposalBytes,
+		Signature:     signature,
+	}
+	return signedProposal, nil
+}
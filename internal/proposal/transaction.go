@@ -0,0 +1,178 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proposal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/envelope"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewTransactionPayload creates an unsigned transaction payload from a proposal and the endorsed responses to that
+// proposal. All responses must be successful and contain identical response payloads.
+func NewTransactionPayload(proposal *peer.Proposal, responses ...*peer.ProposalResponse) (*common.Payload, error) {
+	if len(responses) == 0 {
+		return nil, errors.New("no proposal responses supplied")
+	}
+
+	header := &common.Header{}
+	if err := proto.Unmarshal(proposal.GetHeader(), header); err != nil {
+		return nil, fmt.Errorf("failed to deserialize proposal header: %w", err)
+	}
+
+	responsePayload, endorsements, err := endorsementsFromResponses(responses)
+	if err != nil {
+		return nil, err
+	}
+
+	chaincodeProposalPayloadBytes, err := transactionChaincodeProposalPayloadBytes(proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	actionPayloadBytes, err := proto.Marshal(&peer.ChaincodeActionPayload{
+		ChaincodeProposalPayload: chaincodeProposalPayloadBytes,
+		Action: &peer.ChaincodeEndorsedAction{
+			ProposalResponsePayload: responsePayload,
+			Endorsements:            endorsements,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transactionBytes, err := proto.Marshal(&peer.Transaction{
+		Actions: []*peer.TransactionAction{
+			{
+				Header:  header.GetSignatureHeader(),
+				Payload: actionPayloadBytes,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &common.Payload{
+		Header: header,
+		Data:   transactionBytes,
+	}
+	return payload, nil
+}
+
+// TransactionPayloadBytes returns the deterministic serialization of a transaction payload, which is the message that
+// must be signed.
+func TransactionPayloadBytes(payload *common.Payload) ([]byte, error) {
+	return proto.MarshalOptions{Deterministic: true}.Marshal(payload)
+}
+
+// NewSignedTransaction creates a transaction envelope from a transaction payload and a signature of its serialized
+// bytes.
+func NewSignedTransaction(payload *common.Payload, signature []byte) (*common.Envelope, error) {
+	payloadBytes, err := TransactionPayloadBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := &common.Envelope{
+		Payload:   payloadBytes,
+		Signature: signature,
+	}
+	return transaction, nil
+}
+
+// NewTransaction creates a transaction envelope from a proposal and the endorsed responses to that proposal, signed
+// using the supplied signing identity. The signing identity must be the creator of the proposal.
+func NewTransaction(
+	ctx context.Context,
+	signingID identity.SigningIdentity,
+	proposal *peer.Proposal,
+	responses ...*peer.ProposalResponse,
+) (*common.Envelope, error) {
+	payload, err := NewTransactionPayload(proposal, responses...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkCreator(signingID, payload.GetHeader()); err != nil {
+		return nil, err
+	}
+
+	payloadBytes, err := TransactionPayloadBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := identity.SignContext(ctx, signingID, payloadBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := &common.Envelope{
+		Payload:   payloadBytes,
+		Signature: signature,
+	}
+	return transaction, nil
+}
+
+func endorsementsFromResponses(responses []*peer.ProposalResponse) ([]byte, []*peer.Endorsement, error) {
+	responsePayload := responses[0].GetPayload()
+	endorsements := make([]*peer.Endorsement, 0, len(responses))
+
+	for i, response := range responses {
+		if err := CheckSuccessfulResponse(response); err != nil {
+			return nil, nil, fmt.Errorf("proposal response %d: %w", i, err)
+		}
+		if !bytes.Equal(responsePayload, response.GetPayload()) {
+			return nil, nil, fmt.Errorf("proposal response %d payload does not match proposal response 0", i)
+		}
+		if response.GetEndorsement() == nil {
+			return nil, nil, fmt.Errorf("proposal response %d has no endorsement", i)
+		}
+
+		endorsements = append(endorsements, response.GetEndorsement())
+	}
+
+	return responsePayload, endorsements, nil
+}
+
+// transactionChaincodeProposalPayloadBytes returns the chaincode proposal payload with the transient data removed, as
+// transient data must not be recorded in the ledger.
+func transactionChaincodeProposalPayloadBytes(proposal *peer.Proposal) ([]byte, error) {
+	chaincodeProposalPayload := &peer.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.GetPayload(), chaincodeProposalPayload); err != nil {
+		return nil, fmt.Errorf("failed to deserialize chaincode proposal payload: %w", err)
+	}
+
+	return proto.Marshal(&peer.ChaincodeProposalPayload{
+		Input: chaincodeProposalPayload.GetInput(),
+	})
+}
+
+func checkCreator(signingID identity.SigningIdentity, header *common.Header) error {
+	signatureHeader := &common.SignatureHeader{}
+	if err := proto.Unmarshal(header.GetSignatureHeader(), signatureHeader); err != nil {
+		return fmt.Errorf("failed to deserialize proposal signature header: %w", err)
+	}
+
+	creator, err := envelope.Creator(signingID)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(creator, signatureHeader.GetCreator()) {
+		return errors.New("signing identity is not the proposal creator")
+	}
+
+	return nil
+}
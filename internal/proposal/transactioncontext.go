@@ -6,14 +6,9 @@ SPDX-License-Identifier: Apache-2.0
 package proposal
 
 import (
-	"crypto/rand"
-	"crypto/sha256"
-	"encoding/hex"
-
-	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	"github.com/bestbeforetoday/fabric-admin/internal/envelope"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
 	"github.com/hyperledger/fabric-protos-go-apiv2/common"
-	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
-	"google.golang.org/protobuf/proto"
 )
 
 type transactionContext struct {
@@ -21,32 +16,14 @@ type transactionContext struct {
 	SignatureHeader *common.SignatureHeader
 }
 
-func newTransactionContext(signingID identity.SigningIdentity) (*transactionContext, error) {
-	nonce := make([]byte, 24)
-	if _, err := rand.Read(nonce); err != nil {
-		return nil, err
-	}
-
-	serializedIdentity := &msp.SerializedIdentity{
-		Mspid:   signingID.MspID(),
-		IdBytes: signingID.Credentials(),
-	}
-	creator, err := proto.Marshal(serializedIdentity)
+func newTransactionContext(id gatewayid.Identity) (*transactionContext, error) {
+	signatureHeader, err := envelope.NewSignatureHeader(id)
 	if err != nil {
 		return nil, err
 	}
 
-	saltedCreator := append(nonce, creator...)
-	rawTransactionID := sha256.Sum256(saltedCreator)
-	transactionID := hex.EncodeToString(rawTransactionID[:])
-
-	signatureHeader := &common.SignatureHeader{
-		Creator: creator,
-		Nonce:   nonce,
-	}
-
 	transactionCtx := &transactionContext{
-		TransactionID:   transactionID,
+		TransactionID:   envelope.TransactionID(signatureHeader),
 		SignatureHeader: signatureHeader,
 	}
 	return transactionCtx, nil
@@ -0,0 +1,162 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package submit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/envelope"
+	"github.com/bestbeforetoday/fabric-admin/internal/proposal"
+	"github.com/bestbeforetoday/fabric-admin/pkg/commitstatus"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Gateway submits transactions using the Fabric Gateway service of a peer. The Gateway service selects the endorsing
+// peers and orderer, so only a connection to a single peer is required.
+type Gateway struct {
+	Client                 gateway.GatewayClient
+	CallOptions            []grpc.CallOption
+	EndorsingOrganizations []string
+	// Submitted, if set, is called with the transaction ID once the transaction has been submitted to the orderer, and
+	// before its commit status is obtained.
+	Submitted func(transactionID string)
+}
+
+// Submit a proposal for endorsement, submit the endorsed transaction to the orderer, and wait for it to be committed.
+// An error is returned if the transaction is not committed successfully.
+func (g *Gateway) Submit(ctx context.Context, signingID identity.SigningIdentity, unsignedProposal *peer.Proposal) (*commitstatus.Status, error) {
+	if g.Client == nil {
+		return nil, errors.New("no gRPC client supplied")
+	}
+
+	channelHeader, err := proposalChannelHeader(unsignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	signedProposal, err := proposal.Sign(ctx, signingID, unsignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	preparedTransaction, err := g.endorse(ctx, channelHeader, signedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	if preparedTransaction.Signature, err = identity.SignContext(ctx, signingID, preparedTransaction.GetPayload()); err != nil {
+		return nil, err
+	}
+
+	submitRequest := &gateway.SubmitRequest{
+		TransactionId:       channelHeader.GetTxId(),
+		ChannelId:           channelHeader.GetChannelId(),
+		PreparedTransaction: preparedTransaction,
+	}
+	if _, err = g.Client.Submit(ctx, submitRequest, g.CallOptions...); err != nil {
+		return nil, fmt.Errorf("failed to submit transaction %s: %w", channelHeader.GetTxId(), err)
+	}
+
+	if g.Submitted != nil {
+		g.Submitted(channelHeader.GetTxId())
+	}
+
+	status, err := g.commitStatus(ctx, signingID, channelHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	if status.GetResult() != peer.TxValidationCode_VALID {
+		return nil, fmt.Errorf("transaction %s failed to commit with status code %d (%s)",
+			channelHeader.GetTxId(), status.GetResult(), status.GetResult().String())
+	}
+
+	result := &commitstatus.Status{
+		TransactionID: channelHeader.GetTxId(),
+		BlockNumber:   status.GetBlockNumber(),
+		Code:          status.GetResult(),
+	}
+	return result, nil
+}
+
+func (g *Gateway) endorse(ctx context.Context, channelHeader *common.ChannelHeader, signedProposal *peer.SignedProposal) (*common.Envelope, error) {
+	endorseRequest := &gateway.EndorseRequest{
+		TransactionId:          channelHeader.GetTxId(),
+		ChannelId:              channelHeader.GetChannelId(),
+		ProposedTransaction:    signedProposal,
+		EndorsingOrganizations: g.EndorsingOrganizations,
+	}
+
+	response, err := g.Client.Endorse(ctx, endorseRequest, g.CallOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to endorse transaction %s: %w", channelHeader.GetTxId(), err)
+	}
+
+	preparedTransaction := response.GetPreparedTransaction()
+	if preparedTransaction == nil {
+		return nil, fmt.Errorf("no prepared transaction in endorse response for transaction %s", channelHeader.GetTxId())
+	}
+
+	return preparedTransaction, nil
+}
+
+func (g *Gateway) commitStatus(
+	ctx context.Context,
+	signingID identity.SigningIdentity,
+	channelHeader *common.ChannelHeader,
+) (*gateway.CommitStatusResponse, error) {
+	creator, err := envelope.Creator(signingID)
+	if err != nil {
+		return nil, err
+	}
+
+	requestBytes, err := proto.Marshal(&gateway.CommitStatusRequest{
+		TransactionId: channelHeader.GetTxId(),
+		ChannelId:     channelHeader.GetChannelId(),
+		Identity:      creator,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := identity.SignContext(ctx, signingID, requestBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signedRequest := &gateway.SignedCommitStatusRequest{
+		Request:   requestBytes,
+		Signature: signature,
+	}
+
+	response, err := g.Client.CommitStatus(ctx, signedRequest, g.CallOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain commit status for transaction %s: %w", channelHeader.GetTxId(), err)
+	}
+
+	return response, nil
+}
+
+func proposalChannelHeader(unsignedProposal *peer.Proposal) (*common.ChannelHeader, error) {
+	header := &common.Header{}
+	if err := proto.Unmarshal(unsignedProposal.GetHeader(), header); err != nil {
+		return nil, fmt.Errorf("failed to deserialize proposal header: %w", err)
+	}
+
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(header.GetChannelHeader(), channelHeader); err != nil {
+		return nil, fmt.Errorf("failed to deserialize proposal channel header: %w", err)
+	}
+
+	return channelHeader, nil
+}
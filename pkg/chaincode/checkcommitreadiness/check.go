@@ -0,0 +1,287 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package checkcommitreadiness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/chaincode"
+	"github.com/bestbeforetoday/fabric-admin/internal/common"
+	"github.com/bestbeforetoday/fabric-admin/internal/proposal"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const checkCommitReadinessTransactionName = "CheckCommitReadiness"
+
+// Check which channel member organizations have approved a chaincode definition. The result maps each organization's
+// MSP ID to whether it has approved the definition.
+func Check(ctx context.Context, signingID identity.SigningIdentity, options ...Option) (map[string]bool, error) {
+	checkCommand := &command{}
+
+	if err := common.ApplyOptions(checkCommand, options...); err != nil {
+		return nil, err
+	}
+
+	return checkCommand.run(ctx, signingID)
+}
+
+// NewProposal creates an unsigned check commit readiness proposal for the supplied identity. The proposal can be signed
+// separately, such as on an offline machine, and then submitted using Submit.
+func NewProposal(id gatewayid.Identity, options ...Option) (*peer.Proposal, error) {
+	checkCommand := &command{}
+
+	if err := common.ApplyOptions(checkCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := checkCommand.validateProposal(); err != nil {
+		return nil, err
+	}
+
+	return checkCommand.newProposal(id)
+}
+
+// Submit a signed check commit readiness proposal, created using NewProposal, to a peer.
+func Submit(ctx context.Context, signedProposal *peer.SignedProposal, options ...Option) (map[string]bool, error) {
+	checkCommand := &command{}
+
+	if err := common.ApplyOptions(checkCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := checkCommand.validateSubmit(); err != nil {
+		return nil, err
+	}
+
+	return checkCommand.submit(ctx, signedProposal)
+}
+
+type command struct {
+	grpcClient  peer.EndorserClient
+	grpcOptions []grpc.CallOption
+	channelName string
+	definition  chaincode.Definition
+	tlsCertHash []byte
+}
+
+func (c *command) run(ctx context.Context, signingID identity.SigningIdentity) (map[string]bool, error) {
+	if err := c.validateSubmit(); err != nil {
+		return nil, err
+	}
+	if err := c.validateProposal(); err != nil {
+		return nil, err
+	}
+
+	unsignedProposal, err := c.newProposal(signingID)
+	if err != nil {
+		return nil, err
+	}
+
+	signedProposal, err := proposal.Sign(ctx, signingID, unsignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.submit(ctx, signedProposal)
+}
+
+func (c *command) validateSubmit() error {
+	if c.grpcClient == nil {
+		return errors.New("no gRPC client supplied")
+	}
+
+	return nil
+}
+
+func (c *command) validateProposal() error {
+	if c.channelName == "" {
+		return errors.New("no channel name supplied")
+	}
+
+	return c.definition.Validate()
+}
+
+func (c *command) newProposal(id gatewayid.Identity) (*peer.Proposal, error) {
+	argBytes, err := c.checkCommitReadinessArgsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return proposal.New(
+		id,
+		common.LifecycleChaincodeName,
+		checkCommitReadinessTransactionName,
+		proposal.WithChannel(c.channelName),
+		proposal.WithBytesArguments(argBytes),
+		proposal.WithTLSCertHash(c.tlsCertHash),
+	)
+}
+
+func (c *command) submit(ctx context.Context, signedProposal *peer.SignedProposal) (map[string]bool, error) {
+	proposalResponse, err := c.grpcClient.ProcessProposal(ctx, signedProposal, c.grpcOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = proposal.CheckSuccessfulResponse(proposalResponse); err != nil {
+		return nil, err
+	}
+
+	result := &lifecycle.CheckCommitReadinessResult{}
+	if err = proto.Unmarshal(proposalResponse.GetResponse().GetPayload(), result); err != nil {
+		return nil, fmt.Errorf("failed to deserialize check commit readiness result: %w", err)
+	}
+
+	return result.GetApprovals(), nil
+}
+
+func (c *command) checkCommitReadinessArgsBytes() ([]byte, error) {
+	validationParameter, err := c.definition.ValidationParameter()
+	if err != nil {
+		return nil, err
+	}
+
+	checkArgs := &lifecycle.CheckCommitReadinessArgs{
+		Sequence:            c.definition.Sequence,
+		Name:                c.definition.Name,
+		Version:             c.definition.Version,
+		EndorsementPlugin:   c.definition.EndorsementPluginOrDefault(),
+		ValidationPlugin:    c.definition.ValidationPluginOrDefault(),
+		ValidationParameter: validationParameter,
+		Collections:         c.definition.Collections,
+		InitRequired:        c.definition.InitRequired,
+	}
+	return proto.Marshal(checkArgs)
+}
+
+type Option = func(*command) error
+
+// WithClientConnection uses the supplied gRPC client connection to a peer. This should be shared by all commands
+// connecting to the same network node.
+func WithClientConnection(clientConnection grpc.ClientConnInterface) Option {
+	return func(c *command) error {
+		c.grpcClient = peer.NewEndorserClient(clientConnection)
+		return nil
+	}
+}
+
+// WithCallOptions specifies the gRPC call options to be used.
+func WithCallOptions(options ...grpc.CallOption) Option {
+	return func(c *command) error {
+		c.grpcOptions = append(c.grpcOptions, options...)
+		return nil
+	}
+}
+
+// WithChannel specifies the name of the channel on which the chaincode definition would be committed.
+func WithChannel(channelName string) Option {
+	return func(c *command) error {
+		c.channelName = channelName
+		return nil
+	}
+}
+
+// WithChaincodeName specifies the name of the chaincode.
+func WithChaincodeName(name string) Option {
+	return func(c *command) error {
+		c.definition.Name = name
+		return nil
+	}
+}
+
+// WithVersion specifies the version of the chaincode.
+func WithVersion(version string) Option {
+	return func(c *command) error {
+		c.definition.Version = version
+		return nil
+	}
+}
+
+// WithSequence specifies the sequence number of the chaincode definition.
+func WithSequence(sequence int64) Option {
+	return func(c *command) error {
+		c.definition.Sequence = sequence
+		return nil
+	}
+}
+
+// WithSignaturePolicy specifies the chaincode endorsement policy as a signature policy expression, such as
+// "OR('Org1MSP.peer','Org2MSP.peer')".
+func WithSignaturePolicy(policy string) Option {
+	return func(c *command) error {
+		c.definition.SignaturePolicy = policy
+		return nil
+	}
+}
+
+// WithChannelConfigPolicy specifies the chaincode endorsement policy as a reference to a channel configuration policy,
+// such as "/Channel/Application/Endorsement".
+func WithChannelConfigPolicy(policy string) Option {
+	return func(c *command) error {
+		c.definition.ChannelConfigPolicy = policy
+		return nil
+	}
+}
+
+// WithApplicationPolicy specifies the chaincode endorsement policy as an application policy, such as the validation
+// parameter of a previously committed chaincode definition.
+func WithApplicationPolicy(policy *peer.ApplicationPolicy) Option {
+	return func(c *command) error {
+		c.definition.ApplicationPolicy = policy
+		return nil
+	}
+}
+
+// WithCollections specifies the private data collection configuration for the chaincode.
+func WithCollections(collections *peer.CollectionConfigPackage) Option {
+	return func(c *command) error {
+		c.definition.Collections = collections
+		return nil
+	}
+}
+
+// WithInitRequired specifies whether the chaincode requires an Init transaction to be invoked before other
+// transactions.
+func WithInitRequired(initRequired bool) Option {
+	return func(c *command) error {
+		c.definition.InitRequired = initRequired
+		return nil
+	}
+}
+
+// WithEndorsementPlugin specifies the name of the endorsement plugin. If not specified, the default system endorsement
+// plugin is used.
+func WithEndorsementPlugin(plugin string) Option {
+	return func(c *command) error {
+		c.definition.EndorsementPlugin = plugin
+		return nil
+	}
+}
+
+// WithValidationPlugin specifies the name of the validation plugin. If not specified, the default system validation
+// plugin is used.
+func WithValidationPlugin(plugin string) Option {
+	return func(c *command) error {
+		c.definition.ValidationPlugin = plugin
+		return nil
+	}
+}
+
+// WithTLSCertHash specifies the hash of the client TLS certificate used to connect to the peer. This is required if the
+// peer checks that the proposal is bound to the mutual TLS connection on which it is received.
+func WithTLSCertHash(hash []byte) Option {
+	return func(c *command) error {
+		c.tlsCertHash = hash
+		return nil
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package checkcommitreadiness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	adminproposal "github.com/bestbeforetoday/fabric-admin/pkg/proposal"
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:generate mockgen -destination ./endorser_mock_test.go -package ${GOPACKAGE} github.com/hyperledger/fabric-protos-go-apiv2/peer EndorserClient
+//go:generate mockgen -destination ./signingidentity_mock_test.go -package ${GOPACKAGE} github.com/bestbeforetoday/fabric-admin/pkg/identity SigningIdentity
+
+func WithEndorserClient(grpcClient peer.EndorserClient) Option {
+	return func(c *command) error {
+		c.grpcClient = grpcClient
+		return nil
+	}
+}
+
+func NewSigningIdentity(controller *gomock.Controller, signature []byte) *MockSigningIdentity {
+	mockIdentity := NewMockSigningIdentity(controller)
+	mockIdentity.EXPECT().MspID().AnyTimes()
+	mockIdentity.EXPECT().Credentials().AnyTimes()
+	mockIdentity.EXPECT().Sign(gomock.Any()).Return(signature, nil).AnyTimes()
+
+	return mockIdentity
+}
+
+func NewProposalResponse(status common.Status, message string) *peer.ProposalResponse {
+	return &peer.ProposalResponse{
+		Response: &peer.Response{
+			Status:  int32(status),
+			Message: message,
+		},
+	}
+}
+
+func AssertMarshal(t *testing.T, m protoreflect.ProtoMessage) []byte {
+	result, err := proto.Marshal(m)
+	require.NoError(t, err)
+	return result
+}
+
+// AssertUnmarshal ensures that a protobuf is umarshaled without error
+func AssertUnmarshal(t *testing.T, b []byte, m protoreflect.ProtoMessage) {
+	err := proto.Unmarshal(b, m)
+	require.NoError(t, err)
+}
+
+// AssertUnmarshalCheckArgs ensures that the check commit readiness arguments are unmarshaled from a signed proposal
+// without error
+func AssertUnmarshalCheckArgs(t *testing.T, signedProposal *peer.SignedProposal) (*common.ChannelHeader, *peer.ChaincodeInput, *lifecycle.CheckCommitReadinessArgs) {
+	proposal := &peer.Proposal{}
+	AssertUnmarshal(t, signedProposal.GetProposalBytes(), proposal)
+
+	header := &common.Header{}
+	AssertUnmarshal(t, proposal.GetHeader(), header)
+
+	channelHeader := &common.ChannelHeader{}
+	AssertUnmarshal(t, header.GetChannelHeader(), channelHeader)
+
+	payload := &peer.ChaincodeProposalPayload{}
+	AssertUnmarshal(t, proposal.GetPayload(), payload)
+
+	invocationSpec := &peer.ChaincodeInvocationSpec{}
+	AssertUnmarshal(t, payload.GetInput(), invocationSpec)
+
+	input := invocationSpec.GetChaincodeSpec().GetInput()
+	require.Len(t, input.GetArgs(), 2)
+
+	args := &lifecycle.CheckCommitReadinessArgs{}
+	AssertUnmarshal(t, input.GetArgs()[1], args)
+
+	return channelHeader, input, args
+}
+
+// AssertProtoEqual ensures an expected protobuf message matches an actual message
+func AssertProtoEqual(t *testing.T, expected protoreflect.ProtoMessage, actual protoreflect.ProtoMessage) {
+	require.True(t, proto.Equal(expected, actual), "Expected %v, got %v", expected, actual)
+}
+
+func DefinitionOptions() []Option {
+	return []Option{
+		WithChannel("CHANNEL"),
+		WithChaincodeName("CHAINCODE"),
+		WithVersion("1.0"),
+		WithSequence(1),
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("Missing gRPC connection gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Check(ctx, NewSigningIdentity(controller, nil), DefinitionOptions()...)
+		require.ErrorContains(t, err, "gRPC")
+	})
+
+	for name, testCase := range map[string]struct {
+		options  []Option
+		expected string
+	}{
+		"channel": {
+			options:  []Option{WithChaincodeName("CHAINCODE"), WithVersion("1.0"), WithSequence(1)},
+			expected: "channel",
+		},
+		"chaincode name": {
+			options:  []Option{WithChannel("CHANNEL"), WithVersion("1.0"), WithSequence(1)},
+			expected: "name",
+		},
+		"sequence": {
+			options:  []Option{WithChannel("CHANNEL"), WithChaincodeName("CHAINCODE"), WithVersion("1.0")},
+			expected: "sequence",
+		},
+	} {
+		t.Run("Missing "+name+" gives error", func(t *testing.T) {
+			controller, ctx := gomock.WithContext(context.Background(), t)
+			defer controller.Finish()
+
+			options := append(testCase.options, WithEndorserClient(NewMockEndorserClient(controller)))
+			_, err := Check(ctx, NewSigningIdentity(controller, nil), options...)
+			require.ErrorContains(t, err, testCase.expected)
+		})
+	}
+
+	t.Run("Endorser client errors returned", func(t *testing.T) {
+		expectedErr := errors.New("EXPECTED_ERROR")
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Return(nil, expectedErr)
+
+		options := append(DefinitionOptions(), WithEndorserClient(mockEndorser))
+		_, err := Check(ctx, NewSigningIdentity(controller, nil), options...)
+		require.ErrorIs(t, err, expectedErr)
+	})
+
+	t.Run("Unsuccessful proposal response gives error", func(t *testing.T) {
+		expectedStatus := common.Status_BAD_REQUEST
+		expectedMessage := "EXPECTED_ERROR"
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Return(NewProposalResponse(expectedStatus, expectedMessage), nil)
+
+		options := append(DefinitionOptions(), WithEndorserClient(mockEndorser))
+		_, err := Check(ctx, NewSigningIdentity(controller, nil), options...)
+
+		require.ErrorContainsf(t, err, fmt.Sprintf("%d", expectedStatus), "status code")
+		require.ErrorContains(t, err, expectedMessage, "message")
+	})
+
+	t.Run("Approvals returned on successful proposal response", func(t *testing.T) {
+		expected := map[string]bool{
+			"Org1MSP": true,
+			"Org2MSP": false,
+		}
+		response := NewProposalResponse(common.Status_SUCCESS, "")
+		response.Response.Payload = AssertMarshal(t, &lifecycle.CheckCommitReadinessResult{Approvals: expected})
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Return(response, nil)
+
+		options := append(DefinitionOptions(), WithEndorserClient(mockEndorser))
+		actual, err := Check(ctx, NewSigningIdentity(controller, nil), options...)
+		require.NoError(t, err)
+
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("Proposal includes chaincode definition", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		var signedProposal *peer.SignedProposal
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, in *peer.SignedProposal, _ ...grpc.CallOption) {
+				signedProposal = in
+			}).
+			Return(NewProposalResponse(common.Status_SUCCESS, ""), nil)
+
+		options := append(
+			DefinitionOptions(),
+			WithEndorserClient(mockEndorser),
+			WithInitRequired(true),
+			WithTLSCertHash([]byte("TLS_CERT_HASH")),
+		)
+		_, err := Check(ctx, NewSigningIdentity(controller, []byte("SIGNATURE")), options...)
+		require.NoError(t, err)
+
+		require.Equal(t, []byte("SIGNATURE"), signedProposal.GetSignature())
+
+		channelHeader, input, args := AssertUnmarshalCheckArgs(t, signedProposal)
+		require.Equal(t, "CHANNEL", channelHeader.GetChannelId())
+		require.Equal(t, []byte("TLS_CERT_HASH"), channelHeader.GetTlsCertHash())
+		require.Equal(t, checkCommitReadinessTransactionName, string(input.GetArgs()[0]))
+
+		expected := &lifecycle.CheckCommitReadinessArgs{
+			Sequence:          1,
+			Name:              "CHAINCODE",
+			Version:           "1.0",
+			EndorsementPlugin: "escc",
+			ValidationPlugin:  "vscc",
+			InitRequired:      true,
+		}
+		AssertProtoEqual(t, expected, args)
+	})
+}
+
+func TestOfflineSigning(t *testing.T) {
+	t.Run("Submits externally signed proposal", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		unsignedProposal, err := NewProposal(NewSigningIdentity(controller, nil), DefinitionOptions()...)
+		require.NoError(t, err)
+
+		proposalBytes, err := adminproposal.Bytes(unsignedProposal)
+		require.NoError(t, err)
+
+		signedProposal, err := adminproposal.NewSignedProposal(unsignedProposal, []byte("OFFLINE_SIGNATURE"))
+		require.NoError(t, err)
+
+		var actual *peer.SignedProposal
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, in *peer.SignedProposal, _ ...grpc.CallOption) {
+				actual = in
+			}).
+			Return(NewProposalResponse(common.Status_SUCCESS, ""), nil)
+
+		_, err = Submit(ctx, signedProposal, WithEndorserClient(mockEndorser))
+		require.NoError(t, err)
+
+		require.Equal(t, proposalBytes, actual.GetProposalBytes())
+		require.Equal(t, []byte("OFFLINE_SIGNATURE"), actual.GetSignature())
+	})
+
+	t.Run("Missing chaincode definition gives error creating proposal", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		_, err := NewProposal(NewSigningIdentity(controller, nil), WithChannel("CHANNEL"))
+		require.Error(t, err)
+	})
+
+	t.Run("Submit without gRPC client gives error", func(t *testing.T) {
+		_, err := Submit(context.Background(), &peer.SignedProposal{})
+		require.ErrorContains(t, err, "gRPC")
+	})
+}
@@ -0,0 +1,247 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package commit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/chaincode"
+	"github.com/bestbeforetoday/fabric-admin/internal/common"
+	"github.com/bestbeforetoday/fabric-admin/internal/proposal"
+	"github.com/bestbeforetoday/fabric-admin/internal/submit"
+	"github.com/bestbeforetoday/fabric-admin/pkg/commitstatus"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const commitTransactionName = "CommitChaincodeDefinition"
+
+// Commit a chaincode definition to a channel. The definition must first be approved by enough organizations to satisfy
+// the channel's lifecycle endorsement policy. The transaction is endorsed, submitted and its commit status checked
+// using the Fabric Gateway service of the connected peer. The returned status identifies the committed transaction and
+// the block that contains it.
+func Commit(ctx context.Context, signingID identity.SigningIdentity, options ...Option) (*commitstatus.Status, error) {
+	commitCommand := &command{}
+
+	if err := common.ApplyOptions(commitCommand, options...); err != nil {
+		return nil, err
+	}
+
+	return commitCommand.run(ctx, signingID)
+}
+
+type command struct {
+	gateway     submit.Gateway
+	channelName string
+	definition  chaincode.Definition
+	tlsCertHash []byte
+}
+
+func (c *command) run(ctx context.Context, signingID identity.SigningIdentity) (*commitstatus.Status, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	unsignedProposal, err := c.newProposal(signingID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.gateway.Submit(ctx, signingID, unsignedProposal)
+}
+
+func (c *command) validate() error {
+	if c.gateway.Client == nil {
+		return errors.New("no gRPC client supplied")
+	}
+	if c.channelName == "" {
+		return errors.New("no channel name supplied")
+	}
+
+	return c.definition.Validate()
+}
+
+func (c *command) newProposal(id gatewayid.Identity) (*peer.Proposal, error) {
+	argBytes, err := c.commitArgsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return proposal.New(
+		id,
+		common.LifecycleChaincodeName,
+		commitTransactionName,
+		proposal.WithChannel(c.channelName),
+		proposal.WithBytesArguments(argBytes),
+		proposal.WithTLSCertHash(c.tlsCertHash),
+	)
+}
+
+func (c *command) commitArgsBytes() ([]byte, error) {
+	validationParameter, err := c.definition.ValidationParameter()
+	if err != nil {
+		return nil, err
+	}
+
+	commitArgs := &lifecycle.CommitChaincodeDefinitionArgs{
+		Sequence:            c.definition.Sequence,
+		Name:                c.definition.Name,
+		Version:             c.definition.Version,
+		EndorsementPlugin:   c.definition.EndorsementPluginOrDefault(),
+		ValidationPlugin:    c.definition.ValidationPluginOrDefault(),
+		ValidationParameter: validationParameter,
+		Collections:         c.definition.Collections,
+		InitRequired:        c.definition.InitRequired,
+	}
+	return proto.Marshal(commitArgs)
+}
+
+type Option = func(*command) error
+
+// WithClientConnection uses the supplied gRPC client connection to a peer providing the Fabric Gateway service. This
+// should be shared by all commands connecting to the same network node.
+func WithClientConnection(clientConnection grpc.ClientConnInterface) Option {
+	return func(c *command) error {
+		c.gateway.Client = gateway.NewGatewayClient(clientConnection)
+		return nil
+	}
+}
+
+// WithCallOptions specifies the gRPC call options to be used.
+func WithCallOptions(options ...grpc.CallOption) Option {
+	return func(c *command) error {
+		c.gateway.CallOptions = append(c.gateway.CallOptions, options...)
+		return nil
+	}
+}
+
+// WithEndorsingOrganizations specifies the MSP IDs of organizations whose peers should endorse the transaction. If not
+// specified, the Gateway service selects endorsing peers to satisfy the channel's lifecycle endorsement policy.
+func WithEndorsingOrganizations(mspIDs ...string) Option {
+	return func(c *command) error {
+		c.gateway.EndorsingOrganizations = append(c.gateway.EndorsingOrganizations, mspIDs...)
+		return nil
+	}
+}
+
+// WithSubmittedHandler specifies a function that is called with the transaction ID once the commit transaction has
+// been submitted to the orderer, and before its commit status is obtained. This allows the transaction to be tracked
+// even if obtaining its commit status fails.
+func WithSubmittedHandler(handler func(transactionID string)) Option {
+	return func(c *command) error {
+		c.gateway.Submitted = handler
+		return nil
+	}
+}
+
+// WithChannel specifies the name of the channel to which the chaincode definition is committed.
+func WithChannel(channelName string) Option {
+	return func(c *command) error {
+		c.channelName = channelName
+		return nil
+	}
+}
+
+// WithChaincodeName specifies the name of the chaincode.
+func WithChaincodeName(name string) Option {
+	return func(c *command) error {
+		c.definition.Name = name
+		return nil
+	}
+}
+
+// WithVersion specifies the version of the chaincode.
+func WithVersion(version string) Option {
+	return func(c *command) error {
+		c.definition.Version = version
+		return nil
+	}
+}
+
+// WithSequence specifies the sequence number of the chaincode definition.
+func WithSequence(sequence int64) Option {
+	return func(c *command) error {
+		c.definition.Sequence = sequence
+		return nil
+	}
+}
+
+// WithSignaturePolicy specifies the chaincode endorsement policy as a signature policy expression, such as
+// "OR('Org1MSP.peer','Org2MSP.peer')".
+func WithSignaturePolicy(policy string) Option {
+	return func(c *command) error {
+		c.definition.SignaturePolicy = policy
+		return nil
+	}
+}
+
+// WithChannelConfigPolicy specifies the chaincode endorsement policy as a reference to a channel configuration policy,
+// such as "/Channel/Application/Endorsement".
+func WithChannelConfigPolicy(policy string) Option {
+	return func(c *command) error {
+		c.definition.ChannelConfigPolicy = policy
+		return nil
+	}
+}
+
+// WithApplicationPolicy specifies the chaincode endorsement policy as an application policy, such as the validation
+// parameter of a previously committed chaincode definition.
+func WithApplicationPolicy(policy *peer.ApplicationPolicy) Option {
+	return func(c *command) error {
+		c.definition.ApplicationPolicy = policy
+		return nil
+	}
+}
+
+// WithCollections specifies the private data collection configuration for the chaincode.
+func WithCollections(collections *peer.CollectionConfigPackage) Option {
+	return func(c *command) error {
+		c.definition.Collections = collections
+		return nil
+	}
+}
+
+// WithInitRequired specifies whether the chaincode requires an Init transaction to be invoked before other
+// transactions.
+func WithInitRequired(initRequired bool) Option {
+	return func(c *command) error {
+		c.definition.InitRequired = initRequired
+		return nil
+	}
+}
+
+// WithEndorsementPlugin specifies the name of the endorsement plugin. If not specified, the default system endorsement
+// plugin is used.
+func WithEndorsementPlugin(plugin string) Option {
+	return func(c *command) error {
+		c.definition.EndorsementPlugin = plugin
+		return nil
+	}
+}
+
+// WithValidationPlugin specifies the name of the validation plugin. If not specified, the default system validation
+// plugin is used.
+func WithValidationPlugin(plugin string) Option {
+	return func(c *command) error {
+		c.definition.ValidationPlugin = plugin
+		return nil
+	}
+}
+
+// WithTLSCertHash specifies the hash of the client TLS certificate used to connect to the peer providing the Fabric
+// Gateway service, which binds the commit proposal to the mutual TLS connection.
+func WithTLSCertHash(hash []byte) Option {
+	return func(c *command) error {
+		c.tlsCertHash = hash
+		return nil
+	}
+}
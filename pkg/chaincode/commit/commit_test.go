@@ -0,0 +1,293 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package commit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:generate mockgen -destination ./gateway_mock_test.go -package ${GOPACKAGE} github.com/hyperledger/fabric-protos-go-apiv2/gateway GatewayClient
+//go:generate mockgen -destination ./signingidentity_mock_test.go -package ${GOPACKAGE} github.com/bestbeforetoday/fabric-admin/pkg/identity SigningIdentity
+
+func WithGatewayClient(grpcClient gateway.GatewayClient) Option {
+	return func(c *command) error {
+		c.gateway.Client = grpcClient
+		return nil
+	}
+}
+
+func NewSigningIdentity(controller *gomock.Controller, signature []byte) *MockSigningIdentity {
+	mockIdentity := NewMockSigningIdentity(controller)
+	mockIdentity.EXPECT().MspID().Return("SIGNER_MSP").AnyTimes()
+	mockIdentity.EXPECT().Credentials().Return([]byte("SIGNER_CREDENTIALS")).AnyTimes()
+	mockIdentity.EXPECT().Sign(gomock.Any()).Return(signature, nil).AnyTimes()
+
+	return mockIdentity
+}
+
+// GatewayRequests records the requests made to a mock Gateway client.
+type GatewayRequests struct {
+	Endorse      *gateway.EndorseRequest
+	Submit       *gateway.SubmitRequest
+	CommitStatus *gateway.SignedCommitStatusRequest
+}
+
+// NewGatewayClient returns a mock Gateway client that endorses and submits any transaction, and reports the supplied
+// commit status code. Requests made to the client are recorded.
+func NewGatewayClient(controller *gomock.Controller, code peer.TxValidationCode, requests *GatewayRequests) *MockGatewayClient {
+	mockGateway := NewMockGatewayClient(controller)
+	mockGateway.EXPECT().Endorse(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, in *gateway.EndorseRequest, _ ...interface{}) {
+			requests.Endorse = in
+		}).
+		Return(&gateway.EndorseResponse{
+			PreparedTransaction: &common.Envelope{Payload: []byte("PREPARED_PAYLOAD")},
+		}, nil).
+		AnyTimes()
+	mockGateway.EXPECT().Submit(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, in *gateway.SubmitRequest, _ ...interface{}) {
+			requests.Submit = in
+		}).
+		Return(&gateway.SubmitResponse{}, nil).
+		AnyTimes()
+	mockGateway.EXPECT().CommitStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, in *gateway.SignedCommitStatusRequest, _ ...interface{}) {
+			requests.CommitStatus = in
+		}).
+		Return(&gateway.CommitStatusResponse{Result: code, BlockNumber: 1}, nil).
+		AnyTimes()
+
+	return mockGateway
+}
+
+// AssertUnmarshal ensures that a protobuf is umarshaled without error
+func AssertUnmarshal(t *testing.T, b []byte, m protoreflect.ProtoMessage) {
+	err := proto.Unmarshal(b, m)
+	require.NoError(t, err)
+}
+
+// AssertProtoEqual ensures an expected protobuf message matches an actual message
+func AssertProtoEqual(t *testing.T, expected protoreflect.ProtoMessage, actual protoreflect.ProtoMessage) {
+	require.True(t, proto.Equal(expected, actual), "Expected %v, got %v", expected, actual)
+}
+
+// AssertUnmarshalCommitArgs ensures that the commit arguments are unmarshaled from a signed proposal without error
+func AssertUnmarshalCommitArgs(t *testing.T, signedProposal *peer.SignedProposal) (*common.ChannelHeader, *peer.ChaincodeInput, *lifecycle.CommitChaincodeDefinitionArgs) {
+	proposal := &peer.Proposal{}
+	AssertUnmarshal(t, signedProposal.GetProposalBytes(), proposal)
+
+	header := &common.Header{}
+	AssertUnmarshal(t, proposal.GetHeader(), header)
+
+	channelHeader := &common.ChannelHeader{}
+	AssertUnmarshal(t, header.GetChannelHeader(), channelHeader)
+
+	payload := &peer.ChaincodeProposalPayload{}
+	AssertUnmarshal(t, proposal.GetPayload(), payload)
+
+	invocationSpec := &peer.ChaincodeInvocationSpec{}
+	AssertUnmarshal(t, payload.GetInput(), invocationSpec)
+
+	input := invocationSpec.GetChaincodeSpec().GetInput()
+	require.Len(t, input.GetArgs(), 2)
+
+	args := &lifecycle.CommitChaincodeDefinitionArgs{}
+	AssertUnmarshal(t, input.GetArgs()[1], args)
+
+	return channelHeader, input, args
+}
+
+func RequiredOptions(mockGateway gateway.GatewayClient) []Option {
+	return []Option{
+		WithGatewayClient(mockGateway),
+		WithChannel("CHANNEL"),
+		WithChaincodeName("CHAINCODE"),
+		WithVersion("1.0"),
+		WithSequence(1),
+	}
+}
+
+func TestCommit(t *testing.T) {
+	t.Run("Missing gRPC connection gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Commit(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithChannel("CHANNEL"),
+			WithChaincodeName("CHAINCODE"),
+			WithVersion("1.0"),
+			WithSequence(1),
+		)
+		require.ErrorContains(t, err, "gRPC")
+	})
+
+	for name, testCase := range map[string]struct {
+		option   func(*command) error
+		expected string
+	}{
+		"channel":  {WithChannel(""), "channel"},
+		"name":     {WithChaincodeName(""), "name"},
+		"version":  {WithVersion(""), "version"},
+		"sequence": {WithSequence(0), "sequence"},
+	} {
+		testCase := testCase
+		t.Run("Missing "+name+" gives error", func(t *testing.T) {
+			controller, ctx := gomock.WithContext(context.Background(), t)
+			defer controller.Finish()
+
+			mockGateway := NewMockGatewayClient(controller)
+			options := append(RequiredOptions(mockGateway), testCase.option)
+
+			_, err := Commit(ctx, NewSigningIdentity(controller, nil), options...)
+			require.ErrorContains(t, err, testCase.expected)
+		})
+	}
+
+	t.Run("Endorses commit proposal for channel", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		requests := &GatewayRequests{}
+		mockGateway := NewGatewayClient(controller, peer.TxValidationCode_VALID, requests)
+
+		options := append(
+			RequiredOptions(mockGateway),
+			WithChannelConfigPolicy("/Channel/Application/Endorsement"),
+			WithEndorsingOrganizations("Org1MSP", "Org2MSP"),
+		)
+
+		_, err := Commit(ctx, NewSigningIdentity(controller, []byte("SIGNATURE")), options...)
+		require.NoError(t, err)
+
+		require.Equal(t, "CHANNEL", requests.Endorse.GetChannelId())
+		require.Equal(t, []string{"Org1MSP", "Org2MSP"}, requests.Endorse.GetEndorsingOrganizations())
+
+		channelHeader, input, args := AssertUnmarshalCommitArgs(t, requests.Endorse.GetProposedTransaction())
+		require.Equal(t, "CHANNEL", channelHeader.GetChannelId())
+		require.Equal(t, commitTransactionName, string(input.GetArgs()[0]))
+
+		validationParameter, err := proto.Marshal(&peer.ApplicationPolicy{
+			Type: &peer.ApplicationPolicy_ChannelConfigPolicyReference{
+				ChannelConfigPolicyReference: "/Channel/Application/Endorsement",
+			},
+		})
+		require.NoError(t, err)
+
+		expected := &lifecycle.CommitChaincodeDefinitionArgs{
+			Sequence:            1,
+			Name:                "CHAINCODE",
+			Version:             "1.0",
+			EndorsementPlugin:   "escc",
+			ValidationPlugin:    "vscc",
+			ValidationParameter: validationParameter,
+		}
+		AssertProtoEqual(t, expected, args)
+	})
+
+	t.Run("Submits signed prepared transaction", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		requests := &GatewayRequests{}
+		mockGateway := NewGatewayClient(controller, peer.TxValidationCode_VALID, requests)
+
+		_, err := Commit(ctx, NewSigningIdentity(controller, []byte("SIGNATURE")), RequiredOptions(mockGateway)...)
+		require.NoError(t, err)
+
+		require.Equal(t, requests.Endorse.GetTransactionId(), requests.Submit.GetTransactionId())
+		require.Equal(t, []byte("SIGNATURE"), requests.Submit.GetPreparedTransaction().GetSignature())
+	})
+
+	t.Run("Returns commit status", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		requests := &GatewayRequests{}
+		mockGateway := NewGatewayClient(controller, peer.TxValidationCode_VALID, requests)
+
+		status, err := Commit(ctx, NewSigningIdentity(controller, nil), RequiredOptions(mockGateway)...)
+		require.NoError(t, err)
+
+		require.Equal(t, requests.Endorse.GetTransactionId(), status.TransactionID)
+		require.EqualValues(t, 1, status.BlockNumber)
+		require.True(t, status.Successful())
+	})
+
+	t.Run("Invalid commit status gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockGateway := NewGatewayClient(controller, peer.TxValidationCode_MVCC_READ_CONFLICT, &GatewayRequests{})
+
+		_, err := Commit(ctx, NewSigningIdentity(controller, nil), RequiredOptions(mockGateway)...)
+		require.ErrorContains(t, err, peer.TxValidationCode_MVCC_READ_CONFLICT.String())
+	})
+
+	t.Run("Submitted handler called before commit status", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		var endorseRequest *gateway.EndorseRequest
+		mockGateway := NewMockGatewayClient(controller)
+		mockGateway.EXPECT().Endorse(gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, in *gateway.EndorseRequest, _ ...interface{}) {
+				endorseRequest = in
+			}).
+			Return(&gateway.EndorseResponse{PreparedTransaction: &common.Envelope{}}, nil)
+		mockGateway.EXPECT().Submit(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&gateway.SubmitResponse{}, nil)
+		mockGateway.EXPECT().CommitStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("COMMIT_STATUS_ERROR"))
+
+		var submittedTransactionID string
+		options := append(RequiredOptions(mockGateway), WithSubmittedHandler(func(transactionID string) {
+			submittedTransactionID = transactionID
+		}))
+
+		_, err := Commit(ctx, NewSigningIdentity(controller, nil), options...)
+		require.ErrorContains(t, err, "COMMIT_STATUS_ERROR")
+		require.Equal(t, endorseRequest.GetTransactionId(), submittedTransactionID)
+	})
+
+	t.Run("Endorse response without prepared transaction gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockGateway := NewMockGatewayClient(controller)
+		mockGateway.EXPECT().Endorse(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&gateway.EndorseResponse{}, nil)
+
+		_, err := Commit(ctx, NewSigningIdentity(controller, nil), RequiredOptions(mockGateway)...)
+		require.ErrorContains(t, err, "prepared transaction")
+	})
+
+	t.Run("Submit error is returned", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockGateway := NewMockGatewayClient(controller)
+		mockGateway.EXPECT().Endorse(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&gateway.EndorseResponse{PreparedTransaction: &common.Envelope{}}, nil)
+		mockGateway.EXPECT().Submit(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("SUBMIT_ERROR"))
+
+		_, err := Commit(ctx, NewSigningIdentity(controller, nil), RequiredOptions(mockGateway)...)
+		require.ErrorContains(t, err, "SUBMIT_ERROR")
+	})
+}
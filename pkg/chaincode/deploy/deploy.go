@@ -0,0 +1,652 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package deploy provides support for deploying a chaincode package to a channel by running each step of the chaincode
+// lifecycle for all participating organizations: install on every peer, approve for each organization, check commit
+// readiness, commit, and wait for the commit on every peer. Upgrade does the same for a chaincode that is already
+// committed, deriving the next chaincode definition from the committed one. Progress is recorded in a State so that a
+// failed deployment can be retried from the step at which it failed.
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/chaincode"
+	"github.com/bestbeforetoday/fabric-admin/internal/common"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/approve"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/checkcommitreadiness"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/commit"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/install"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/querycommitted"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/queryinstalled"
+	"github.com/bestbeforetoday/fabric-admin/pkg/commitstatus"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"google.golang.org/grpc"
+)
+
+// Organization participating in a chaincode deployment.
+type Organization struct {
+	// SigningID of an administrator of the organization.
+	SigningID identity.SigningIdentity
+	// Peers of the organization on which the chaincode is installed. The first peer is also used to approve and commit
+	// the chaincode definition, so must provide the Fabric Gateway service.
+	Peers []*Peer
+}
+
+// Peer to which the chaincode is deployed.
+type Peer struct {
+	// Name uniquely identifies the peer within the deployment, and is used to record its progress in the State.
+	Name string
+	// Connection to the peer. This should be shared by all commands connecting to the same network node.
+	Connection grpc.ClientConnInterface
+	// TLSCertHash is the hash of the client TLS certificate used by the connection, if the peer requires client TLS
+	// authentication.
+	TLSCertHash []byte
+}
+
+// Deploy a chaincode package to a channel. Each step is skipped if the supplied state, or the peers, show that it has
+// already been completed. The returned state records the deployment progress, and is returned even if the deployment
+// fails. In that case the error is a *StepError identifying the failed step, and the state can be supplied to a later
+// Deploy call using WithState to retry from that step.
+//
+// The wait step blocks until every peer has committed the chaincode definition, so the context should include a
+// suitable timeout. If a previous attempt submitted the commit transaction but did not obtain its commit status, the
+// wait step instead checks that each peer reports the chaincode definition as committed.
+func Deploy(ctx context.Context, options ...Option) (*State, error) {
+	deployCommand := &command{
+		state: &State{},
+	}
+
+	if err := common.ApplyOptions(deployCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := deployCommand.validate(); err != nil {
+		return nil, err
+	}
+
+	return deployCommand.state, deployCommand.run(ctx)
+}
+
+type command struct {
+	grpcOptions      []grpc.CallOption
+	channelName      string
+	chaincodePackage []byte
+	definition       chaincode.Definition
+	organizations    []*Organization
+	state            *State
+	initRequiredSet  bool
+}
+
+func (c *command) validate() error {
+	if err := c.validateTargets(); err != nil {
+		return err
+	}
+
+	return c.validateDefinition()
+}
+
+func (c *command) validateTargets() error {
+	if c.chaincodePackage == nil {
+		return errors.New("no chaincode package supplied")
+	}
+	if c.channelName == "" {
+		return errors.New("no channel name supplied")
+	}
+	if len(c.organizations) == 0 {
+		return errors.New("no organizations supplied")
+	}
+
+	peerNames := make(map[string]bool)
+	for _, org := range c.organizations {
+		if org.SigningID == nil {
+			return errors.New("no signing identity supplied for organization")
+		}
+		if len(org.Peers) == 0 {
+			return fmt.Errorf("no peers supplied for organization %s", org.SigningID.MspID())
+		}
+
+		for _, orgPeer := range org.Peers {
+			if orgPeer.Name == "" || orgPeer.Connection == nil {
+				return fmt.Errorf("peer name and connection required for organization %s", org.SigningID.MspID())
+			}
+			if peerNames[orgPeer.Name] {
+				return fmt.Errorf("duplicate peer name: %s", orgPeer.Name)
+			}
+			peerNames[orgPeer.Name] = true
+		}
+	}
+
+	return nil
+}
+
+func (c *command) validateDefinition() error {
+	if err := c.definition.Validate(); err != nil {
+		return err
+	}
+
+	if c.state.Sequence != 0 && c.state.Sequence != c.definition.Sequence {
+		return fmt.Errorf("deployment state is for sequence %d, not %d", c.state.Sequence, c.definition.Sequence)
+	}
+
+	return nil
+}
+
+func (c *command) run(ctx context.Context) error {
+	c.state.Sequence = c.definition.Sequence
+
+	steps := []struct {
+		step Step
+		run  func(context.Context) error
+	}{
+		{StepPackage, c.packageChaincode},
+		{StepInstall, c.install},
+		{StepApprove, c.approve},
+		{StepCheckCommitReadiness, c.checkCommitReadiness},
+		{StepCommit, c.commit},
+		{StepWait, c.wait},
+	}
+
+	for _, s := range steps {
+		if err := s.run(ctx); err != nil {
+			return &StepError{Step: s.step, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func (c *command) packageChaincode(context.Context) error {
+	packageID, err := PackageID(c.chaincodePackage)
+	if err != nil {
+		return err
+	}
+
+	if c.state.PackageID != "" && c.state.PackageID != packageID {
+		return fmt.Errorf("deployment state is for package %s, not %s", c.state.PackageID, packageID)
+	}
+
+	c.state.PackageID = packageID
+	return nil
+}
+
+func (c *command) install(ctx context.Context) error {
+	for _, org := range c.organizations {
+		for _, orgPeer := range org.Peers {
+			if contains(c.state.InstalledPeers, orgPeer.Name) {
+				continue
+			}
+
+			if err := c.installOnPeer(ctx, org.SigningID, orgPeer); err != nil {
+				return fmt.Errorf("failed to install on peer %s: %w", orgPeer.Name, err)
+			}
+
+			c.state.InstalledPeers = append(c.state.InstalledPeers, orgPeer.Name)
+		}
+	}
+
+	return nil
+}
+
+func (c *command) installOnPeer(ctx context.Context, signingID identity.SigningIdentity, orgPeer *Peer) error {
+	result, err := queryinstalled.Query(
+		ctx,
+		signingID,
+		queryinstalled.WithClientConnection(orgPeer.Connection),
+		queryinstalled.WithCallOptions(c.grpcOptions...),
+		queryinstalled.WithTLSCertHash(orgPeer.TLSCertHash),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, installed := range result.GetInstalledChaincodes() {
+		if installed.GetPackageId() == c.state.PackageID {
+			return nil
+		}
+	}
+
+	return install.Install(
+		ctx,
+		signingID,
+		install.WithClientConnection(orgPeer.Connection),
+		install.WithChaincodePackageBytes(c.chaincodePackage),
+		install.WithCallOptions(c.grpcOptions...),
+		install.WithTLSCertHash(orgPeer.TLSCertHash),
+	)
+}
+
+func (c *command) approve(ctx context.Context) error {
+	if err := c.checkCommitted(ctx); err != nil {
+		return err
+	}
+
+	if c.state.Committed() || c.allApproved() {
+		return nil
+	}
+
+	// Approvals from a previous attempt might not have been recorded, and approving the same definition again fails.
+	approvals, err := c.approvals(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, org := range c.organizations {
+		mspID := org.SigningID.MspID()
+		if contains(c.state.ApprovedOrganizations, mspID) {
+			continue
+		}
+
+		if !approvals[mspID] {
+			if err := c.approveForOrganization(ctx, org); err != nil {
+				return fmt.Errorf("failed to approve for organization %s: %w", mspID, err)
+			}
+		}
+
+		c.state.ApprovedOrganizations = append(c.state.ApprovedOrganizations, mspID)
+	}
+
+	return nil
+}
+
+// checkCommitted records in the state if the chaincode definition is already committed, which happens if a previous
+// attempt submitted the commit transaction but failed before obtaining its commit status.
+func (c *command) checkCommitted(ctx context.Context) error {
+	if c.state.Committed() {
+		return nil
+	}
+
+	org := c.organizations[0]
+	committed, err := c.queryCommittedDefinition(ctx, org.SigningID, org.Peers[0])
+	if err != nil {
+		return fmt.Errorf("failed to query committed chaincode definition: %w", err)
+	}
+
+	if committed.GetSequence() < c.definition.Sequence {
+		return nil
+	}
+	if committed.GetSequence() > c.definition.Sequence {
+		return fmt.Errorf("chaincode definition sequence %d is already committed", committed.GetSequence())
+	}
+
+	differences, err := c.definition.Differences(committed)
+	if err != nil {
+		return err
+	}
+	if len(differences) > 0 {
+		return fmt.Errorf("a different chaincode definition is committed at sequence %d: %s", committed.GetSequence(), strings.Join(differences, ", "))
+	}
+
+	c.state.DefinitionCommitted = true
+	return nil
+}
+
+// queryCommittedDefinition returns the chaincode definition committed on a peer, or nil if none is committed.
+func (c *command) queryCommittedDefinition(
+	ctx context.Context,
+	signingID identity.SigningIdentity,
+	queryPeer *Peer,
+) (*lifecycle.QueryChaincodeDefinitionsResult_ChaincodeDefinition, error) {
+	result, err := querycommitted.QueryAll(
+		ctx,
+		signingID,
+		querycommitted.WithClientConnection(queryPeer.Connection),
+		querycommitted.WithCallOptions(c.grpcOptions...),
+		querycommitted.WithTLSCertHash(queryPeer.TLSCertHash),
+		querycommitted.WithChannel(c.channelName),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, definition := range result.GetChaincodeDefinitions() {
+		if definition.GetName() == c.definition.Name {
+			return definition, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (c *command) allApproved() bool {
+	for _, org := range c.organizations {
+		if !contains(c.state.ApprovedOrganizations, org.SigningID.MspID()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *command) approveForOrganization(ctx context.Context, org *Organization) error {
+	gatewayPeer := org.Peers[0]
+	options := []approve.Option{
+		approve.WithClientConnection(gatewayPeer.Connection),
+		approve.WithCallOptions(c.grpcOptions...),
+		approve.WithTLSCertHash(gatewayPeer.TLSCertHash),
+		approve.WithEndorsingOrganizations(org.SigningID.MspID()),
+		approve.WithChannel(c.channelName),
+		approve.WithPackageID(c.state.PackageID),
+		approve.WithChaincodeName(c.definition.Name),
+		approve.WithVersion(c.definition.Version),
+		approve.WithSequence(c.definition.Sequence),
+		approve.WithSignaturePolicy(c.definition.SignaturePolicy),
+		approve.WithChannelConfigPolicy(c.definition.ChannelConfigPolicy),
+		approve.WithApplicationPolicy(c.definition.ApplicationPolicy),
+		approve.WithCollections(c.definition.Collections),
+		approve.WithInitRequired(c.definition.InitRequired),
+		approve.WithEndorsementPlugin(c.definition.EndorsementPlugin),
+		approve.WithValidationPlugin(c.definition.ValidationPlugin),
+	}
+
+	return approve.Approve(ctx, org.SigningID, options...)
+}
+
+func (c *command) checkCommitReadiness(ctx context.Context) error {
+	if c.state.Committed() {
+		return nil
+	}
+
+	approvals, err := c.approvals(ctx)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, org := range c.organizations {
+		if mspID := org.SigningID.MspID(); !approvals[mspID] {
+			missing = append(missing, mspID)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("chaincode definition not approved by organizations: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func (c *command) approvals(ctx context.Context) (map[string]bool, error) {
+	org := c.organizations[0]
+	queryPeer := org.Peers[0]
+	options := []checkcommitreadiness.Option{
+		checkcommitreadiness.WithClientConnection(queryPeer.Connection),
+		checkcommitreadiness.WithCallOptions(c.grpcOptions...),
+		checkcommitreadiness.WithTLSCertHash(queryPeer.TLSCertHash),
+		checkcommitreadiness.WithChannel(c.channelName),
+		checkcommitreadiness.WithChaincodeName(c.definition.Name),
+		checkcommitreadiness.WithVersion(c.definition.Version),
+		checkcommitreadiness.WithSequence(c.definition.Sequence),
+		checkcommitreadiness.WithSignaturePolicy(c.definition.SignaturePolicy),
+		checkcommitreadiness.WithChannelConfigPolicy(c.definition.ChannelConfigPolicy),
+		checkcommitreadiness.WithApplicationPolicy(c.definition.ApplicationPolicy),
+		checkcommitreadiness.WithCollections(c.definition.Collections),
+		checkcommitreadiness.WithInitRequired(c.definition.InitRequired),
+		checkcommitreadiness.WithEndorsementPlugin(c.definition.EndorsementPlugin),
+		checkcommitreadiness.WithValidationPlugin(c.definition.ValidationPlugin),
+	}
+
+	return checkcommitreadiness.Check(ctx, org.SigningID, options...)
+}
+
+func (c *command) commit(ctx context.Context) error {
+	if c.state.Committed() {
+		return nil
+	}
+
+	org := c.organizations[0]
+	gatewayPeer := org.Peers[0]
+	options := []commit.Option{
+		commit.WithClientConnection(gatewayPeer.Connection),
+		commit.WithCallOptions(c.grpcOptions...),
+		commit.WithTLSCertHash(gatewayPeer.TLSCertHash),
+		commit.WithEndorsingOrganizations(c.mspIDs()...),
+		commit.WithChannel(c.channelName),
+		commit.WithChaincodeName(c.definition.Name),
+		commit.WithVersion(c.definition.Version),
+		commit.WithSequence(c.definition.Sequence),
+		commit.WithSignaturePolicy(c.definition.SignaturePolicy),
+		commit.WithChannelConfigPolicy(c.definition.ChannelConfigPolicy),
+		commit.WithApplicationPolicy(c.definition.ApplicationPolicy),
+		commit.WithCollections(c.definition.Collections),
+		commit.WithInitRequired(c.definition.InitRequired),
+		commit.WithEndorsementPlugin(c.definition.EndorsementPlugin),
+		commit.WithValidationPlugin(c.definition.ValidationPlugin),
+		commit.WithSubmittedHandler(func(transactionID string) {
+			c.state.CommitTransactionID = transactionID
+			c.state.CommitBlockNumber = 0
+		}),
+	}
+
+	status, err := commit.Commit(ctx, org.SigningID, options...)
+	if err != nil {
+		return err
+	}
+
+	c.state.CommitBlockNumber = status.BlockNumber
+	c.state.DefinitionCommitted = true
+	return nil
+}
+
+func (c *command) mspIDs() []string {
+	results := make([]string, 0, len(c.organizations))
+	for _, org := range c.organizations {
+		results = append(results, org.SigningID.MspID())
+	}
+
+	return results
+}
+
+func (c *command) wait(ctx context.Context) error {
+	for _, org := range c.organizations {
+		for _, orgPeer := range org.Peers {
+			if contains(c.state.CommittedPeers, orgPeer.Name) {
+				continue
+			}
+
+			if err := c.waitForPeer(ctx, org.SigningID, orgPeer); err != nil {
+				return fmt.Errorf("failed waiting for commit on peer %s: %w", orgPeer.Name, err)
+			}
+
+			c.state.CommittedPeers = append(c.state.CommittedPeers, orgPeer.Name)
+		}
+	}
+
+	return nil
+}
+
+func (c *command) waitForPeer(ctx context.Context, signingID identity.SigningIdentity, orgPeer *Peer) error {
+	// Without the commit block number there is no block from which to wait for the commit transaction.
+	if c.state.CommitBlockNumber == 0 {
+		return c.checkPeerCommitted(ctx, signingID, orgPeer)
+	}
+
+	status, err := commitstatus.Wait(
+		ctx,
+		signingID,
+		c.state.CommitTransactionID,
+		commitstatus.WithClientConnection(orgPeer.Connection),
+		commitstatus.WithCallOptions(c.grpcOptions...),
+		commitstatus.WithTLSCertHash(orgPeer.TLSCertHash),
+		commitstatus.WithChannel(c.channelName),
+		commitstatus.WithStartBlock(c.state.CommitBlockNumber),
+	)
+	if err != nil {
+		return err
+	}
+
+	if !status.Successful() {
+		return fmt.Errorf("commit transaction %s has status code %d (%s)", status.TransactionID, status.Code, status.Code.String())
+	}
+
+	return nil
+}
+
+func (c *command) checkPeerCommitted(ctx context.Context, signingID identity.SigningIdentity, orgPeer *Peer) error {
+	committed, err := c.queryCommittedDefinition(ctx, signingID, orgPeer)
+	if err != nil {
+		return err
+	}
+
+	if committed.GetSequence() < c.definition.Sequence {
+		return fmt.Errorf("chaincode definition sequence %d not yet committed", c.definition.Sequence)
+	}
+
+	return nil
+}
+
+type Option = func(*command) error
+
+// WithOrganization adds an organization to the deployment. This option should be supplied once for each organization.
+func WithOrganization(org *Organization) Option {
+	return func(c *command) error {
+		c.organizations = append(c.organizations, org)
+		return nil
+	}
+}
+
+// WithState resumes a previous deployment using its recorded state. The supplied state is updated as the deployment
+// progresses.
+func WithState(state *State) Option {
+	return func(c *command) error {
+		if state == nil {
+			return errors.New("nil deployment state supplied")
+		}
+
+		c.state = state
+		return nil
+	}
+}
+
+// WithCallOptions specifies the gRPC call options to be used.
+func WithCallOptions(options ...grpc.CallOption) Option {
+	return func(c *command) error {
+		c.grpcOptions = append(c.grpcOptions, options...)
+		return nil
+	}
+}
+
+// WithChaincodePackage supplies the chaincode package to be deployed.
+func WithChaincodePackage(chaincodePackageReader io.Reader) Option {
+	return func(c *command) error {
+		chaincodePackage, err := io.ReadAll(chaincodePackageReader)
+		if err != nil {
+			return err
+		}
+
+		return WithChaincodePackageBytes(chaincodePackage)(c)
+	}
+}
+
+// WithChaincodePackageBytes supplies the chaincode package to be deployed.
+func WithChaincodePackageBytes(chaincodePackage []byte) Option {
+	return func(c *command) error {
+		c.chaincodePackage = chaincodePackage
+		return nil
+	}
+}
+
+// WithChannel specifies the name of the channel to which the chaincode is deployed.
+func WithChannel(channelName string) Option {
+	return func(c *command) error {
+		c.channelName = channelName
+		return nil
+	}
+}
+
+// WithChaincodeName specifies the name of the chaincode.
+func WithChaincodeName(name string) Option {
+	return func(c *command) error {
+		c.definition.Name = name
+		return nil
+	}
+}
+
+// WithVersion specifies the version of the chaincode.
+func WithVersion(version string) Option {
+	return func(c *command) error {
+		c.definition.Version = version
+		return nil
+	}
+}
+
+// WithSequence specifies the sequence number of the chaincode definition.
+func WithSequence(sequence int64) Option {
+	return func(c *command) error {
+		c.definition.Sequence = sequence
+		return nil
+	}
+}
+
+// WithSignaturePolicy specifies the chaincode endorsement policy as a signature policy expression, such as
+// "OR('Org1MSP.peer','Org2MSP.peer')".
+func WithSignaturePolicy(policy string) Option {
+	return func(c *command) error {
+		c.definition.SignaturePolicy = policy
+		return nil
+	}
+}
+
+// WithChannelConfigPolicy specifies the chaincode endorsement policy as a reference to a channel configuration policy,
+// such as "/Channel/Application/Endorsement".
+func WithChannelConfigPolicy(policy string) Option {
+	return func(c *command) error {
+		c.definition.ChannelConfigPolicy = policy
+		return nil
+	}
+}
+
+// WithApplicationPolicy specifies the chaincode endorsement policy as an application policy, such as the validation
+// parameter of a previously committed chaincode definition.
+func WithApplicationPolicy(policy *peer.ApplicationPolicy) Option {
+	return func(c *command) error {
+		c.definition.ApplicationPolicy = policy
+		return nil
+	}
+}
+
+// WithCollections specifies the private data collection configuration for the chaincode.
+func WithCollections(collections *peer.CollectionConfigPackage) Option {
+	return func(c *command) error {
+		c.definition.Collections = collections
+		return nil
+	}
+}
+
+// WithInitRequired specifies whether the chaincode requires an Init transaction to be invoked before other
+// transactions.
+func WithInitRequired(initRequired bool) Option {
+	return func(c *command) error {
+		c.definition.InitRequired = initRequired
+		c.initRequiredSet = true
+		return nil
+	}
+}
+
+// WithEndorsementPlugin specifies the name of the endorsement plugin. If not specified, the default system endorsement
+// plugin is used.
+func WithEndorsementPlugin(plugin string) Option {
+	return func(c *command) error {
+		c.definition.EndorsementPlugin = plugin
+		return nil
+	}
+}
+
+// WithValidationPlugin specifies the name of the validation plugin. If not specified, the default system validation
+// plugin is used.
+func WithValidationPlugin(plugin string) Option {
+	return func(c *command) error {
+		c.definition.ValidationPlugin = plugin
+		return nil
+	}
+}
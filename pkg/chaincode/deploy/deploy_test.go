@@ -0,0 +1,646 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+//go:generate mockgen -destination ./signingidentity_mock_test.go -package ${GOPACKAGE} github.com/bestbeforetoday/fabric-admin/pkg/identity SigningIdentity
+
+const commitBlockNumber = 7
+
+func NewSigningIdentity(controller *gomock.Controller, mspID string) *MockSigningIdentity {
+	mockIdentity := NewMockSigningIdentity(controller)
+	mockIdentity.EXPECT().MspID().Return(mspID).AnyTimes()
+	mockIdentity.EXPECT().Credentials().Return([]byte(mspID + "_CREDENTIALS")).AnyTimes()
+	mockIdentity.EXPECT().Sign(gomock.Any()).Return([]byte("SIGNATURE"), nil).AnyTimes()
+
+	return mockIdentity
+}
+
+// FakeNetwork records the chaincode lifecycle state of a set of fake peers, and the calls made to them.
+type FakeNetwork struct {
+	mu                  sync.Mutex
+	Installed           map[string][]string
+	Approvals           map[string]bool
+	ApproveArgs         map[string]*lifecycle.ApproveChaincodeDefinitionForMyOrgArgs
+	Committed           map[string]*lifecycle.QueryChaincodeDefinitionResult
+	EndorsingOrgs       []string
+	CommitTransactionID string
+	Calls               []string
+	// Failures are errors returned once for a peer and lifecycle function, keyed by "<peer>/<function>".
+	Failures map[string]error
+}
+
+func NewFakeNetwork() *FakeNetwork {
+	return &FakeNetwork{
+		Installed:   make(map[string][]string),
+		Approvals:   make(map[string]bool),
+		ApproveArgs: make(map[string]*lifecycle.ApproveChaincodeDefinitionForMyOrgArgs),
+		Committed:   make(map[string]*lifecycle.QueryChaincodeDefinitionResult),
+		Failures:    make(map[string]error),
+	}
+}
+
+// Peer returns a deployment peer whose connection is served by the fake network.
+func (n *FakeNetwork) Peer(name string) *Peer {
+	return &Peer{
+		Name:       name,
+		Connection: &FakeConnection{name: name, network: n},
+	}
+}
+
+// CallCount returns the number of times a lifecycle function was invoked on a peer.
+func (n *FakeNetwork) CallCount(peerName string, function string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	count := 0
+	for _, call := range n.Calls {
+		if call == peerName+"/"+function {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (n *FakeNetwork) record(peerName string, function string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := peerName + "/" + function
+	n.Calls = append(n.Calls, key)
+
+	if err, ok := n.Failures[key]; ok {
+		delete(n.Failures, key)
+		return err
+	}
+
+	return nil
+}
+
+// FakeConnection implements a gRPC client connection to a fake peer.
+type FakeConnection struct {
+	name    string
+	network *FakeNetwork
+}
+
+func (c *FakeConnection) Invoke(_ context.Context, method string, args interface{}, reply interface{}, _ ...grpc.CallOption) error {
+	var response proto.Message
+	var err error
+
+	switch method {
+	case "/protos.Endorser/ProcessProposal":
+		response, err = c.processProposal(args.(*peer.SignedProposal))
+	case "/gateway.Gateway/Endorse":
+		response, err = c.endorse(args.(*gateway.EndorseRequest))
+	case "/gateway.Gateway/Submit":
+		response = &gateway.SubmitResponse{}
+	case "/gateway.Gateway/CommitStatus":
+		if err = c.network.record(c.name, "CommitStatus"); err == nil {
+			response = &gateway.CommitStatusResponse{Result: peer.TxValidationCode_VALID, BlockNumber: commitBlockNumber}
+		}
+	default:
+		err = fmt.Errorf("unexpected method: %s", method)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	proto.Merge(reply.(proto.Message), response)
+	return nil
+}
+
+func (c *FakeConnection) processProposal(signedProposal *peer.SignedProposal) (*peer.ProposalResponse, error) {
+	_, function, arg, err := unmarshalInvocation(signedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.network.record(c.name, function); err != nil {
+		return nil, err
+	}
+
+	c.network.mu.Lock()
+	defer c.network.mu.Unlock()
+
+	var result proto.Message
+	switch function {
+	case "QueryInstalledChaincodes":
+		installed := &lifecycle.QueryInstalledChaincodesResult{}
+		for _, packageID := range c.network.Installed[c.name] {
+			installed.InstalledChaincodes = append(installed.InstalledChaincodes, &lifecycle.QueryInstalledChaincodesResult_InstalledChaincode{
+				PackageId: packageID,
+			})
+		}
+		result = installed
+	case "InstallChaincode":
+		args := &lifecycle.InstallChaincodeArgs{}
+		if err = proto.Unmarshal(arg, args); err != nil {
+			return nil, err
+		}
+		packageID, err := PackageID(args.GetChaincodeInstallPackage())
+		if err != nil {
+			return nil, err
+		}
+		c.network.Installed[c.name] = append(c.network.Installed[c.name], packageID)
+		result = &lifecycle.InstallChaincodeResult{PackageId: packageID}
+	case "CheckCommitReadiness":
+		approvals := make(map[string]bool, len(c.network.Approvals))
+		for mspID, approved := range c.network.Approvals {
+			approvals[mspID] = approved
+		}
+		result = &lifecycle.CheckCommitReadinessResult{Approvals: approvals}
+	case "QueryChaincodeDefinition":
+		args := &lifecycle.QueryChaincodeDefinitionArgs{}
+		if err = proto.Unmarshal(arg, args); err != nil {
+			return nil, err
+		}
+		definition, ok := c.network.Committed[args.GetName()]
+		if !ok {
+			return &peer.ProposalResponse{
+				Response: &peer.Response{
+					Status:  int32(common.Status_INTERNAL_SERVER_ERROR),
+					Message: fmt.Sprintf("namespace %s is not defined", args.GetName()),
+				},
+			}, nil
+		}
+		result = definition
+	case "QueryChaincodeDefinitions":
+		definitions := &lifecycle.QueryChaincodeDefinitionsResult{}
+		for name, definition := range c.network.Committed {
+			definitions.ChaincodeDefinitions = append(definitions.ChaincodeDefinitions, &lifecycle.QueryChaincodeDefinitionsResult_ChaincodeDefinition{
+				Name:                name,
+				Sequence:            definition.GetSequence(),
+				Version:             definition.GetVersion(),
+				EndorsementPlugin:   definition.GetEndorsementPlugin(),
+				ValidationPlugin:    definition.GetValidationPlugin(),
+				ValidationParameter: definition.GetValidationParameter(),
+				Collections:         definition.GetCollections(),
+				InitRequired:        definition.GetInitRequired(),
+			})
+		}
+		result = definitions
+	default:
+		return nil, fmt.Errorf("unexpected proposal function: %s", function)
+	}
+
+	payload, err := proto.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &peer.ProposalResponse{
+		Response: &peer.Response{
+			Status:  int32(common.Status_SUCCESS),
+			Payload: payload,
+		},
+	}
+	return response, nil
+}
+
+func (c *FakeConnection) endorse(request *gateway.EndorseRequest) (*gateway.EndorseResponse, error) {
+	mspID, function, arg, err := unmarshalInvocation(request.GetProposedTransaction())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.network.record(c.name, function); err != nil {
+		return nil, err
+	}
+
+	c.network.mu.Lock()
+	defer c.network.mu.Unlock()
+
+	switch function {
+	case "ApproveChaincodeDefinitionForMyOrg":
+		args := &lifecycle.ApproveChaincodeDefinitionForMyOrgArgs{}
+		if err = proto.Unmarshal(arg, args); err != nil {
+			return nil, err
+		}
+		c.network.Approvals[mspID] = true
+		c.network.ApproveArgs[mspID] = args
+	case "CommitChaincodeDefinition":
+		args := &lifecycle.CommitChaincodeDefinitionArgs{}
+		if err = proto.Unmarshal(arg, args); err != nil {
+			return nil, err
+		}
+		c.network.CommitTransactionID = request.GetTransactionId()
+		c.network.EndorsingOrgs = request.GetEndorsingOrganizations()
+		c.network.Committed[args.GetName()] = &lifecycle.QueryChaincodeDefinitionResult{
+			Sequence:            args.GetSequence(),
+			Version:             args.GetVersion(),
+			EndorsementPlugin:   args.GetEndorsementPlugin(),
+			ValidationPlugin:    args.GetValidationPlugin(),
+			ValidationParameter: args.GetValidationParameter(),
+			Collections:         args.GetCollections(),
+			InitRequired:        args.GetInitRequired(),
+		}
+	default:
+		return nil, fmt.Errorf("unexpected transaction function: %s", function)
+	}
+
+	response := &gateway.EndorseResponse{
+		PreparedTransaction: &common.Envelope{Payload: []byte("PREPARED_PAYLOAD")},
+	}
+	return response, nil
+}
+
+func (c *FakeConnection) NewStream(ctx context.Context, _ *grpc.StreamDesc, method string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+	if method != "/protos.Deliver/DeliverFiltered" {
+		return nil, fmt.Errorf("unexpected stream method: %s", method)
+	}
+
+	if err := c.network.record(c.name, "DeliverFiltered"); err != nil {
+		return nil, err
+	}
+
+	return &FakeDeliverStream{ctx: ctx, network: c.network}, nil
+}
+
+// FakeDeliverStream delivers a single filtered block containing the network's commit transaction.
+type FakeDeliverStream struct {
+	ctx     context.Context
+	network *FakeNetwork
+}
+
+func (s *FakeDeliverStream) Header() (metadata.MD, error) { return nil, nil }
+func (s *FakeDeliverStream) Trailer() metadata.MD         { return nil }
+func (s *FakeDeliverStream) CloseSend() error             { return nil }
+func (s *FakeDeliverStream) Context() context.Context     { return s.ctx }
+func (s *FakeDeliverStream) SendMsg(interface{}) error    { return nil }
+
+func (s *FakeDeliverStream) RecvMsg(m interface{}) error {
+	s.network.mu.Lock()
+	defer s.network.mu.Unlock()
+
+	response := &peer.DeliverResponse{
+		Type: &peer.DeliverResponse_FilteredBlock{
+			FilteredBlock: &peer.FilteredBlock{
+				Number: commitBlockNumber,
+				FilteredTransactions: []*peer.FilteredTransaction{
+					{Txid: s.network.CommitTransactionID, TxValidationCode: peer.TxValidationCode_VALID},
+				},
+			},
+		},
+	}
+	proto.Merge(m.(proto.Message), response)
+	return nil
+}
+
+func unmarshalInvocation(signedProposal *peer.SignedProposal) (string, string, []byte, error) {
+	proposal := &peer.Proposal{}
+	if err := proto.Unmarshal(signedProposal.GetProposalBytes(), proposal); err != nil {
+		return "", "", nil, err
+	}
+
+	header := &common.Header{}
+	if err := proto.Unmarshal(proposal.GetHeader(), header); err != nil {
+		return "", "", nil, err
+	}
+
+	signatureHeader := &common.SignatureHeader{}
+	if err := proto.Unmarshal(header.GetSignatureHeader(), signatureHeader); err != nil {
+		return "", "", nil, err
+	}
+
+	creator := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(signatureHeader.GetCreator(), creator); err != nil {
+		return "", "", nil, err
+	}
+
+	payload := &peer.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.GetPayload(), payload); err != nil {
+		return "", "", nil, err
+	}
+
+	invocationSpec := &peer.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(payload.GetInput(), invocationSpec); err != nil {
+		return "", "", nil, err
+	}
+
+	args := invocationSpec.GetChaincodeSpec().GetInput().GetArgs()
+	if len(args) != 2 {
+		return "", "", nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+	}
+
+	return creator.GetMspid(), string(args[0]), args[1], nil
+}
+
+// TestDeployment is a network of two organizations, the first with two peers and the second with one. Its options do
+// not include the chaincode version or sequence.
+type TestDeployment struct {
+	Network          *FakeNetwork
+	ChaincodePackage []byte
+	PackageID        string
+	Options          []Option
+}
+
+func NewTestDeployment(t *testing.T, controller *gomock.Controller) *TestDeployment {
+	network := NewFakeNetwork()
+	chaincodePackage := NewChaincodePackage(t, "basic_1.0")
+
+	packageID, err := PackageID(chaincodePackage)
+	require.NoError(t, err)
+
+	return &TestDeployment{
+		Network:          network,
+		ChaincodePackage: chaincodePackage,
+		PackageID:        packageID,
+		Options: []Option{
+			WithOrganization(&Organization{
+				SigningID: NewSigningIdentity(controller, "Org1MSP"),
+				Peers:     []*Peer{network.Peer("peer0.org1"), network.Peer("peer1.org1")},
+			}),
+			WithOrganization(&Organization{
+				SigningID: NewSigningIdentity(controller, "Org2MSP"),
+				Peers:     []*Peer{network.Peer("peer0.org2")},
+			}),
+			WithChaincodePackageBytes(chaincodePackage),
+			WithChannel("CHANNEL"),
+			WithChaincodeName("basic"),
+		},
+	}
+}
+
+// DeployOptions for the first deployment of the chaincode.
+func (d *TestDeployment) DeployOptions() []Option {
+	return append(d.Options, WithVersion("1.0"), WithSequence(1))
+}
+
+var allPeers = []string{"peer0.org1", "peer1.org1", "peer0.org2"}
+
+func TestDeploy(t *testing.T) {
+	t.Run("Runs all steps for all organizations", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+
+		state, err := Deploy(ctx, deployment.DeployOptions()...)
+		require.NoError(t, err)
+
+		network := deployment.Network
+		expected := &State{
+			PackageID:             deployment.PackageID,
+			Sequence:              1,
+			InstalledPeers:        allPeers,
+			ApprovedOrganizations: []string{"Org1MSP", "Org2MSP"},
+			CommitTransactionID:   network.CommitTransactionID,
+			CommitBlockNumber:     commitBlockNumber,
+			DefinitionCommitted:   true,
+			CommittedPeers:        allPeers,
+		}
+		require.Equal(t, expected, state)
+		require.True(t, state.Committed())
+
+		for _, peerName := range allPeers {
+			require.Equal(t, []string{deployment.PackageID}, network.Installed[peerName], "installed on %s", peerName)
+			require.Equal(t, 1, network.CallCount(peerName, "DeliverFiltered"), "waited for %s", peerName)
+		}
+		require.Equal(t, map[string]bool{"Org1MSP": true, "Org2MSP": true}, network.Approvals)
+		require.Equal(t, 1, network.CallCount("peer0.org1", "ApproveChaincodeDefinitionForMyOrg"))
+		require.Equal(t, 1, network.CallCount("peer0.org2", "ApproveChaincodeDefinitionForMyOrg"))
+		require.Equal(t, []string{"Org1MSP", "Org2MSP"}, network.EndorsingOrgs)
+	})
+
+	t.Run("Skips install on peers with package already installed", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+		deployment.Network.Installed["peer1.org1"] = []string{deployment.PackageID}
+
+		state, err := Deploy(ctx, deployment.DeployOptions()...)
+		require.NoError(t, err)
+
+		require.ElementsMatch(t, allPeers, state.InstalledPeers)
+		require.Equal(t, 0, deployment.Network.CallCount("peer1.org1", "InstallChaincode"))
+	})
+
+	t.Run("Skips approval for organizations that have already approved", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+		deployment.Network.Approvals["Org1MSP"] = true
+
+		state, err := Deploy(ctx, deployment.DeployOptions()...)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"Org1MSP", "Org2MSP"}, state.ApprovedOrganizations)
+		require.Equal(t, 0, deployment.Network.CallCount("peer0.org1", "ApproveChaincodeDefinitionForMyOrg"))
+	})
+
+	for _, failure := range []struct {
+		step Step
+		key  string
+	}{
+		{StepInstall, "peer0.org2/InstallChaincode"},
+		{StepApprove, "peer0.org2/ApproveChaincodeDefinitionForMyOrg"},
+		{StepCommit, "peer0.org1/CommitChaincodeDefinition"},
+		{StepWait, "peer1.org1/DeliverFiltered"},
+	} {
+		t.Run("Resumes from failed "+string(failure.step)+" step", func(t *testing.T) {
+			controller, ctx := gomock.WithContext(context.Background(), t)
+			defer controller.Finish()
+			deployment := NewTestDeployment(t, controller)
+			expectedErr := errors.New("EXPECTED_ERROR")
+			deployment.Network.Failures[failure.key] = expectedErr
+
+			state, err := Deploy(ctx, deployment.DeployOptions()...)
+
+			var stepErr *StepError
+			require.ErrorAs(t, err, &stepErr)
+			require.Equal(t, failure.step, stepErr.Step)
+			require.ErrorIs(t, err, expectedErr)
+
+			resumed, err := Deploy(ctx, append(deployment.DeployOptions(), WithState(state))...)
+			require.NoError(t, err)
+			require.Same(t, state, resumed)
+
+			network := deployment.Network
+			require.Equal(t, allPeers, state.CommittedPeers)
+			require.Equal(t, 1, network.CallCount("peer0.org1", "InstallChaincode"), "install on first peer")
+			require.Equal(t, 1, network.CallCount("peer0.org1", "ApproveChaincodeDefinitionForMyOrg"), "approve for first organization")
+			require.Equal(t, 1, network.CallCount("peer0.org1", "QueryInstalledChaincodes"), "install check on first peer")
+			require.Equal(t, 1, network.CallCount("peer0.org1", "DeliverFiltered"), "wait on first peer")
+		})
+	}
+
+	t.Run("Resumes without committing again after commit status failure", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+		// Approvals also wait for commit status, so only the commit transaction obtains commit status.
+		deployment.Network.Approvals["Org1MSP"] = true
+		deployment.Network.Approvals["Org2MSP"] = true
+		expectedErr := errors.New("EXPECTED_ERROR")
+		deployment.Network.Failures["peer0.org1/CommitStatus"] = expectedErr
+
+		state, err := Deploy(ctx, deployment.DeployOptions()...)
+
+		var stepErr *StepError
+		require.ErrorAs(t, err, &stepErr)
+		require.Equal(t, StepCommit, stepErr.Step)
+		require.ErrorIs(t, err, expectedErr)
+
+		network := deployment.Network
+		require.Equal(t, network.CommitTransactionID, state.CommitTransactionID, "transaction ID recorded")
+		require.False(t, state.Committed())
+
+		resumed, err := Deploy(ctx, append(deployment.DeployOptions(), WithState(state))...)
+		require.NoError(t, err)
+		require.Same(t, state, resumed)
+
+		require.True(t, state.Committed())
+		require.Equal(t, allPeers, state.CommittedPeers)
+		require.Equal(t, 1, network.CallCount("peer0.org1", "CommitChaincodeDefinition"))
+		for _, peerName := range allPeers {
+			require.Equal(t, 0, network.CallCount(peerName, "DeliverFiltered"), "waited for %s", peerName)
+		}
+	})
+
+	t.Run("Different definition committed at same sequence gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+		deployment.Network.Committed["basic"] = &lifecycle.QueryChaincodeDefinitionResult{Sequence: 1, Version: "0.9"}
+
+		_, err := Deploy(ctx, deployment.DeployOptions()...)
+
+		var stepErr *StepError
+		require.ErrorAs(t, err, &stepErr)
+		require.Equal(t, StepApprove, stepErr.Step)
+		require.ErrorContains(t, err, "version 0.9 != 1.0")
+		require.Equal(t, 0, deployment.Network.CallCount("peer0.org1", "ApproveChaincodeDefinitionForMyOrg"))
+	})
+
+	t.Run("Missing approval gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+
+		state := &State{
+			PackageID:             deployment.PackageID,
+			InstalledPeers:        allPeers,
+			ApprovedOrganizations: []string{"Org1MSP", "Org2MSP"},
+		}
+		deployment.Network.Approvals["Org1MSP"] = true
+
+		_, err := Deploy(ctx, append(deployment.DeployOptions(), WithState(state))...)
+
+		var stepErr *StepError
+		require.ErrorAs(t, err, &stepErr)
+		require.Equal(t, StepCheckCommitReadiness, stepErr.Step)
+		require.ErrorContains(t, err, "Org2MSP")
+		require.Equal(t, 0, deployment.Network.CallCount("peer0.org1", "CommitChaincodeDefinition"))
+	})
+
+	t.Run("State for different package gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+
+		state := &State{PackageID: "other_1.0:HASH"}
+		_, err := Deploy(ctx, append(deployment.DeployOptions(), WithState(state))...)
+
+		var stepErr *StepError
+		require.ErrorAs(t, err, &stepErr)
+		require.Equal(t, StepPackage, stepErr.Step)
+		require.Empty(t, deployment.Network.Calls)
+	})
+
+	for name, testCase := range map[string]struct {
+		options  func(controller *gomock.Controller, network *FakeNetwork) []Option
+		expected string
+	}{
+		"no chaincode package": {
+			options: func(controller *gomock.Controller, network *FakeNetwork) []Option {
+				return []Option{
+					WithOrganization(&Organization{SigningID: NewSigningIdentity(controller, "Org1MSP"), Peers: []*Peer{network.Peer("peer0")}}),
+					WithChannel("CHANNEL"), WithChaincodeName("basic"), WithVersion("1.0"), WithSequence(1),
+				}
+			},
+			expected: "package",
+		},
+		"no channel": {
+			options: func(controller *gomock.Controller, network *FakeNetwork) []Option {
+				return []Option{
+					WithOrganization(&Organization{SigningID: NewSigningIdentity(controller, "Org1MSP"), Peers: []*Peer{network.Peer("peer0")}}),
+					WithChaincodePackageBytes([]byte("PACKAGE")), WithChaincodeName("basic"), WithVersion("1.0"), WithSequence(1),
+				}
+			},
+			expected: "channel",
+		},
+		"no organizations": {
+			options: func(*gomock.Controller, *FakeNetwork) []Option {
+				return []Option{
+					WithChaincodePackageBytes([]byte("PACKAGE")),
+					WithChannel("CHANNEL"), WithChaincodeName("basic"), WithVersion("1.0"), WithSequence(1),
+				}
+			},
+			expected: "organizations",
+		},
+		"organization without peers": {
+			options: func(controller *gomock.Controller, _ *FakeNetwork) []Option {
+				return []Option{
+					WithOrganization(&Organization{SigningID: NewSigningIdentity(controller, "Org1MSP")}),
+					WithChaincodePackageBytes([]byte("PACKAGE")),
+					WithChannel("CHANNEL"), WithChaincodeName("basic"), WithVersion("1.0"), WithSequence(1),
+				}
+			},
+			expected: "Org1MSP",
+		},
+		"duplicate peer names": {
+			options: func(controller *gomock.Controller, network *FakeNetwork) []Option {
+				return []Option{
+					WithOrganization(&Organization{SigningID: NewSigningIdentity(controller, "Org1MSP"), Peers: []*Peer{network.Peer("peer0")}}),
+					WithOrganization(&Organization{SigningID: NewSigningIdentity(controller, "Org2MSP"), Peers: []*Peer{network.Peer("peer0")}}),
+					WithChaincodePackageBytes([]byte("PACKAGE")),
+					WithChannel("CHANNEL"), WithChaincodeName("basic"), WithVersion("1.0"), WithSequence(1),
+				}
+			},
+			expected: "duplicate",
+		},
+		"no chaincode name": {
+			options: func(controller *gomock.Controller, network *FakeNetwork) []Option {
+				return []Option{
+					WithOrganization(&Organization{SigningID: NewSigningIdentity(controller, "Org1MSP"), Peers: []*Peer{network.Peer("peer0")}}),
+					WithChaincodePackageBytes([]byte("PACKAGE")),
+					WithChannel("CHANNEL"), WithVersion("1.0"), WithSequence(1),
+				}
+			},
+			expected: "name",
+		},
+	} {
+		t.Run("Invalid options gives error: "+name, func(t *testing.T) {
+			controller, ctx := gomock.WithContext(context.Background(), t)
+			defer controller.Finish()
+			network := NewFakeNetwork()
+
+			state, err := Deploy(ctx, testCase.options(controller, network)...)
+			require.ErrorContains(t, err, testCase.expected)
+			require.Nil(t, state)
+			require.Empty(t, network.Calls)
+		})
+	}
+}
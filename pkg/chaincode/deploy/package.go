@@ -0,0 +1,69 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deploy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const packageMetadataFile = "metadata.json"
+
+type packageMetadata struct {
+	Label string `json:"label"`
+}
+
+// PackageID returns the ID assigned by peers to a chaincode package when it is installed. This is the package label
+// followed by the SHA-256 hash of the package.
+func PackageID(chaincodePackage []byte) (string, error) {
+	label, err := packageLabel(chaincodePackage)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(chaincodePackage)
+	return label + ":" + hex.EncodeToString(hash[:]), nil
+}
+
+func packageLabel(chaincodePackage []byte) (string, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(chaincodePackage))
+	if err != nil {
+		return "", fmt.Errorf("failed to read chaincode package: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("no %s found in chaincode package", packageMetadataFile)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read chaincode package: %w", err)
+		}
+
+		if header.Name != packageMetadataFile {
+			continue
+		}
+
+		metadata := &packageMetadata{}
+		if err = json.NewDecoder(tarReader).Decode(metadata); err != nil {
+			return "", fmt.Errorf("failed to parse chaincode package metadata: %w", err)
+		}
+		if metadata.Label == "" {
+			return "", errors.New("no label found in chaincode package metadata")
+		}
+
+		return metadata.Label, nil
+	}
+}
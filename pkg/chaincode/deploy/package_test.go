@@ -0,0 +1,69 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deploy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// NewPackage creates a gzipped tar archive containing the supplied files.
+func NewPackage(t *testing.T, files map[string]string) []byte {
+	buffer := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for name, content := range files {
+		err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))})
+		require.NoError(t, err)
+		_, err = tarWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	return buffer.Bytes()
+}
+
+// NewChaincodePackage creates a minimal chaincode package with the supplied label.
+func NewChaincodePackage(t *testing.T, label string) []byte {
+	return NewPackage(t, map[string]string{
+		"metadata.json": `{"type":"golang","label":"` + label + `"}`,
+		"code.tar.gz":   "CODE",
+	})
+}
+
+func TestPackageID(t *testing.T) {
+	t.Run("Label and hash of chaincode package", func(t *testing.T) {
+		chaincodePackage, err := os.ReadFile("../../../test/chaincode/basic.tar.gz")
+		require.NoError(t, err)
+
+		actual, err := PackageID(chaincodePackage)
+		require.NoError(t, err)
+
+		require.Equal(t, "basic_1.0:4092f66e25131be4cdf6219e816e4bccdd2593418a3560fd5192c2e330621e99", actual)
+	})
+
+	t.Run("Non-gzip package gives error", func(t *testing.T) {
+		_, err := PackageID([]byte("INVALID"))
+		require.Error(t, err)
+	})
+
+	t.Run("Missing metadata gives error", func(t *testing.T) {
+		_, err := PackageID(NewPackage(t, map[string]string{"code.tar.gz": "CODE"}))
+		require.ErrorContains(t, err, "metadata.json")
+	})
+
+	t.Run("Missing label gives error", func(t *testing.T) {
+		_, err := PackageID(NewPackage(t, map[string]string{"metadata.json": `{"type":"golang"}`}))
+		require.ErrorContains(t, err, "label")
+	})
+}
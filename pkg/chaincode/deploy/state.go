@@ -0,0 +1,79 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deploy
+
+import "fmt"
+
+// Step of a chaincode deployment.
+type Step string
+
+const (
+	// StepPackage identifies the chaincode package and determines its package ID.
+	StepPackage Step = "package"
+	// StepInstall installs the chaincode package on the peers of each organization.
+	StepInstall Step = "install"
+	// StepApprove approves the chaincode definition for each organization.
+	StepApprove Step = "approve"
+	// StepCheckCommitReadiness checks that all organizations have approved the chaincode definition.
+	StepCheckCommitReadiness Step = "checkcommitreadiness"
+	// StepCommit commits the chaincode definition to the channel.
+	StepCommit Step = "commit"
+	// StepWait waits for the chaincode definition to be committed by the peers of each organization.
+	StepWait Step = "wait"
+)
+
+// State records the progress of a chaincode deployment. It can be serialized, for example as JSON, and supplied to a
+// later deployment using WithState to resume from the step at which a previous attempt failed. A State should only be
+// reused for the same chaincode package, definition, channel and organizations.
+type State struct {
+	// PackageID of the chaincode package being deployed.
+	PackageID string `json:"packageId,omitempty"`
+	// Sequence number of the chaincode definition being deployed.
+	Sequence int64 `json:"sequence,omitempty"`
+	// InstalledPeers are the names of peers on which the chaincode package is installed.
+	InstalledPeers []string `json:"installedPeers,omitempty"`
+	// ApprovedOrganizations are the MSP IDs of organizations that have approved the chaincode definition.
+	ApprovedOrganizations []string `json:"approvedOrganizations,omitempty"`
+	// CommitTransactionID is the ID of the transaction submitted to commit the chaincode definition. It is recorded
+	// before the commit status is known.
+	CommitTransactionID string `json:"commitTransactionId,omitempty"`
+	// CommitBlockNumber is the number of the block containing the commit transaction, if its commit status was
+	// obtained.
+	CommitBlockNumber uint64 `json:"commitBlockNumber,omitempty"`
+	// DefinitionCommitted is true once the chaincode definition is known to be committed to the channel.
+	DefinitionCommitted bool `json:"definitionCommitted,omitempty"`
+	// CommittedPeers are the names of peers that have committed the chaincode definition.
+	CommittedPeers []string `json:"committedPeers,omitempty"`
+}
+
+// Committed returns true if the chaincode definition has been committed to the channel.
+func (s *State) Committed() bool {
+	return s.DefinitionCommitted
+}
+
+// StepError is returned when a deployment fails, and identifies the step at which it failed.
+type StepError struct {
+	Step Step
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("chaincode deployment failed at %s step: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,109 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/common"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/querycommitted"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"google.golang.org/protobuf/proto"
+)
+
+// Upgrade a chaincode that is already committed to a channel to use a new chaincode package. The committed chaincode
+// definition is queried to determine the next sequence number, so WithSequence must not be specified. Definition
+// parameters that are not specified, such as the version, endorsement policy, collections, plugins and whether Init is
+// required, are carried forward unchanged from the committed definition. The upgrade then proceeds in the same way as
+// Deploy, and can be resumed by supplying its state using WithState.
+func Upgrade(ctx context.Context, options ...Option) (*State, error) {
+	upgradeCommand := &command{
+		state: &State{},
+	}
+
+	if err := common.ApplyOptions(upgradeCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if upgradeCommand.definition.Sequence != 0 {
+		return nil, errors.New("sequence is determined from the committed chaincode definition and must not be supplied")
+	}
+	if upgradeCommand.definition.Name == "" {
+		return nil, errors.New("no chaincode name supplied")
+	}
+
+	if err := upgradeCommand.validateTargets(); err != nil {
+		return nil, err
+	}
+
+	committed, err := upgradeCommand.queryCommitted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query committed definition of chaincode %s: %w", upgradeCommand.definition.Name, err)
+	}
+
+	if err = upgradeCommand.carryForward(committed); err != nil {
+		return nil, err
+	}
+
+	if err = upgradeCommand.validateDefinition(); err != nil {
+		return nil, err
+	}
+
+	return upgradeCommand.state, upgradeCommand.run(ctx)
+}
+
+func (c *command) queryCommitted(ctx context.Context) (*lifecycle.QueryChaincodeDefinitionResult, error) {
+	org := c.organizations[0]
+	queryPeer := org.Peers[0]
+
+	return querycommitted.Query(
+		ctx,
+		org.SigningID,
+		querycommitted.WithClientConnection(queryPeer.Connection),
+		querycommitted.WithCallOptions(c.grpcOptions...),
+		querycommitted.WithTLSCertHash(queryPeer.TLSCertHash),
+		querycommitted.WithChannel(c.channelName),
+		querycommitted.WithChaincodeName(c.definition.Name),
+	)
+}
+
+func (c *command) carryForward(committed *lifecycle.QueryChaincodeDefinitionResult) error {
+	// A resumed upgrade may already have committed its sequence, so the recorded sequence takes precedence.
+	if c.state.Sequence != 0 {
+		c.definition.Sequence = c.state.Sequence
+	} else {
+		c.definition.Sequence = committed.GetSequence() + 1
+	}
+
+	if c.definition.Version == "" {
+		c.definition.Version = committed.GetVersion()
+	}
+	if c.definition.EndorsementPlugin == "" {
+		c.definition.EndorsementPlugin = committed.GetEndorsementPlugin()
+	}
+	if c.definition.ValidationPlugin == "" {
+		c.definition.ValidationPlugin = committed.GetValidationPlugin()
+	}
+	if c.definition.Collections == nil {
+		c.definition.Collections = committed.GetCollections()
+	}
+	if !c.initRequiredSet {
+		c.definition.InitRequired = committed.GetInitRequired()
+	}
+
+	if !c.definition.HasEndorsementPolicy() && len(committed.GetValidationParameter()) > 0 {
+		policy := &peer.ApplicationPolicy{}
+		if err := proto.Unmarshal(committed.GetValidationParameter(), policy); err != nil {
+			return fmt.Errorf("failed to deserialize committed endorsement policy: %w", err)
+		}
+		c.definition.ApplicationPolicy = policy
+	}
+
+	return nil
+}
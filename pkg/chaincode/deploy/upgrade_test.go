@@ -0,0 +1,157 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bestbeforetoday/fabric-admin/pkg/policydsl"
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func NewSignaturePolicyParameter(t *testing.T, expression string) []byte {
+	signaturePolicy, err := policydsl.FromString(expression)
+	require.NoError(t, err)
+
+	result, err := proto.Marshal(&peer.ApplicationPolicy{
+		Type: &peer.ApplicationPolicy_SignaturePolicy{SignaturePolicy: signaturePolicy},
+	})
+	require.NoError(t, err)
+
+	return result
+}
+
+// NewCommittedDefinition returns a committed chaincode definition with non-default values for all parameters.
+func NewCommittedDefinition(t *testing.T) *lifecycle.QueryChaincodeDefinitionResult {
+	return &lifecycle.QueryChaincodeDefinitionResult{
+		Sequence:            2,
+		Version:             "1.0",
+		EndorsementPlugin:   "CUSTOM_ESCC",
+		ValidationPlugin:    "CUSTOM_VSCC",
+		ValidationParameter: NewSignaturePolicyParameter(t, "AND('Org1MSP.peer','Org2MSP.peer')"),
+		Collections: &peer.CollectionConfigPackage{
+			Config: []*peer.CollectionConfig{
+				{
+					Payload: &peer.CollectionConfig_StaticCollectionConfig{
+						StaticCollectionConfig: &peer.StaticCollectionConfig{Name: "COLLECTION"},
+					},
+				},
+			},
+		},
+		InitRequired: true,
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	t.Run("Approves and commits next sequence with committed parameters", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+		committed := NewCommittedDefinition(t)
+		deployment.Network.Committed["basic"] = committed
+
+		state, err := Upgrade(ctx, deployment.Options...)
+		require.NoError(t, err)
+
+		require.EqualValues(t, 3, state.Sequence)
+		require.Equal(t, allPeers, state.CommittedPeers)
+
+		for _, mspID := range []string{"Org1MSP", "Org2MSP"} {
+			args := deployment.Network.ApproveArgs[mspID]
+			require.NotNil(t, args, "approved by %s", mspID)
+			require.EqualValues(t, 3, args.GetSequence())
+			require.Equal(t, committed.GetVersion(), args.GetVersion())
+			require.Equal(t, committed.GetEndorsementPlugin(), args.GetEndorsementPlugin())
+			require.Equal(t, committed.GetValidationPlugin(), args.GetValidationPlugin())
+			require.Equal(t, committed.GetValidationParameter(), args.GetValidationParameter())
+			require.True(t, proto.Equal(committed.GetCollections(), args.GetCollections()), "collections")
+			require.True(t, args.GetInitRequired())
+			require.Equal(t, deployment.PackageID, args.GetSource().GetLocalPackage().GetPackageId())
+		}
+
+		require.EqualValues(t, 3, deployment.Network.Committed["basic"].GetSequence())
+	})
+
+	t.Run("Specified parameters override committed parameters", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+		deployment.Network.Committed["basic"] = NewCommittedDefinition(t)
+
+		options := append(
+			deployment.Options,
+			WithVersion("2.0"),
+			WithSignaturePolicy("OR('Org1MSP.peer','Org2MSP.peer')"),
+			WithInitRequired(false),
+		)
+		_, err := Upgrade(ctx, options...)
+		require.NoError(t, err)
+
+		args := deployment.Network.ApproveArgs["Org1MSP"]
+		require.Equal(t, "2.0", args.GetVersion())
+		require.Equal(t, NewSignaturePolicyParameter(t, "OR('Org1MSP.peer','Org2MSP.peer')"), args.GetValidationParameter())
+		require.False(t, args.GetInitRequired())
+		require.Equal(t, "CUSTOM_ESCC", args.GetEndorsementPlugin())
+	})
+
+	t.Run("Resumed upgrade keeps recorded sequence after commit", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+		deployment.Network.Committed["basic"] = NewCommittedDefinition(t)
+		deployment.Network.Failures["peer0.org2/DeliverFiltered"] = errors.New("EXPECTED_ERROR")
+
+		state, err := Upgrade(ctx, deployment.Options...)
+		var stepErr *StepError
+		require.ErrorAs(t, err, &stepErr)
+		require.Equal(t, StepWait, stepErr.Step)
+
+		_, err = Upgrade(ctx, append(deployment.Options, WithState(state))...)
+		require.NoError(t, err)
+
+		require.EqualValues(t, 3, state.Sequence)
+		require.EqualValues(t, 3, deployment.Network.Committed["basic"].GetSequence())
+		require.Equal(t, 1, deployment.Network.CallCount("peer0.org1", "CommitChaincodeDefinition"))
+	})
+
+	t.Run("Chaincode not committed gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+
+		state, err := Upgrade(ctx, deployment.Options...)
+		require.ErrorContains(t, err, "not defined")
+		require.Nil(t, state)
+		require.Equal(t, 0, deployment.Network.CallCount("peer0.org1", "InstallChaincode"))
+	})
+
+	t.Run("Supplied sequence gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+		deployment.Network.Committed["basic"] = NewCommittedDefinition(t)
+
+		_, err := Upgrade(ctx, append(deployment.Options, WithSequence(3))...)
+		require.ErrorContains(t, err, "sequence")
+		require.Empty(t, deployment.Network.Calls)
+	})
+
+	t.Run("Deploy with state for different sequence gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		deployment := NewTestDeployment(t, controller)
+
+		_, err := Deploy(ctx, append(deployment.DeployOptions(), WithState(&State{Sequence: 2}))...)
+		require.ErrorContains(t, err, "sequence")
+		require.Empty(t, deployment.Network.Calls)
+	})
+}
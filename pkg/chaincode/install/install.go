@@ -13,6 +13,7 @@ import (
 	"github.com/bestbeforetoday/fabric-admin/internal/common"
 	"github.com/bestbeforetoday/fabric-admin/internal/proposal"
 	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
 	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
 	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
 	"google.golang.org/grpc"
@@ -22,46 +23,75 @@ import (
 const installTransactionName = "InstallChaincode"
 
 func Install(ctx context.Context, signingID identity.SigningIdentity, options ...Option) error {
-	installCommand := &command{
-		signingID: signingID,
+	installCommand := &command{}
+
+	if err := common.ApplyOptions(installCommand, options...); err != nil {
+		return err
+	}
+
+	return installCommand.run(ctx, signingID)
+}
+
+// NewProposal creates an unsigned install proposal for the supplied identity. The proposal can be signed separately,
+// such as on an offline machine, and then submitted using Submit.
+func NewProposal(id gatewayid.Identity, options ...Option) (*peer.Proposal, error) {
+	installCommand := &command{}
+
+	if err := common.ApplyOptions(installCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := installCommand.validateProposal(); err != nil {
+		return nil, err
 	}
 
+	return installCommand.newProposal(id)
+}
+
+// Submit a signed install proposal, created using NewProposal, to a peer.
+func Submit(ctx context.Context, signedProposal *peer.SignedProposal, options ...Option) error {
+	installCommand := &command{}
+
 	if err := common.ApplyOptions(installCommand, options...); err != nil {
 		return err
 	}
 
-	return installCommand.run(ctx)
+	if err := installCommand.validateSubmit(); err != nil {
+		return err
+	}
+
+	return installCommand.submit(ctx, signedProposal)
 }
 
 type command struct {
-	signingID        identity.SigningIdentity
 	grpcClient       peer.EndorserClient
 	grpcOptions      []grpc.CallOption
 	chaincodePackage []byte
+	tlsCertHash      []byte
 }
 
-func (c *command) run(ctx context.Context) error {
-	if err := c.validate(); err != nil {
+func (c *command) run(ctx context.Context, signingID identity.SigningIdentity) error {
+	if err := c.validateSubmit(); err != nil {
+		return err
+	}
+	if err := c.validateProposal(); err != nil {
 		return err
 	}
 
-	signedProposal, err := c.signedProposal()
+	unsignedProposal, err := c.newProposal(signingID)
 	if err != nil {
 		return err
 	}
 
-	proposalResponse, err := c.grpcClient.ProcessProposal(ctx, signedProposal, c.grpcOptions...)
+	signedProposal, err := proposal.Sign(ctx, signingID, unsignedProposal)
 	if err != nil {
 		return err
 	}
 
-	return proposal.CheckSuccessfulResponse(proposalResponse)
+	return c.submit(ctx, signedProposal)
 }
 
-func (c *command) validate() error {
-	if c.grpcClient == nil {
-		return errors.New("no gRPC client supplied")
-	}
+func (c *command) validateProposal() error {
 	if c.chaincodePackage == nil {
 		return errors.New("no chaincode package supplied")
 	}
@@ -69,37 +99,36 @@ func (c *command) validate() error {
 	return nil
 }
 
-func (c *command) signedProposal() (*peer.SignedProposal, error) {
+func (c *command) validateSubmit() error {
+	if c.grpcClient == nil {
+		return errors.New("no gRPC client supplied")
+	}
+
+	return nil
+}
+
+func (c *command) newProposal(id gatewayid.Identity) (*peer.Proposal, error) {
 	argBytes, err := c.installChaincodeArgsBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	proposal, err := proposal.New(
-		c.signingID,
+	return proposal.New(
+		id,
 		common.LifecycleChaincodeName,
 		installTransactionName,
 		proposal.WithBytesArguments(argBytes),
+		proposal.WithTLSCertHash(c.tlsCertHash),
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	proposalBytes, err := proto.Marshal(proposal)
-	if err != nil {
-		return nil, err
-	}
+}
 
-	signature, err := c.signingID.Sign(proposalBytes)
+func (c *command) submit(ctx context.Context, signedProposal *peer.SignedProposal) error {
+	proposalResponse, err := c.grpcClient.ProcessProposal(ctx, signedProposal, c.grpcOptions...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	signedProposal := &peer.SignedProposal{
-		ProposalBytes: proposalBytes,
-		Signature:     signature,
-	}
-	return signedProposal, nil
+	return proposal.CheckSuccessfulResponse(proposalResponse)
 }
 
 func (c *command) installChaincodeArgsBytes() ([]byte, error) {
@@ -147,3 +176,12 @@ func WithCallOptions(options ...grpc.CallOption) Option {
 		return nil
 	}
 }
+
+// WithTLSCertHash specifies the hash of the client TLS certificate used to connect to the peer. This is required if the
+// peer checks that the install proposal is bound to the mutual TLS connection on which it is received.
+func WithTLSCertHash(hash []byte) Option {
+	return func(c *command) error {
+		c.tlsCertHash = hash
+		return nil
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryapproved
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/common"
+	"github.com/bestbeforetoday/fabric-admin/internal/proposal"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	queryApprovedTransactionName = "QueryApprovedChaincodeDefinition"
+	// notApprovedMessage is included in the error message returned by Fabric's lifecycle chaincode when the organization
+	// has not approved the requested chaincode definition.
+	notApprovedMessage = "could not fetch approved chaincode definition"
+)
+
+// ErrNotApproved is returned, wrapped, when the organization has not approved a chaincode definition for the requested
+// chaincode name and sequence.
+var ErrNotApproved = errors.New("chaincode definition not approved")
+
+// Query the chaincode definition approved by the signing identity's organization for the chaincode specified using
+// WithChaincodeName. The query must be sent to a peer of the same organization, since approvals are held in the
+// organization's private data. If the organization has not approved a matching definition, the returned error wraps
+// ErrNotApproved.
+func Query(ctx context.Context, signingID identity.SigningIdentity, options ...Option) (*lifecycle.QueryApprovedChaincodeDefinitionResult, error) {
+	queryCommand := &command{}
+
+	if err := common.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
+	}
+
+	return queryCommand.run(ctx, signingID)
+}
+
+// NewProposal creates an unsigned query approved chaincode definition proposal for the supplied identity. The proposal
+// can be signed separately, such as on an offline machine, and then submitted using Submit.
+func NewProposal(id gatewayid.Identity, options ...Option) (*peer.Proposal, error) {
+	queryCommand := &command{}
+
+	if err := common.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := queryCommand.validateProposal(); err != nil {
+		return nil, err
+	}
+
+	return queryCommand.newProposal(id)
+}
+
+// Submit a signed query approved chaincode definition proposal, created using NewProposal, to a peer.
+func Submit(ctx context.Context, signedProposal *peer.SignedProposal, options ...Option) (*lifecycle.QueryApprovedChaincodeDefinitionResult, error) {
+	queryCommand := &command{}
+
+	if err := common.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := queryCommand.validateSubmit(); err != nil {
+		return nil, err
+	}
+
+	return queryCommand.submit(ctx, signedProposal)
+}
+
+type command struct {
+	grpcClient    peer.EndorserClient
+	grpcOptions   []grpc.CallOption
+	channelName   string
+	chaincodeName string
+	sequence      int64
+	tlsCertHash   []byte
+}
+
+func (c *command) run(ctx context.Context, signingID identity.SigningIdentity) (*lifecycle.QueryApprovedChaincodeDefinitionResult, error) {
+	if err := c.validateSubmit(); err != nil {
+		return nil, err
+	}
+	if err := c.validateProposal(); err != nil {
+		return nil, err
+	}
+
+	unsignedProposal, err := c.newProposal(signingID)
+	if err != nil {
+		return nil, err
+	}
+
+	signedProposal, err := proposal.Sign(ctx, signingID, unsignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.submit(ctx, signedProposal)
+}
+
+func (c *command) validateSubmit() error {
+	if c.grpcClient == nil {
+		return errors.New("no gRPC client supplied")
+	}
+
+	return nil
+}
+
+func (c *command) validateProposal() error {
+	if c.channelName == "" {
+		return errors.New("no channel name supplied")
+	}
+	if c.chaincodeName == "" {
+		return errors.New("no chaincode name supplied")
+	}
+	if c.sequence < 0 {
+		return fmt.Errorf("invalid sequence number: %d", c.sequence)
+	}
+
+	return nil
+}
+
+func (c *command) newProposal(id gatewayid.Identity) (*peer.Proposal, error) {
+	argBytes, err := c.queryApprovedChaincodeDefinitionArgsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return proposal.New(
+		id,
+		common.LifecycleChaincodeName,
+		queryApprovedTransactionName,
+		proposal.WithChannel(c.channelName),
+		proposal.WithBytesArguments(argBytes),
+		proposal.WithTLSCertHash(c.tlsCertHash),
+	)
+}
+
+func (c *command) submit(ctx context.Context, signedProposal *peer.SignedProposal) (*lifecycle.QueryApprovedChaincodeDefinitionResult, error) {
+	proposalResponse, err := c.grpcClient.ProcessProposal(ctx, signedProposal, c.grpcOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = proposal.CheckSuccessfulResponse(proposalResponse); err != nil {
+		if strings.Contains(proposalResponse.GetResponse().GetMessage(), notApprovedMessage) {
+			return nil, fmt.Errorf("%w: %v", ErrNotApproved, err)
+		}
+		return nil, err
+	}
+
+	result := &lifecycle.QueryApprovedChaincodeDefinitionResult{}
+	if err = proto.Unmarshal(proposalResponse.GetResponse().GetPayload(), result); err != nil {
+		return nil, fmt.Errorf("failed to deserialize query approved chaincode definition result: %w", err)
+	}
+
+	return result, nil
+}
+
+func (c *command) queryApprovedChaincodeDefinitionArgsBytes() ([]byte, error) {
+	queryArgs := &lifecycle.QueryApprovedChaincodeDefinitionArgs{
+		Name:     c.chaincodeName,
+		Sequence: c.sequence,
+	}
+	return proto.Marshal(queryArgs)
+}
+
+type Option = func(*command) error
+
+// WithClientConnection uses the supplied gRPC client connection to a peer. This should be shared by all commands
+// connecting to the same network node.
+func WithClientConnection(clientConnection grpc.ClientConnInterface) Option {
+	return func(c *command) error {
+		c.grpcClient = peer.NewEndorserClient(clientConnection)
+		return nil
+	}
+}
+
+// WithCallOptions specifies the gRPC call options to be used.
+func WithCallOptions(options ...grpc.CallOption) Option {
+	return func(c *command) error {
+		c.grpcOptions = append(c.grpcOptions, options...)
+		return nil
+	}
+}
+
+// WithChannel specifies the name of the channel to query.
+func WithChannel(channelName string) Option {
+	return func(c *command) error {
+		c.channelName = channelName
+		return nil
+	}
+}
+
+// WithChaincodeName specifies the name of the chaincode whose approved definition is queried.
+func WithChaincodeName(name string) Option {
+	return func(c *command) error {
+		c.chaincodeName = name
+		return nil
+	}
+}
+
+// WithSequence specifies the sequence number of the approved chaincode definition. If not specified, the most recently
+// approved definition is returned.
+func WithSequence(sequence int64) Option {
+	return func(c *command) error {
+		c.sequence = sequence
+		return nil
+	}
+}
+
+// WithTLSCertHash specifies the hash of the client TLS certificate used to connect to the peer. This is required if the
+// peer checks that the query proposal is bound to the mutual TLS connection on which it is received.
+func WithTLSCertHash(hash []byte) Option {
+	return func(c *command) error {
+		c.tlsCertHash = hash
+		return nil
+	}
+}
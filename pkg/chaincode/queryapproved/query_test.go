@@ -0,0 +1,319 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryapproved
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	adminproposal "github.com/bestbeforetoday/fabric-admin/pkg/proposal"
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:generate mockgen -destination ./endorser_mock_test.go -package ${GOPACKAGE} github.com/hyperledger/fabric-protos-go-apiv2/peer EndorserClient
+//go:generate mockgen -destination ./signingidentity_mock_test.go -package ${GOPACKAGE} github.com/bestbeforetoday/fabric-admin/pkg/identity SigningIdentity
+
+func WithEndorserClient(grpcClient peer.EndorserClient) Option {
+	return func(c *command) error {
+		c.grpcClient = grpcClient
+		return nil
+	}
+}
+
+func NewSigningIdentity(controller *gomock.Controller, signature []byte) *MockSigningIdentity {
+	mockIdentity := NewMockSigningIdentity(controller)
+	mockIdentity.EXPECT().MspID().AnyTimes()
+	mockIdentity.EXPECT().Credentials().AnyTimes()
+	mockIdentity.EXPECT().Sign(gomock.Any()).Return(signature, nil).AnyTimes()
+
+	return mockIdentity
+}
+
+func NewProposalResponse(status common.Status, message string) *peer.ProposalResponse {
+	return &peer.ProposalResponse{
+		Response: &peer.Response{
+			Status:  int32(status),
+			Message: message,
+		},
+	}
+}
+
+func AssertMarshal(t *testing.T, m protoreflect.ProtoMessage) []byte {
+	result, err := proto.Marshal(m)
+	require.NoError(t, err)
+	return result
+}
+
+// AssertUnmarshal ensures that a protobuf is umarshaled without error
+func AssertUnmarshal(t *testing.T, b []byte, m protoreflect.ProtoMessage) {
+	err := proto.Unmarshal(b, m)
+	require.NoError(t, err)
+}
+
+// AssertProtoEqual ensures an expected protobuf message matches an actual message
+func AssertProtoEqual(t *testing.T, expected protoreflect.ProtoMessage, actual protoreflect.ProtoMessage) {
+	require.True(t, proto.Equal(expected, actual), "Expected %v, got %v", expected, actual)
+}
+
+// AssertUnmarshalQueryArgs ensures that the query arguments are unmarshaled from a signed proposal without error
+func AssertUnmarshalQueryArgs(t *testing.T, signedProposal *peer.SignedProposal) (*common.ChannelHeader, *peer.ChaincodeInput, *lifecycle.QueryApprovedChaincodeDefinitionArgs) {
+	proposal := &peer.Proposal{}
+	AssertUnmarshal(t, signedProposal.GetProposalBytes(), proposal)
+
+	header := &common.Header{}
+	AssertUnmarshal(t, proposal.GetHeader(), header)
+
+	channelHeader := &common.ChannelHeader{}
+	AssertUnmarshal(t, header.GetChannelHeader(), channelHeader)
+
+	payload := &peer.ChaincodeProposalPayload{}
+	AssertUnmarshal(t, proposal.GetPayload(), payload)
+
+	invocationSpec := &peer.ChaincodeInvocationSpec{}
+	AssertUnmarshal(t, payload.GetInput(), invocationSpec)
+
+	input := invocationSpec.GetChaincodeSpec().GetInput()
+	require.Len(t, input.GetArgs(), 2)
+
+	args := &lifecycle.QueryApprovedChaincodeDefinitionArgs{}
+	AssertUnmarshal(t, input.GetArgs()[1], args)
+
+	return channelHeader, input, args
+}
+
+func TestQuery(t *testing.T) {
+	t.Run("Missing gRPC connection gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Query(ctx, NewSigningIdentity(controller, nil), WithChannel("CHANNEL"), WithChaincodeName("CHAINCODE"))
+		require.ErrorContains(t, err, "gRPC")
+	})
+
+	t.Run("Missing channel gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Query(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(NewMockEndorserClient(controller)),
+			WithChaincodeName("CHAINCODE"),
+		)
+		require.ErrorContains(t, err, "channel")
+	})
+
+	t.Run("Missing chaincode name gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Query(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(NewMockEndorserClient(controller)),
+			WithChannel("CHANNEL"),
+		)
+		require.ErrorContains(t, err, "chaincode name")
+	})
+
+	t.Run("Negative sequence gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Query(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(NewMockEndorserClient(controller)),
+			WithChannel("CHANNEL"),
+			WithChaincodeName("CHAINCODE"),
+			WithSequence(-1),
+		)
+		require.ErrorContains(t, err, "sequence")
+	})
+
+	t.Run("Endorser client errors returned", func(t *testing.T) {
+		expectedErr := errors.New("EXPECTED_ERROR")
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Return(nil, expectedErr)
+
+		_, err := Query(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+			WithChannel("CHANNEL"),
+			WithChaincodeName("CHAINCODE"),
+		)
+		require.ErrorIs(t, err, expectedErr)
+	})
+
+	t.Run("Unsuccessful proposal response gives error", func(t *testing.T) {
+		expectedStatus := common.Status_INTERNAL_SERVER_ERROR
+		expectedMessage := "could not fetch approved chaincode definition"
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Return(NewProposalResponse(expectedStatus, expectedMessage), nil)
+
+		_, err := Query(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+			WithChannel("CHANNEL"),
+			WithChaincodeName("CHAINCODE"),
+		)
+
+		require.ErrorContainsf(t, err, fmt.Sprintf("%d", expectedStatus), "status code")
+		require.ErrorContains(t, err, expectedMessage, "message")
+	})
+
+	t.Run("Missing approval gives not approved error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Return(NewProposalResponse(
+				common.Status_INTERNAL_SERVER_ERROR,
+				"failed to invoke backing implementation of 'QueryApprovedChaincodeDefinition': could not fetch approved chaincode definition (name: 'CHAINCODE', sequence: '1') on channel 'CHANNEL'",
+			), nil)
+
+		_, err := Query(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+			WithChannel("CHANNEL"),
+			WithChaincodeName("CHAINCODE"),
+			WithSequence(1),
+		)
+		require.ErrorIs(t, err, ErrNotApproved)
+	})
+
+	t.Run("Other unsuccessful proposal response is not a missing approval", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Return(NewProposalResponse(common.Status_FORBIDDEN, "access denied"), nil)
+
+		_, err := Query(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+			WithChannel("CHANNEL"),
+			WithChaincodeName("CHAINCODE"),
+		)
+		require.ErrorContains(t, err, "access denied")
+		require.NotErrorIs(t, err, ErrNotApproved)
+	})
+
+	t.Run("Approved definition returned on successful proposal response", func(t *testing.T) {
+		expected := &lifecycle.QueryApprovedChaincodeDefinitionResult{
+			Sequence:          3,
+			Version:           "2.0",
+			EndorsementPlugin: "escc",
+			ValidationPlugin:  "vscc",
+			Source: &lifecycle.ChaincodeSource{
+				Type: &lifecycle.ChaincodeSource_LocalPackage{
+					LocalPackage: &lifecycle.ChaincodeSource_Local{PackageId: "basic_2.0:HASH"},
+				},
+			},
+		}
+		response := NewProposalResponse(common.Status_SUCCESS, "")
+		response.Response.Payload = AssertMarshal(t, expected)
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		var signedProposal *peer.SignedProposal
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, in *peer.SignedProposal, _ ...grpc.CallOption) {
+				signedProposal = in
+			}).
+			Return(response, nil)
+
+		actual, err := Query(
+			ctx,
+			NewSigningIdentity(controller, []byte("SIGNATURE")),
+			WithEndorserClient(mockEndorser),
+			WithChannel("CHANNEL"),
+			WithChaincodeName("CHAINCODE"),
+			WithSequence(3),
+		)
+		require.NoError(t, err)
+		AssertProtoEqual(t, expected, actual)
+
+		require.Equal(t, []byte("SIGNATURE"), signedProposal.GetSignature())
+		channelHeader, input, args := AssertUnmarshalQueryArgs(t, signedProposal)
+		require.Equal(t, "CHANNEL", channelHeader.GetChannelId())
+		require.Equal(t, queryApprovedTransactionName, string(input.GetArgs()[0]))
+		require.Equal(t, "CHAINCODE", args.GetName())
+		require.EqualValues(t, 3, args.GetSequence())
+	})
+}
+
+func TestOfflineSigning(t *testing.T) {
+	t.Run("Submits externally signed proposal", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		unsignedProposal, err := NewProposal(
+			NewSigningIdentity(controller, nil),
+			WithChannel("CHANNEL"),
+			WithChaincodeName("CHAINCODE"),
+		)
+		require.NoError(t, err)
+
+		proposalBytes, err := adminproposal.Bytes(unsignedProposal)
+		require.NoError(t, err)
+
+		signedProposal, err := adminproposal.NewSignedProposal(unsignedProposal, []byte("OFFLINE_SIGNATURE"))
+		require.NoError(t, err)
+
+		var actual *peer.SignedProposal
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, in *peer.SignedProposal, _ ...grpc.CallOption) {
+				actual = in
+			}).
+			Return(NewProposalResponse(common.Status_SUCCESS, ""), nil)
+
+		_, err = Submit(ctx, signedProposal, WithEndorserClient(mockEndorser))
+		require.NoError(t, err)
+
+		require.Equal(t, proposalBytes, actual.GetProposalBytes())
+		require.Equal(t, []byte("OFFLINE_SIGNATURE"), actual.GetSignature())
+	})
+
+	t.Run("Submit without gRPC client gives error", func(t *testing.T) {
+		_, err := Submit(context.Background(), &peer.SignedProposal{})
+		require.ErrorContains(t, err, "gRPC")
+	})
+}
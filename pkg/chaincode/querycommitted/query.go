@@ -0,0 +1,258 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package querycommitted
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/common"
+	"github.com/bestbeforetoday/fabric-admin/internal/proposal"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	queryCommittedTransactionName    = "QueryChaincodeDefinition"
+	queryAllCommittedTransactionName = "QueryChaincodeDefinitions"
+)
+
+// Query the chaincode definition committed to a channel for the chaincode specified using WithChaincodeName.
+func Query(ctx context.Context, signingID identity.SigningIdentity, options ...Option) (*lifecycle.QueryChaincodeDefinitionResult, error) {
+	queryCommand := &command{}
+
+	if err := common.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
+	}
+
+	return queryCommand.run(ctx, signingID)
+}
+
+// QueryAll returns the chaincode definitions committed to a channel. Any chaincode name specified using
+// WithChaincodeName is ignored.
+func QueryAll(ctx context.Context, signingID identity.SigningIdentity, options ...Option) (*lifecycle.QueryChaincodeDefinitionsResult, error) {
+	queryCommand := &command{}
+
+	if err := common.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := queryCommand.validateSubmit(); err != nil {
+		return nil, err
+	}
+	if queryCommand.channelName == "" {
+		return nil, errors.New("no channel name supplied")
+	}
+
+	argBytes, err := proto.Marshal(&lifecycle.QueryChaincodeDefinitionsArgs{})
+	if err != nil {
+		return nil, err
+	}
+
+	unsignedProposal, err := queryCommand.newLifecycleProposal(signingID, queryAllCommittedTransactionName, argBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signedProposal, err := proposal.Sign(ctx, signingID, unsignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := queryCommand.process(ctx, signedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &lifecycle.QueryChaincodeDefinitionsResult{}
+	if err = proto.Unmarshal(payload, result); err != nil {
+		return nil, fmt.Errorf("failed to deserialize query chaincode definitions result: %w", err)
+	}
+
+	return result, nil
+}
+
+// NewProposal creates an unsigned query committed chaincode definition proposal for the supplied identity. The proposal
+// can be signed separately, such as on an offline machine, and then submitted using Submit.
+func NewProposal(id gatewayid.Identity, options ...Option) (*peer.Proposal, error) {
+	queryCommand := &command{}
+
+	if err := common.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := queryCommand.validateProposal(); err != nil {
+		return nil, err
+	}
+
+	return queryCommand.newProposal(id)
+}
+
+// Submit a signed query committed chaincode definition proposal, created using NewProposal, to a peer.
+func Submit(ctx context.Context, signedProposal *peer.SignedProposal, options ...Option) (*lifecycle.QueryChaincodeDefinitionResult, error) {
+	queryCommand := &command{}
+
+	if err := common.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := queryCommand.validateSubmit(); err != nil {
+		return nil, err
+	}
+
+	return queryCommand.submit(ctx, signedProposal)
+}
+
+type command struct {
+	grpcClient    peer.EndorserClient
+	grpcOptions   []grpc.CallOption
+	channelName   string
+	chaincodeName string
+	tlsCertHash   []byte
+}
+
+func (c *command) run(ctx context.Context, signingID identity.SigningIdentity) (*lifecycle.QueryChaincodeDefinitionResult, error) {
+	if err := c.validateSubmit(); err != nil {
+		return nil, err
+	}
+	if err := c.validateProposal(); err != nil {
+		return nil, err
+	}
+
+	unsignedProposal, err := c.newProposal(signingID)
+	if err != nil {
+		return nil, err
+	}
+
+	signedProposal, err := proposal.Sign(ctx, signingID, unsignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.submit(ctx, signedProposal)
+}
+
+func (c *command) validateSubmit() error {
+	if c.grpcClient == nil {
+		return errors.New("no gRPC client supplied")
+	}
+
+	return nil
+}
+
+func (c *command) validateProposal() error {
+	if c.channelName == "" {
+		return errors.New("no channel name supplied")
+	}
+	if c.chaincodeName == "" {
+		return errors.New("no chaincode name supplied")
+	}
+
+	return nil
+}
+
+func (c *command) newProposal(id gatewayid.Identity) (*peer.Proposal, error) {
+	argBytes, err := c.queryChaincodeDefinitionArgsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.newLifecycleProposal(id, queryCommittedTransactionName, argBytes)
+}
+
+func (c *command) newLifecycleProposal(id gatewayid.Identity, transactionName string, argBytes []byte) (*peer.Proposal, error) {
+	return proposal.New(
+		id,
+		common.LifecycleChaincodeName,
+		transactionName,
+		proposal.WithChannel(c.channelName),
+		proposal.WithBytesArguments(argBytes),
+		proposal.WithTLSCertHash(c.tlsCertHash),
+	)
+}
+
+func (c *command) submit(ctx context.Context, signedProposal *peer.SignedProposal) (*lifecycle.QueryChaincodeDefinitionResult, error) {
+	payload, err := c.process(ctx, signedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &lifecycle.QueryChaincodeDefinitionResult{}
+	if err = proto.Unmarshal(payload, result); err != nil {
+		return nil, fmt.Errorf("failed to deserialize query chaincode definition result: %w", err)
+	}
+
+	return result, nil
+}
+
+func (c *command) process(ctx context.Context, signedProposal *peer.SignedProposal) ([]byte, error) {
+	proposalResponse, err := c.grpcClient.ProcessProposal(ctx, signedProposal, c.grpcOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = proposal.CheckSuccessfulResponse(proposalResponse); err != nil {
+		return nil, err
+	}
+
+	return proposalResponse.GetResponse().GetPayload(), nil
+}
+
+func (c *command) queryChaincodeDefinitionArgsBytes() ([]byte, error) {
+	queryArgs := &lifecycle.QueryChaincodeDefinitionArgs{
+		Name: c.chaincodeName,
+	}
+	return proto.Marshal(queryArgs)
+}
+
+type Option = func(*command) error
+
+// WithClientConnection uses the supplied gRPC client connection to a peer. This should be shared by all commands
+// connecting to the same network node.
+func WithClientConnection(clientConnection grpc.ClientConnInterface) Option {
+	return func(c *command) error {
+		c.grpcClient = peer.NewEndorserClient(clientConnection)
+		return nil
+	}
+}
+
+// WithCallOptions specifies the gRPC call options to be used.
+func WithCallOptions(options ...grpc.CallOption) Option {
+	return func(c *command) error {
+		c.grpcOptions = append(c.grpcOptions, options...)
+		return nil
+	}
+}
+
+// WithChannel specifies the name of the channel to query.
+func WithChannel(channelName string) Option {
+	return func(c *command) error {
+		c.channelName = channelName
+		return nil
+	}
+}
+
+// WithChaincodeName specifies the name of the chaincode whose definition is queried.
+func WithChaincodeName(name string) Option {
+	return func(c *command) error {
+		c.chaincodeName = name
+		return nil
+	}
+}
+
+// WithTLSCertHash specifies the hash of the client TLS certificate used to connect to the peer. This is required if the
+// peer checks that the query proposal is bound to the mutual TLS connection on which it is received.
+func WithTLSCertHash(hash []byte) Option {
+	return func(c *command) error {
+		c.tlsCertHash = hash
+		return nil
+	}
+}
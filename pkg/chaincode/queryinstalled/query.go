@@ -13,6 +13,7 @@ import (
 	"github.com/bestbeforetoday/fabric-admin/internal/common"
 	"github.com/bestbeforetoday/fabric-admin/internal/proposal"
 	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
 	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
 	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
 	"google.golang.org/grpc"
@@ -22,48 +23,64 @@ import (
 const queryInstalledTransactionName = "QueryInstalledChaincodes"
 
 func Query(ctx context.Context, signingID identity.SigningIdentity, options ...Option) (*lifecycle.QueryInstalledChaincodesResult, error) {
-	installCommand := &command{
-		signingID: signingID,
+	queryCommand := &command{}
+
+	if err := common.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
 	}
 
-	if err := common.ApplyOptions(installCommand, options...); err != nil {
+	return queryCommand.run(ctx, signingID)
+}
+
+// NewProposal creates an unsigned query installed chaincodes proposal for the supplied identity. The proposal can be
+// signed separately, such as on an offline machine, and then submitted using Submit.
+func NewProposal(id gatewayid.Identity, options ...Option) (*peer.Proposal, error) {
+	queryCommand := &command{}
+
+	if err := common.ApplyOptions(queryCommand, options...); err != nil {
 		return nil, err
 	}
 
-	return installCommand.run(ctx)
+	return queryCommand.newProposal(id)
+}
+
+// Submit a signed query installed chaincodes proposal, created using NewProposal, to a peer.
+func Submit(ctx context.Context, signedProposal *peer.SignedProposal, options ...Option) (*lifecycle.QueryInstalledChaincodesResult, error) {
+	queryCommand := &command{}
+
+	if err := common.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := queryCommand.validate(); err != nil {
+		return nil, err
+	}
+
+	return queryCommand.submit(ctx, signedProposal)
 }
 
 type command struct {
-	signingID   identity.SigningIdentity
 	grpcClient  peer.EndorserClient
 	grpcOptions []grpc.CallOption
+	tlsCertHash []byte
 }
 
-func (c *command) run(ctx context.Context) (*lifecycle.QueryInstalledChaincodesResult, error) {
+func (c *command) run(ctx context.Context, signingID identity.SigningIdentity) (*lifecycle.QueryInstalledChaincodesResult, error) {
 	if err := c.validate(); err != nil {
 		return nil, err
 	}
 
-	signedProposal, err := c.signedProposal()
+	unsignedProposal, err := c.newProposal(signingID)
 	if err != nil {
 		return nil, err
 	}
 
-	proposalResponse, err := c.grpcClient.ProcessProposal(ctx, signedProposal, c.grpcOptions...)
+	signedProposal, err := proposal.Sign(ctx, signingID, unsignedProposal)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = proposal.CheckSuccessfulResponse(proposalResponse); err != nil {
-		return nil, err
-	}
-
-	result := &lifecycle.QueryInstalledChaincodesResult{}
-	if err = proto.Unmarshal(proposalResponse.GetResponse().GetPayload(), result); err != nil {
-		return nil, fmt.Errorf("failed to deserialize query installed chaincode result: %w", err)
-	}
-
-	return result, nil
+	return c.submit(ctx, signedProposal)
 }
 
 func (c *command) validate() error {
@@ -74,37 +91,37 @@ func (c *command) validate() error {
 	return nil
 }
 
-func (c *command) signedProposal() (*peer.SignedProposal, error) {
+func (c *command) newProposal(id gatewayid.Identity) (*peer.Proposal, error) {
 	argBytes, err := c.queryInstalledChaincodesArgsBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	proposal, err := proposal.New(
-		c.signingID,
+	return proposal.New(
+		id,
 		common.LifecycleChaincodeName,
 		queryInstalledTransactionName,
 		proposal.WithBytesArguments(argBytes),
+		proposal.WithTLSCertHash(c.tlsCertHash),
 	)
-	if err != nil {
-		return nil, err
-	}
+}
 
-	proposalBytes, err := proto.Marshal(proposal)
+func (c *command) submit(ctx context.Context, signedProposal *peer.SignedProposal) (*lifecycle.QueryInstalledChaincodesResult, error) {
+	proposalResponse, err := c.grpcClient.ProcessProposal(ctx, signedProposal, c.grpcOptions...)
 	if err != nil {
 		return nil, err
 	}
 
-	signature, err := c.signingID.Sign(proposalBytes)
-	if err != nil {
+	if err = proposal.CheckSuccessfulResponse(proposalResponse); err != nil {
 		return nil, err
 	}
 
-	signedProposal := &peer.SignedProposal{
-		ProposalBytes: proposalBytes,
-		Signature:     signature,
+	result := &lifecycle.QueryInstalledChaincodesResult{}
+	if err = proto.Unmarshal(proposalResponse.GetResponse().GetPayload(), result); err != nil {
+		return nil, fmt.Errorf("failed to deserialize query installed chaincode result: %w", err)
 	}
-	return signedProposal, nil
+
+	return result, nil
 }
 
 func (c *command) queryInstalledChaincodesArgsBytes() ([]byte, error) {
@@ -130,3 +147,12 @@ func WithCallOptions(options ...grpc.CallOption) Option {
 		return nil
 	}
 }
+
+// WithTLSCertHash specifies the hash of the client TLS certificate used to connect to the peer. This is required if the
+// peer checks that the query proposal is bound to the mutual TLS connection on which it is received.
+func WithTLSCertHash(hash []byte) Option {
+	return func(c *command) error {
+		c.tlsCertHash = hash
+		return nil
+	}
+}
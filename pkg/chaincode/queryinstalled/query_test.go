@@ -10,7 +10,9 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	adminproposal "github.com/bestbeforetoday/fabric-admin/pkg/proposal"
 	"github.com/golang/mock/gomock"
 	"github.com/hyperledger/fabric-protos-go-apiv2/common"
 	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
@@ -244,4 +246,82 @@ func TestQuery(t *testing.T) {
 		)
 		require.NoError(t, err)
 	})
+
+	t.Run("Signing abandoned when context done", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		release := make(chan struct{})
+		defer close(release)
+
+		mockSigner := NewMockSigningIdentity(controller)
+		mockSigner.EXPECT().MspID().AnyTimes()
+		mockSigner.EXPECT().Credentials().AnyTimes()
+		mockSigner.EXPECT().Sign(gomock.Any()).
+			DoAndReturn(func(message []byte) ([]byte, error) {
+				<-release
+				return nil, nil
+			}).
+			AnyTimes()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(0)
+
+		_, err := Query(
+			ctx,
+			mockSigner,
+			WithEndorserClient(mockEndorser),
+		)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestOfflineSigning(t *testing.T) {
+	t.Run("Submits externally signed proposal", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		unsignedProposal, err := NewProposal(
+			NewSigningIdentity(controller, nil),
+		)
+		require.NoError(t, err)
+
+		proposalBytes, err := adminproposal.Bytes(unsignedProposal)
+		require.NoError(t, err)
+
+		signedProposal, err := adminproposal.NewSignedProposal(unsignedProposal, []byte("OFFLINE_SIGNATURE"))
+		require.NoError(t, err)
+
+		var actual *peer.SignedProposal
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, in *peer.SignedProposal, _ ...grpc.CallOption) {
+				actual = in
+			}).
+			Return(NewProposalResponse(common.Status_SUCCESS, ""), nil)
+
+		_, err = Submit(
+			ctx,
+			signedProposal,
+			WithEndorserClient(mockEndorser),
+		)
+		require.NoError(t, err)
+
+		require.Equal(t, proposalBytes, actual.GetProposalBytes())
+		require.Equal(t, []byte("OFFLINE_SIGNATURE"), actual.GetSignature())
+	})
+
+	t.Run("Submit without gRPC client gives error", func(t *testing.T) {
+		_, err := Submit(
+			context.Background(),
+			&peer.SignedProposal{},
+		)
+		require.ErrorContains(t, err, "gRPC")
+	})
 }
@@ -0,0 +1,216 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/chaincode"
+	"github.com/bestbeforetoday/fabric-admin/pkg/policydsl"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"gopkg.in/yaml.v3"
+)
+
+// Desired state of a chaincode on a channel.
+type Desired struct {
+	Channel string `yaml:"channel"`
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	// Package is the path of the chaincode package file.
+	Package string `yaml:"package"`
+	// SignaturePolicy is the endorsement policy as a signature policy expression, such as
+	// "OR('Org1MSP.peer','Org2MSP.peer')".
+	SignaturePolicy string `yaml:"signaturePolicy"`
+	// ChannelConfigPolicy is the endorsement policy as a reference to a channel configuration policy, such as
+	// "/Channel/Application/Endorsement". If neither this nor SignaturePolicy is specified, the channel's default
+	// endorsement policy is used.
+	ChannelConfigPolicy string        `yaml:"channelConfigPolicy"`
+	EndorsementPlugin   string        `yaml:"endorsementPlugin"`
+	ValidationPlugin    string        `yaml:"validationPlugin"`
+	InitRequired        bool          `yaml:"initRequired"`
+	Collections         []*Collection `yaml:"collections"`
+
+	// baseDir is the directory against which a relative package path is resolved.
+	baseDir string
+}
+
+// Collection describes a private data collection, using the same field names as a Fabric collection configuration
+// file.
+type Collection struct {
+	Name string `yaml:"name"`
+	// Policy is a signature policy expression identifying the organizations that are members of the collection.
+	Policy            string             `yaml:"policy"`
+	RequiredPeerCount int32              `yaml:"requiredPeerCount"`
+	MaxPeerCount      int32              `yaml:"maxPeerCount"`
+	BlockToLive       uint64             `yaml:"blockToLive"`
+	MemberOnlyRead    bool               `yaml:"memberOnlyRead"`
+	MemberOnlyWrite   bool               `yaml:"memberOnlyWrite"`
+	EndorsementPolicy *EndorsementPolicy `yaml:"endorsementPolicy"`
+}
+
+// EndorsementPolicy for a private data collection, which overrides the chaincode endorsement policy for writes to the
+// collection.
+type EndorsementPolicy struct {
+	SignaturePolicy     string `yaml:"signaturePolicy"`
+	ChannelConfigPolicy string `yaml:"channelConfigPolicy"`
+}
+
+// LoadDesired reads a desired state document, in either JSON or YAML format, from a file. A relative package path in
+// the document is resolved against the directory containing the document.
+func LoadDesired(filename string) (*Desired, error) {
+	desiredBytes, err := os.ReadFile(filename) //#nosec G304 -- caller supplied document location
+	if err != nil {
+		return nil, fmt.Errorf("failed to read desired state: %w", err)
+	}
+
+	desired, err := ParseDesired(desiredBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	desired.baseDir = filepath.Dir(filename)
+	return desired, nil
+}
+
+// ParseDesired parses a desired state document in either JSON or YAML format. A relative package path in the document
+// is resolved against the current working directory.
+func ParseDesired(desiredBytes []byte) (*Desired, error) {
+	desired := &Desired{}
+	if err := yaml.Unmarshal(desiredBytes, desired); err != nil {
+		return nil, fmt.Errorf("failed to parse desired state: %w", err)
+	}
+
+	return desired, nil
+}
+
+// Validate that the desired state contains the required fields.
+func (d *Desired) Validate() error {
+	if d.Channel == "" {
+		return errors.New("no channel name supplied")
+	}
+	if d.Package == "" {
+		return errors.New("no chaincode package supplied")
+	}
+
+	definition, err := d.definition(1)
+	if err != nil {
+		return err
+	}
+
+	return definition.Validate()
+}
+
+// ChaincodePackage reads the chaincode package file.
+func (d *Desired) ChaincodePackage() ([]byte, error) {
+	chaincodePackage, err := os.ReadFile(d.packagePath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chaincode package: %w", err)
+	}
+
+	return chaincodePackage, nil
+}
+
+func (d *Desired) packagePath() string {
+	if filepath.IsAbs(d.Package) || d.baseDir == "" {
+		return d.Package
+	}
+
+	return filepath.Join(d.baseDir, d.Package)
+}
+
+func (d *Desired) definition(sequence int64) (*chaincode.Definition, error) {
+	collections, err := d.collectionConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &chaincode.Definition{
+		Name:                d.Name,
+		Version:             d.Version,
+		Sequence:            sequence,
+		EndorsementPlugin:   d.EndorsementPlugin,
+		ValidationPlugin:    d.ValidationPlugin,
+		SignaturePolicy:     d.SignaturePolicy,
+		ChannelConfigPolicy: d.ChannelConfigPolicy,
+		Collections:         collections,
+		InitRequired:        d.InitRequired,
+	}, nil
+}
+
+func (d *Desired) collectionConfig() (*peer.CollectionConfigPackage, error) {
+	if len(d.Collections) == 0 {
+		return nil, nil
+	}
+
+	result := &peer.CollectionConfigPackage{}
+	for _, collection := range d.Collections {
+		config, err := collection.staticConfig()
+		if err != nil {
+			return nil, fmt.Errorf("invalid collection %s: %w", collection.Name, err)
+		}
+
+		result.Config = append(result.Config, &peer.CollectionConfig{
+			Payload: &peer.CollectionConfig_StaticCollectionConfig{StaticCollectionConfig: config},
+		})
+	}
+
+	return result, nil
+}
+
+func (c *Collection) staticConfig() (*peer.StaticCollectionConfig, error) {
+	if c.Name == "" {
+		return nil, errors.New("no collection name supplied")
+	}
+
+	memberOrgsPolicy, err := policydsl.FromString(c.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid member organizations policy: %w", err)
+	}
+
+	endorsementPolicy, err := c.EndorsementPolicy.applicationPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	return &peer.StaticCollectionConfig{
+		Name: c.Name,
+		MemberOrgsPolicy: &peer.CollectionPolicyConfig{
+			Payload: &peer.CollectionPolicyConfig_SignaturePolicy{SignaturePolicy: memberOrgsPolicy},
+		},
+		RequiredPeerCount: c.RequiredPeerCount,
+		MaximumPeerCount:  c.MaxPeerCount,
+		BlockToLive:       c.BlockToLive,
+		MemberOnlyRead:    c.MemberOnlyRead,
+		MemberOnlyWrite:   c.MemberOnlyWrite,
+		EndorsementPolicy: endorsementPolicy,
+	}, nil
+}
+
+func (p *EndorsementPolicy) applicationPolicy() (*peer.ApplicationPolicy, error) {
+	switch {
+	case p == nil:
+		return nil, nil
+	case p.SignaturePolicy != "" && p.ChannelConfigPolicy != "":
+		return nil, errors.New("more than one endorsement policy supplied")
+	case p.SignaturePolicy != "":
+		signaturePolicy, err := policydsl.FromString(p.SignaturePolicy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endorsement policy: %w", err)
+		}
+		return &peer.ApplicationPolicy{
+			Type: &peer.ApplicationPolicy_SignaturePolicy{SignaturePolicy: signaturePolicy},
+		}, nil
+	case p.ChannelConfigPolicy != "":
+		return &peer.ApplicationPolicy{
+			Type: &peer.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: p.ChannelConfigPolicy},
+		}, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reconcile
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bestbeforetoday/fabric-admin/pkg/policydsl"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewChaincodePackage creates a minimal chaincode package with the supplied label.
+func NewChaincodePackage(t *testing.T, label string) []byte {
+	buffer := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for name, content := range map[string]string{
+		"metadata.json": `{"type":"golang","label":"` + label + `"}`,
+		"code.tar.gz":   "CODE",
+	} {
+		err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))})
+		require.NoError(t, err)
+		_, err = tarWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	return buffer.Bytes()
+}
+
+// NewDesired writes a chaincode package and a desired state document referencing it to a temporary directory, and
+// loads the desired state.
+func NewDesired(t *testing.T, chaincodePackage []byte) *Desired {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "basic.tar.gz"), chaincodePackage, 0o600))
+
+	document := "channel: CHANNEL\nname: basic\nversion: \"1.0\"\npackage: basic.tar.gz\n"
+	filename := filepath.Join(dir, "basic.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(document), 0o600))
+
+	desired, err := LoadDesired(filename)
+	require.NoError(t, err)
+
+	return desired
+}
+
+func TestDesired(t *testing.T) {
+	t.Run("Parses YAML document", func(t *testing.T) {
+		document := `
+channel: mychannel
+name: basic
+version: "1.1"
+package: /packages/basic.tar.gz
+signaturePolicy: OR('Org1MSP.peer','Org2MSP.peer')
+endorsementPlugin: escc
+validationPlugin: vscc
+initRequired: true
+collections:
+  - name: private
+    policy: OR('Org1MSP.member','Org2MSP.member')
+    requiredPeerCount: 1
+    maxPeerCount: 2
+    blockToLive: 100
+    memberOnlyRead: true
+    memberOnlyWrite: true
+    endorsementPolicy:
+      channelConfigPolicy: /Channel/Application/Writers
+`
+		actual, err := ParseDesired([]byte(document))
+		require.NoError(t, err)
+
+		expected := &Desired{
+			Channel:           "mychannel",
+			Name:              "basic",
+			Version:           "1.1",
+			Package:           "/packages/basic.tar.gz",
+			SignaturePolicy:   "OR('Org1MSP.peer','Org2MSP.peer')",
+			EndorsementPlugin: "escc",
+			ValidationPlugin:  "vscc",
+			InitRequired:      true,
+			Collections: []*Collection{
+				{
+					Name:              "private",
+					Policy:            "OR('Org1MSP.member','Org2MSP.member')",
+					RequiredPeerCount: 1,
+					MaxPeerCount:      2,
+					BlockToLive:       100,
+					MemberOnlyRead:    true,
+					MemberOnlyWrite:   true,
+					EndorsementPolicy: &EndorsementPolicy{ChannelConfigPolicy: "/Channel/Application/Writers"},
+				},
+			},
+		}
+		require.Equal(t, expected, actual)
+		require.NoError(t, actual.Validate())
+	})
+
+	t.Run("Parses JSON document", func(t *testing.T) {
+		actual, err := ParseDesired([]byte(`{"channel":"mychannel","name":"basic","version":"1.0","package":"basic.tar.gz"}`))
+		require.NoError(t, err)
+
+		require.Equal(t, &Desired{Channel: "mychannel", Name: "basic", Version: "1.0", Package: "basic.tar.gz"}, actual)
+	})
+
+	t.Run("Invalid document gives error", func(t *testing.T) {
+		_, err := ParseDesired([]byte("channel: ["))
+		require.Error(t, err)
+	})
+
+	t.Run("Relative package path resolved against document directory", func(t *testing.T) {
+		chaincodePackage := NewChaincodePackage(t, "basic_1.0")
+		desired := NewDesired(t, chaincodePackage)
+
+		actual, err := desired.ChaincodePackage()
+		require.NoError(t, err)
+		require.Equal(t, chaincodePackage, actual)
+	})
+
+	t.Run("Missing package file gives error", func(t *testing.T) {
+		desired := &Desired{Package: filepath.Join(t.TempDir(), "missing.tar.gz")}
+
+		_, err := desired.ChaincodePackage()
+		require.ErrorContains(t, err, "chaincode package")
+	})
+
+	t.Run("Collections converted to collection configuration", func(t *testing.T) {
+		desired := &Desired{
+			Collections: []*Collection{
+				{
+					Name:              "private",
+					Policy:            "OR('Org1MSP.member')",
+					RequiredPeerCount: 1,
+					MaxPeerCount:      2,
+					BlockToLive:       100,
+					MemberOnlyRead:    true,
+					EndorsementPolicy: &EndorsementPolicy{SignaturePolicy: "OR('Org1MSP.peer')"},
+				},
+			},
+		}
+
+		actual, err := desired.collectionConfig()
+		require.NoError(t, err)
+
+		memberOrgsPolicy, err := policydsl.FromString("OR('Org1MSP.member')")
+		require.NoError(t, err)
+		endorsementPolicy, err := policydsl.FromString("OR('Org1MSP.peer')")
+		require.NoError(t, err)
+
+		expected := &peer.CollectionConfigPackage{
+			Config: []*peer.CollectionConfig{
+				{
+					Payload: &peer.CollectionConfig_StaticCollectionConfig{
+						StaticCollectionConfig: &peer.StaticCollectionConfig{
+							Name: "private",
+							MemberOrgsPolicy: &peer.CollectionPolicyConfig{
+								Payload: &peer.CollectionPolicyConfig_SignaturePolicy{SignaturePolicy: memberOrgsPolicy},
+							},
+							RequiredPeerCount: 1,
+							MaximumPeerCount:  2,
+							BlockToLive:       100,
+							MemberOnlyRead:    true,
+							EndorsementPolicy: &peer.ApplicationPolicy{
+								Type: &peer.ApplicationPolicy_SignaturePolicy{SignaturePolicy: endorsementPolicy},
+							},
+						},
+					},
+				},
+			},
+		}
+		require.True(t, proto.Equal(expected, actual), "Expected %v, got %v", expected, actual)
+	})
+
+	for name, testCase := range map[string]struct {
+		desired  *Desired
+		expected string
+	}{
+		"no channel": {
+			desired:  &Desired{Name: "basic", Version: "1.0", Package: "basic.tar.gz"},
+			expected: "channel",
+		},
+		"no package": {
+			desired:  &Desired{Channel: "mychannel", Name: "basic", Version: "1.0"},
+			expected: "package",
+		},
+		"no name": {
+			desired:  &Desired{Channel: "mychannel", Version: "1.0", Package: "basic.tar.gz"},
+			expected: "name",
+		},
+		"no version": {
+			desired:  &Desired{Channel: "mychannel", Name: "basic", Package: "basic.tar.gz"},
+			expected: "version",
+		},
+		"multiple policies": {
+			desired: &Desired{
+				Channel: "mychannel", Name: "basic", Version: "1.0", Package: "basic.tar.gz",
+				SignaturePolicy: "OR('Org1MSP.peer')", ChannelConfigPolicy: "/Channel/Application/Endorsement",
+			},
+			expected: "more than one",
+		},
+		"collection without policy": {
+			desired: &Desired{
+				Channel: "mychannel", Name: "basic", Version: "1.0", Package: "basic.tar.gz",
+				Collections: []*Collection{{Name: "private"}},
+			},
+			expected: "private",
+		},
+		"collection without name": {
+			desired: &Desired{
+				Channel: "mychannel", Name: "basic", Version: "1.0", Package: "basic.tar.gz",
+				Collections: []*Collection{{Policy: "OR('Org1MSP.member')"}},
+			},
+			expected: "collection name",
+		},
+	} {
+		t.Run("Invalid desired state gives error: "+name, func(t *testing.T) {
+			err := testCase.desired.Validate()
+			require.ErrorContains(t, err, testCase.expected)
+		})
+	}
+}
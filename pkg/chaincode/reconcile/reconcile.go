@@ -0,0 +1,514 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package reconcile provides support for managing a chaincode declaratively. A desired state document describes the
+// chaincode that should be running on a channel. This is compared with the chaincode packages installed on each peer
+// and the chaincode definition committed to the channel to produce a plan of the install, approve and commit actions
+// needed to reach the desired state, which can then be applied.
+//
+// The package approved by each organization is not part of the committed chaincode definition. If only the chaincode
+// package changes, organizations that approved a different package approve the committed definition again with the
+// desired package, and no new definition is committed.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/common"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/approve"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/checkcommitreadiness"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/deploy"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/install"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/queryapproved"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/querycommitted"
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/queryinstalled"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"google.golang.org/grpc"
+)
+
+// ActionType identifies a chaincode lifecycle step.
+type ActionType string
+
+const (
+	ActionInstall ActionType = "install"
+	ActionApprove ActionType = "approve"
+	ActionCommit  ActionType = "commit"
+)
+
+// Action required to reach the desired state.
+type Action struct {
+	Type ActionType
+	// Target of the action, which is a peer name for install, an organization MSP ID for approve, and the channel name
+	// for commit.
+	Target string
+	// Reason the action is required.
+	Reason string
+}
+
+func (a *Action) String() string {
+	return fmt.Sprintf("%s %s: %s", a.Type, a.Target, a.Reason)
+}
+
+// Plan of the actions required to reach the desired state.
+type Plan struct {
+	Channel   string
+	Chaincode string
+	PackageID string
+	// Sequence of the chaincode definition to be approved and committed, or of the committed definition if it is
+	// unchanged.
+	Sequence int64
+	Actions  []*Action
+}
+
+// InSync returns true if no actions are required to reach the desired state.
+func (p *Plan) InSync() bool {
+	return len(p.Actions) == 0
+}
+
+func (p *Plan) hasAction(actionType ActionType) bool {
+	for _, action := range p.Actions {
+		if action.Type == actionType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Plan) hasTarget(actionType ActionType, target string) bool {
+	for _, action := range p.Actions {
+		if action.Type == actionType && action.Target == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewPlan compares the desired state with the state of the supplied organizations' peers and the channel, and returns
+// the actions required to reach the desired state. No changes are made.
+func NewPlan(ctx context.Context, desired *Desired, options ...Option) (*Plan, error) {
+	reconcileCommand := &command{
+		desired: desired,
+	}
+
+	if err := common.ApplyOptions(reconcileCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := reconcileCommand.validate(); err != nil {
+		return nil, err
+	}
+
+	return reconcileCommand.plan(ctx)
+}
+
+// Apply the actions required to reach the desired state. The plan that was applied is returned, even if applying it
+// fails. If a chaincode definition is to be committed, the actions are applied using deploy.Deploy, and a failure is
+// reported as a *deploy.StepError. Otherwise the chaincode package is installed on peers that do not have it, and then
+// approved at the committed sequence for organizations that have approved a different package. Apply can safely be
+// called again after a failure since a new plan is created that accounts for the actions already completed.
+//
+// Applying a commit blocks until every peer has committed the chaincode definition, so the context should include a
+// suitable timeout.
+func Apply(ctx context.Context, desired *Desired, options ...Option) (*Plan, error) {
+	reconcileCommand := &command{
+		desired: desired,
+	}
+
+	if err := common.ApplyOptions(reconcileCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := reconcileCommand.validate(); err != nil {
+		return nil, err
+	}
+
+	plan, err := reconcileCommand.plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, reconcileCommand.apply(ctx, plan)
+}
+
+type command struct {
+	grpcOptions      []grpc.CallOption
+	desired          *Desired
+	organizations    []*deploy.Organization
+	chaincodePackage []byte
+}
+
+func (c *command) validate() error {
+	if c.desired == nil {
+		return errors.New("no desired state supplied")
+	}
+	if err := c.desired.Validate(); err != nil {
+		return err
+	}
+	if len(c.organizations) == 0 {
+		return errors.New("no organizations supplied")
+	}
+
+	for _, org := range c.organizations {
+		if org.SigningID == nil {
+			return errors.New("no signing identity supplied for organization")
+		}
+		if len(org.Peers) == 0 {
+			return fmt.Errorf("no peers supplied for organization %s", org.SigningID.MspID())
+		}
+	}
+
+	return nil
+}
+
+func (c *command) plan(ctx context.Context) (*Plan, error) {
+	chaincodePackage, err := c.desired.ChaincodePackage()
+	if err != nil {
+		return nil, err
+	}
+	c.chaincodePackage = chaincodePackage
+
+	packageID, err := deploy.PackageID(chaincodePackage)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		Channel:   c.desired.Channel,
+		Chaincode: c.desired.Name,
+		PackageID: packageID,
+	}
+
+	if err = c.planInstall(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	if err = c.planDefinition(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func (c *command) planInstall(ctx context.Context, plan *Plan) error {
+	for _, org := range c.organizations {
+		for _, orgPeer := range org.Peers {
+			installed, err := c.isInstalled(ctx, org, orgPeer, plan.PackageID)
+			if err != nil {
+				return fmt.Errorf("failed to query installed chaincodes on peer %s: %w", orgPeer.Name, err)
+			}
+
+			if !installed {
+				plan.Actions = append(plan.Actions, &Action{
+					Type:   ActionInstall,
+					Target: orgPeer.Name,
+					Reason: fmt.Sprintf("package %s not installed", plan.PackageID),
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *command) isInstalled(ctx context.Context, org *deploy.Organization, orgPeer *deploy.Peer, packageID string) (bool, error) {
+	result, err := queryinstalled.Query(
+		ctx,
+		org.SigningID,
+		queryinstalled.WithClientConnection(orgPeer.Connection),
+		queryinstalled.WithCallOptions(c.grpcOptions...),
+		queryinstalled.WithTLSCertHash(orgPeer.TLSCertHash),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	for _, installed := range result.GetInstalledChaincodes() {
+		if installed.GetPackageId() == packageID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (c *command) planDefinition(ctx context.Context, plan *Plan) error {
+	committed, err := c.queryCommitted(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query committed chaincode definitions: %w", err)
+	}
+
+	if committed == nil {
+		plan.Sequence = 1
+		return c.planCommit(ctx, plan, []string{"not committed"})
+	}
+
+	definition, err := c.desired.definition(committed.GetSequence())
+	if err != nil {
+		return err
+	}
+
+	differences, err := definition.Differences(committed)
+	if err != nil {
+		return err
+	}
+
+	if len(differences) > 0 {
+		plan.Sequence = committed.GetSequence() + 1
+		return c.planCommit(ctx, plan, differences)
+	}
+
+	plan.Sequence = committed.GetSequence()
+	return c.planPackageApprovals(ctx, plan)
+}
+
+func (c *command) planCommit(ctx context.Context, plan *Plan, differences []string) error {
+	approvals, err := c.approvals(ctx, plan.Sequence)
+	if err != nil {
+		return fmt.Errorf("failed to check commit readiness: %w", err)
+	}
+
+	for _, org := range c.organizations {
+		if mspID := org.SigningID.MspID(); !approvals[mspID] {
+			plan.Actions = append(plan.Actions, &Action{
+				Type:   ActionApprove,
+				Target: mspID,
+				Reason: fmt.Sprintf("sequence %d not approved", plan.Sequence),
+			})
+		}
+	}
+
+	plan.Actions = append(plan.Actions, &Action{
+		Type:   ActionCommit,
+		Target: plan.Channel,
+		Reason: strings.Join(differences, ", "),
+	})
+
+	return nil
+}
+
+// planPackageApprovals plans approval of the committed chaincode definition for organizations that approved a different
+// chaincode package, or did not approve the committed sequence.
+func (c *command) planPackageApprovals(ctx context.Context, plan *Plan) error {
+	for _, org := range c.organizations {
+		mspID := org.SigningID.MspID()
+
+		// Commit only requires approval by a majority of organizations, so some may not have approved this sequence.
+		approved, err := c.queryApproved(ctx, org, plan.Sequence)
+		if err != nil && !errors.Is(err, queryapproved.ErrNotApproved) {
+			return fmt.Errorf("failed to query approved chaincode definition for organization %s: %w", mspID, err)
+		}
+
+		if approvedPackageID := approved.GetSource().GetLocalPackage().GetPackageId(); approvedPackageID != plan.PackageID {
+			plan.Actions = append(plan.Actions, &Action{
+				Type:   ActionApprove,
+				Target: mspID,
+				Reason: fmt.Sprintf("package %s not approved", plan.PackageID),
+			})
+		}
+	}
+
+	return nil
+}
+
+func (c *command) queryApproved(
+	ctx context.Context,
+	org *deploy.Organization,
+	sequence int64,
+) (*lifecycle.QueryApprovedChaincodeDefinitionResult, error) {
+	queryPeer := org.Peers[0]
+
+	return queryapproved.Query(
+		ctx,
+		org.SigningID,
+		queryapproved.WithClientConnection(queryPeer.Connection),
+		queryapproved.WithCallOptions(c.grpcOptions...),
+		queryapproved.WithTLSCertHash(queryPeer.TLSCertHash),
+		queryapproved.WithChannel(c.desired.Channel),
+		queryapproved.WithChaincodeName(c.desired.Name),
+		queryapproved.WithSequence(sequence),
+	)
+}
+
+func (c *command) queryCommitted(ctx context.Context) (*lifecycle.QueryChaincodeDefinitionsResult_ChaincodeDefinition, error) {
+	org := c.organizations[0]
+	queryPeer := org.Peers[0]
+
+	result, err := querycommitted.QueryAll(
+		ctx,
+		org.SigningID,
+		querycommitted.WithClientConnection(queryPeer.Connection),
+		querycommitted.WithCallOptions(c.grpcOptions...),
+		querycommitted.WithTLSCertHash(queryPeer.TLSCertHash),
+		querycommitted.WithChannel(c.desired.Channel),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, definition := range result.GetChaincodeDefinitions() {
+		if definition.GetName() == c.desired.Name {
+			return definition, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (c *command) approvals(ctx context.Context, sequence int64) (map[string]bool, error) {
+	definition, err := c.desired.definition(sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	org := c.organizations[0]
+	queryPeer := org.Peers[0]
+
+	return checkcommitreadiness.Check(
+		ctx,
+		org.SigningID,
+		checkcommitreadiness.WithClientConnection(queryPeer.Connection),
+		checkcommitreadiness.WithCallOptions(c.grpcOptions...),
+		checkcommitreadiness.WithTLSCertHash(queryPeer.TLSCertHash),
+		checkcommitreadiness.WithChannel(c.desired.Channel),
+		checkcommitreadiness.WithChaincodeName(definition.Name),
+		checkcommitreadiness.WithVersion(definition.Version),
+		checkcommitreadiness.WithSequence(definition.Sequence),
+		checkcommitreadiness.WithSignaturePolicy(definition.SignaturePolicy),
+		checkcommitreadiness.WithChannelConfigPolicy(definition.ChannelConfigPolicy),
+		checkcommitreadiness.WithCollections(definition.Collections),
+		checkcommitreadiness.WithInitRequired(definition.InitRequired),
+		checkcommitreadiness.WithEndorsementPlugin(definition.EndorsementPlugin),
+		checkcommitreadiness.WithValidationPlugin(definition.ValidationPlugin),
+	)
+}
+
+func (c *command) apply(ctx context.Context, plan *Plan) error {
+	if plan.hasAction(ActionCommit) {
+		return c.deploy(ctx, plan)
+	}
+
+	if err := c.install(ctx, plan); err != nil {
+		return err
+	}
+
+	return c.approve(ctx, plan)
+}
+
+func (c *command) deploy(ctx context.Context, plan *Plan) error {
+	definition, err := c.desired.definition(plan.Sequence)
+	if err != nil {
+		return err
+	}
+
+	options := []deploy.Option{
+		deploy.WithCallOptions(c.grpcOptions...),
+		deploy.WithChaincodePackageBytes(c.chaincodePackage),
+		deploy.WithChannel(plan.Channel),
+		deploy.WithChaincodeName(definition.Name),
+		deploy.WithVersion(definition.Version),
+		deploy.WithSequence(definition.Sequence),
+		deploy.WithSignaturePolicy(definition.SignaturePolicy),
+		deploy.WithChannelConfigPolicy(definition.ChannelConfigPolicy),
+		deploy.WithCollections(definition.Collections),
+		deploy.WithInitRequired(definition.InitRequired),
+		deploy.WithEndorsementPlugin(definition.EndorsementPlugin),
+		deploy.WithValidationPlugin(definition.ValidationPlugin),
+	}
+	for _, org := range c.organizations {
+		options = append(options, deploy.WithOrganization(org))
+	}
+
+	_, err = deploy.Deploy(ctx, options...)
+	return err
+}
+
+func (c *command) install(ctx context.Context, plan *Plan) error {
+	for _, org := range c.organizations {
+		for _, orgPeer := range org.Peers {
+			if !plan.hasTarget(ActionInstall, orgPeer.Name) {
+				continue
+			}
+
+			err := install.Install(
+				ctx,
+				org.SigningID,
+				install.WithClientConnection(orgPeer.Connection),
+				install.WithChaincodePackageBytes(c.chaincodePackage),
+				install.WithCallOptions(c.grpcOptions...),
+				install.WithTLSCertHash(orgPeer.TLSCertHash),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to install on peer %s: %w", orgPeer.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *command) approve(ctx context.Context, plan *Plan) error {
+	definition, err := c.desired.definition(plan.Sequence)
+	if err != nil {
+		return err
+	}
+
+	for _, org := range c.organizations {
+		mspID := org.SigningID.MspID()
+		if !plan.hasTarget(ActionApprove, mspID) {
+			continue
+		}
+
+		gatewayPeer := org.Peers[0]
+		err := approve.Approve(
+			ctx,
+			org.SigningID,
+			approve.WithClientConnection(gatewayPeer.Connection),
+			approve.WithCallOptions(c.grpcOptions...),
+			approve.WithTLSCertHash(gatewayPeer.TLSCertHash),
+			approve.WithEndorsingOrganizations(mspID),
+			approve.WithChannel(plan.Channel),
+			approve.WithPackageID(plan.PackageID),
+			approve.WithChaincodeName(definition.Name),
+			approve.WithVersion(definition.Version),
+			approve.WithSequence(definition.Sequence),
+			approve.WithSignaturePolicy(definition.SignaturePolicy),
+			approve.WithChannelConfigPolicy(definition.ChannelConfigPolicy),
+			approve.WithCollections(definition.Collections),
+			approve.WithInitRequired(definition.InitRequired),
+			approve.WithEndorsementPlugin(definition.EndorsementPlugin),
+			approve.WithValidationPlugin(definition.ValidationPlugin),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to approve for organization %s: %w", mspID, err)
+		}
+	}
+
+	return nil
+}
+
+type Option = func(*command) error
+
+// WithOrganization specifies an organization whose peers should have the chaincode package installed, and which should
+// approve the chaincode definition. This option can be specified multiple times. The first peer of the first
+// organization is used to query the channel.
+func WithOrganization(org *deploy.Organization) Option {
+	return func(c *command) error {
+		c.organizations = append(c.organizations, org)
+		return nil
+	}
+}
+
+// WithCallOptions specifies the gRPC call options to be used.
+func WithCallOptions(options ...grpc.CallOption) Option {
+	return func(c *command) error {
+		c.grpcOptions = append(c.grpcOptions, options...)
+		return nil
+	}
+}
@@ -0,0 +1,740 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/deploy"
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+//go:generate mockgen -destination ./signingidentity_mock_test.go -package ${GOPACKAGE} github.com/bestbeforetoday/fabric-admin/pkg/identity SigningIdentity
+
+const commitBlockNumber = 7
+
+func NewSigningIdentity(controller *gomock.Controller, mspID string) *MockSigningIdentity {
+	mockIdentity := NewMockSigningIdentity(controller)
+	mockIdentity.EXPECT().MspID().Return(mspID).AnyTimes()
+	mockIdentity.EXPECT().Credentials().Return([]byte(mspID + "_CREDENTIALS")).AnyTimes()
+	mockIdentity.EXPECT().Sign(gomock.Any()).Return([]byte("SIGNATURE"), nil).AnyTimes()
+
+	return mockIdentity
+}
+
+// FakeNetwork records the chaincode lifecycle state of a set of fake peers, and the calls made to them.
+type FakeNetwork struct {
+	mu                  sync.Mutex
+	Installed           map[string][]string
+	Approvals           map[string]bool
+	ApproveArgs         map[string]*lifecycle.ApproveChaincodeDefinitionForMyOrgArgs
+	Committed           map[string]*lifecycle.QueryChaincodeDefinitionResult
+	EndorsingOrgs       []string
+	CommitTransactionID string
+	Calls               []string
+}
+
+func NewFakeNetwork() *FakeNetwork {
+	return &FakeNetwork{
+		Installed:   make(map[string][]string),
+		Approvals:   make(map[string]bool),
+		ApproveArgs: make(map[string]*lifecycle.ApproveChaincodeDefinitionForMyOrgArgs),
+		Committed:   make(map[string]*lifecycle.QueryChaincodeDefinitionResult),
+	}
+}
+
+// Peer returns a deployment peer whose connection is served by the fake network.
+func (n *FakeNetwork) Peer(name string) *deploy.Peer {
+	return &deploy.Peer{
+		Name:       name,
+		Connection: &FakeConnection{name: name, network: n},
+	}
+}
+
+// CallCount returns the number of times a lifecycle function was invoked on a peer.
+func (n *FakeNetwork) CallCount(peerName string, function string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	count := 0
+	for _, call := range n.Calls {
+		if call == peerName+"/"+function {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (n *FakeNetwork) record(peerName string, function string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.Calls = append(n.Calls, peerName+"/"+function)
+}
+
+// FakeConnection implements a gRPC client connection to a fake peer.
+type FakeConnection struct {
+	name    string
+	network *FakeNetwork
+}
+
+func (c *FakeConnection) Invoke(_ context.Context, method string, args interface{}, reply interface{}, _ ...grpc.CallOption) error {
+	var response proto.Message
+	var err error
+
+	switch method {
+	case "/protos.Endorser/ProcessProposal":
+		response, err = c.processProposal(args.(*peer.SignedProposal))
+	case "/gateway.Gateway/Endorse":
+		response, err = c.endorse(args.(*gateway.EndorseRequest))
+	case "/gateway.Gateway/Submit":
+		response = &gateway.SubmitResponse{}
+	case "/gateway.Gateway/CommitStatus":
+		response = &gateway.CommitStatusResponse{Result: peer.TxValidationCode_VALID, BlockNumber: commitBlockNumber}
+	default:
+		err = fmt.Errorf("unexpected method: %s", method)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	proto.Merge(reply.(proto.Message), response)
+	return nil
+}
+
+func (c *FakeConnection) processProposal(signedProposal *peer.SignedProposal) (*peer.ProposalResponse, error) {
+	mspID, function, arg, err := unmarshalInvocation(signedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	c.network.record(c.name, function)
+
+	c.network.mu.Lock()
+	defer c.network.mu.Unlock()
+
+	var result proto.Message
+	switch function {
+	case "QueryInstalledChaincodes":
+		installed := &lifecycle.QueryInstalledChaincodesResult{}
+		for _, packageID := range c.network.Installed[c.name] {
+			installed.InstalledChaincodes = append(installed.InstalledChaincodes, &lifecycle.QueryInstalledChaincodesResult_InstalledChaincode{
+				PackageId: packageID,
+			})
+		}
+		result = installed
+	case "InstallChaincode":
+		args := &lifecycle.InstallChaincodeArgs{}
+		if err = proto.Unmarshal(arg, args); err != nil {
+			return nil, err
+		}
+		packageID, err := deploy.PackageID(args.GetChaincodeInstallPackage())
+		if err != nil {
+			return nil, err
+		}
+		c.network.Installed[c.name] = append(c.network.Installed[c.name], packageID)
+		result = &lifecycle.InstallChaincodeResult{PackageId: packageID}
+	case "CheckCommitReadiness":
+		approvals := make(map[string]bool, len(c.network.Approvals))
+		for mspID, approved := range c.network.Approvals {
+			approvals[mspID] = approved
+		}
+		result = &lifecycle.CheckCommitReadinessResult{Approvals: approvals}
+	case "QueryChaincodeDefinitions":
+		definitions := &lifecycle.QueryChaincodeDefinitionsResult{}
+		for name, definition := range c.network.Committed {
+			definitions.ChaincodeDefinitions = append(definitions.ChaincodeDefinitions, &lifecycle.QueryChaincodeDefinitionsResult_ChaincodeDefinition{
+				Name:                name,
+				Sequence:            definition.GetSequence(),
+				Version:             definition.GetVersion(),
+				EndorsementPlugin:   definition.GetEndorsementPlugin(),
+				ValidationPlugin:    definition.GetValidationPlugin(),
+				ValidationParameter: definition.GetValidationParameter(),
+				Collections:         definition.GetCollections(),
+				InitRequired:        definition.GetInitRequired(),
+			})
+		}
+		result = definitions
+	case "QueryApprovedChaincodeDefinition":
+		args := &lifecycle.QueryApprovedChaincodeDefinitionArgs{}
+		if err = proto.Unmarshal(arg, args); err != nil {
+			return nil, err
+		}
+		approved, ok := c.network.ApproveArgs[mspID]
+		if !ok || approved.GetName() != args.GetName() || approved.GetSequence() != args.GetSequence() {
+			return &peer.ProposalResponse{
+				Response: &peer.Response{
+					Status:  int32(common.Status_INTERNAL_SERVER_ERROR),
+					Message: fmt.Sprintf("could not fetch approved chaincode definition (name: '%s', sequence: '%d')", args.GetName(), args.GetSequence()),
+				},
+			}, nil
+		}
+		result = &lifecycle.QueryApprovedChaincodeDefinitionResult{
+			Sequence: approved.GetSequence(),
+			Version:  approved.GetVersion(),
+			Source:   approved.GetSource(),
+		}
+	default:
+		return nil, fmt.Errorf("unexpected proposal function: %s", function)
+	}
+
+	payload, err := proto.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &peer.ProposalResponse{
+		Response: &peer.Response{
+			Status:  int32(common.Status_SUCCESS),
+			Payload: payload,
+		},
+	}
+	return response, nil
+}
+
+func (c *FakeConnection) endorse(request *gateway.EndorseRequest) (*gateway.EndorseResponse, error) {
+	mspID, function, arg, err := unmarshalInvocation(request.GetProposedTransaction())
+	if err != nil {
+		return nil, err
+	}
+
+	c.network.record(c.name, function)
+
+	c.network.mu.Lock()
+	defer c.network.mu.Unlock()
+
+	switch function {
+	case "ApproveChaincodeDefinitionForMyOrg":
+		args := &lifecycle.ApproveChaincodeDefinitionForMyOrgArgs{}
+		if err = proto.Unmarshal(arg, args); err != nil {
+			return nil, err
+		}
+		c.network.Approvals[mspID] = true
+		c.network.ApproveArgs[mspID] = args
+	case "CommitChaincodeDefinition":
+		args := &lifecycle.CommitChaincodeDefinitionArgs{}
+		if err = proto.Unmarshal(arg, args); err != nil {
+			return nil, err
+		}
+		c.network.CommitTransactionID = request.GetTransactionId()
+		c.network.EndorsingOrgs = request.GetEndorsingOrganizations()
+		c.network.Committed[args.GetName()] = &lifecycle.QueryChaincodeDefinitionResult{
+			Sequence:            args.GetSequence(),
+			Version:             args.GetVersion(),
+			EndorsementPlugin:   args.GetEndorsementPlugin(),
+			ValidationPlugin:    args.GetValidationPlugin(),
+			ValidationParameter: args.GetValidationParameter(),
+			Collections:         args.GetCollections(),
+			InitRequired:        args.GetInitRequired(),
+		}
+	default:
+		return nil, fmt.Errorf("unexpected transaction function: %s", function)
+	}
+
+	response := &gateway.EndorseResponse{
+		PreparedTransaction: &common.Envelope{Payload: []byte("PREPARED_PAYLOAD")},
+	}
+	return response, nil
+}
+
+func (c *FakeConnection) NewStream(ctx context.Context, _ *grpc.StreamDesc, method string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+	if method != "/protos.Deliver/DeliverFiltered" {
+		return nil, fmt.Errorf("unexpected stream method: %s", method)
+	}
+
+	c.network.record(c.name, "DeliverFiltered")
+
+	return &FakeDeliverStream{ctx: ctx, network: c.network}, nil
+}
+
+// FakeDeliverStream delivers a single filtered block containing the network's commit transaction.
+type FakeDeliverStream struct {
+	ctx     context.Context
+	network *FakeNetwork
+}
+
+func (s *FakeDeliverStream) Header() (metadata.MD, error) { return nil, nil }
+func (s *FakeDeliverStream) Trailer() metadata.MD         { return nil }
+func (s *FakeDeliverStream) CloseSend() error             { return nil }
+func (s *FakeDeliverStream) Context() context.Context     { return s.ctx }
+func (s *FakeDeliverStream) SendMsg(interface{}) error    { return nil }
+
+func (s *FakeDeliverStream) RecvMsg(m interface{}) error {
+	s.network.mu.Lock()
+	defer s.network.mu.Unlock()
+
+	response := &peer.DeliverResponse{
+		Type: &peer.DeliverResponse_FilteredBlock{
+			FilteredBlock: &peer.FilteredBlock{
+				Number: commitBlockNumber,
+				FilteredTransactions: []*peer.FilteredTransaction{
+					{Txid: s.network.CommitTransactionID, TxValidationCode: peer.TxValidationCode_VALID},
+				},
+			},
+		},
+	}
+	proto.Merge(m.(proto.Message), response)
+	return nil
+}
+
+func unmarshalInvocation(signedProposal *peer.SignedProposal) (string, string, []byte, error) {
+	proposal := &peer.Proposal{}
+	if err := proto.Unmarshal(signedProposal.GetProposalBytes(), proposal); err != nil {
+		return "", "", nil, err
+	}
+
+	header := &common.Header{}
+	if err := proto.Unmarshal(proposal.GetHeader(), header); err != nil {
+		return "", "", nil, err
+	}
+
+	signatureHeader := &common.SignatureHeader{}
+	if err := proto.Unmarshal(header.GetSignatureHeader(), signatureHeader); err != nil {
+		return "", "", nil, err
+	}
+
+	creator := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(signatureHeader.GetCreator(), creator); err != nil {
+		return "", "", nil, err
+	}
+
+	payload := &peer.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.GetPayload(), payload); err != nil {
+		return "", "", nil, err
+	}
+
+	invocationSpec := &peer.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(payload.GetInput(), invocationSpec); err != nil {
+		return "", "", nil, err
+	}
+
+	args := invocationSpec.GetChaincodeSpec().GetInput().GetArgs()
+	if len(args) != 2 {
+		return "", "", nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+	}
+
+	return creator.GetMspid(), string(args[0]), args[1], nil
+}
+
+// TestReconciliation is a network of two organizations, the first with two peers and the second with one, and a
+// desired state for the first version of a chaincode.
+type TestReconciliation struct {
+	Network   *FakeNetwork
+	Desired   *Desired
+	PackageID string
+	Options   []Option
+}
+
+func NewTestReconciliation(t *testing.T, controller *gomock.Controller) *TestReconciliation {
+	network := NewFakeNetwork()
+	desired := NewDesired(t, NewChaincodePackage(t, "basic_1.0"))
+
+	chaincodePackage, err := desired.ChaincodePackage()
+	require.NoError(t, err)
+	packageID, err := deploy.PackageID(chaincodePackage)
+	require.NoError(t, err)
+
+	return &TestReconciliation{
+		Network:   network,
+		Desired:   desired,
+		PackageID: packageID,
+		Options: []Option{
+			WithOrganization(&deploy.Organization{
+				SigningID: NewSigningIdentity(controller, "Org1MSP"),
+				Peers:     []*deploy.Peer{network.Peer("peer0.org1"), network.Peer("peer1.org1")},
+			}),
+			WithOrganization(&deploy.Organization{
+				SigningID: NewSigningIdentity(controller, "Org2MSP"),
+				Peers:     []*deploy.Peer{network.Peer("peer0.org2")},
+			}),
+		},
+	}
+}
+
+// Commit the desired state to the network, approved by both organizations with the desired package, and install the
+// desired package on all peers.
+func (r *TestReconciliation) Commit(t *testing.T, sequence int64) {
+	definition, err := r.Desired.definition(sequence)
+	require.NoError(t, err)
+
+	validationParameter, err := definition.ValidationParameter()
+	require.NoError(t, err)
+
+	r.Network.Committed[definition.Name] = &lifecycle.QueryChaincodeDefinitionResult{
+		Sequence:            definition.Sequence,
+		Version:             definition.Version,
+		EndorsementPlugin:   definition.EndorsementPluginOrDefault(),
+		ValidationPlugin:    definition.ValidationPluginOrDefault(),
+		ValidationParameter: validationParameter,
+		Collections:         definition.Collections,
+		InitRequired:        definition.InitRequired,
+	}
+
+	for _, mspID := range []string{"Org1MSP", "Org2MSP"} {
+		r.Network.ApproveArgs[mspID] = &lifecycle.ApproveChaincodeDefinitionForMyOrgArgs{
+			Name:     definition.Name,
+			Version:  definition.Version,
+			Sequence: definition.Sequence,
+			Source: &lifecycle.ChaincodeSource{
+				Type: &lifecycle.ChaincodeSource_LocalPackage{
+					LocalPackage: &lifecycle.ChaincodeSource_Local{PackageId: r.PackageID},
+				},
+			},
+		}
+	}
+
+	for _, peerName := range allPeers {
+		r.Network.Installed[peerName] = []string{r.PackageID}
+	}
+}
+
+var allPeers = []string{"peer0.org1", "peer1.org1", "peer0.org2"}
+
+func actionsOfType(plan *Plan, actionType ActionType) []string {
+	var results []string
+	for _, action := range plan.Actions {
+		if action.Type == actionType {
+			results = append(results, action.Target)
+		}
+	}
+
+	return results
+}
+
+func TestNewPlan(t *testing.T) {
+	t.Run("Plans all actions for chaincode that is not committed", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+
+		plan, err := NewPlan(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+
+		require.Equal(t, "CHANNEL", plan.Channel)
+		require.Equal(t, "basic", plan.Chaincode)
+		require.Equal(t, reconciliation.PackageID, plan.PackageID)
+		require.EqualValues(t, 1, plan.Sequence)
+		require.False(t, plan.InSync())
+		require.Equal(t, allPeers, actionsOfType(plan, ActionInstall))
+		require.Equal(t, []string{"Org1MSP", "Org2MSP"}, actionsOfType(plan, ActionApprove))
+		require.Equal(t, []string{"CHANNEL"}, actionsOfType(plan, ActionCommit))
+		require.Equal(t, "commit CHANNEL: not committed", plan.Actions[len(plan.Actions)-1].String())
+	})
+
+	t.Run("Makes no changes", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+
+		_, err := NewPlan(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+
+		network := reconciliation.Network
+		require.Empty(t, network.Installed)
+		require.Empty(t, network.Approvals)
+		require.Empty(t, network.Committed)
+	})
+
+	t.Run("Committed definition with default policy and plugins is in sync", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+		reconciliation.Commit(t, 2)
+		reconciliation.Network.Committed["basic"].ValidationParameter = nil
+		reconciliation.Network.Committed["basic"].Collections = &peer.CollectionConfigPackage{}
+
+		plan, err := NewPlan(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+
+		require.True(t, plan.InSync(), "actions: %v", plan.Actions)
+		require.EqualValues(t, 2, plan.Sequence)
+	})
+
+	t.Run("Plans install only on peers without package", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+		reconciliation.Commit(t, 1)
+		delete(reconciliation.Network.Installed, "peer1.org1")
+
+		plan, err := NewPlan(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"peer1.org1"}, actionsOfType(plan, ActionInstall))
+		require.Len(t, plan.Actions, 1)
+	})
+
+	t.Run("Changed package plans approval at committed sequence", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+		reconciliation.Commit(t, 2)
+		reconciliation.Network.ApproveArgs["Org2MSP"].Source = &lifecycle.ChaincodeSource{
+			Type: &lifecycle.ChaincodeSource_LocalPackage{
+				LocalPackage: &lifecycle.ChaincodeSource_Local{PackageId: "basic_0.9:HASH"},
+			},
+		}
+
+		plan, err := NewPlan(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+
+		require.False(t, plan.InSync())
+		require.EqualValues(t, 2, plan.Sequence)
+		require.Equal(t, []string{"Org2MSP"}, actionsOfType(plan, ActionApprove))
+		require.Empty(t, actionsOfType(plan, ActionCommit))
+		require.Equal(t, fmt.Sprintf("package %s not approved", reconciliation.PackageID), plan.Actions[0].Reason)
+	})
+
+	t.Run("Organization that did not approve committed sequence plans approval", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+		reconciliation.Commit(t, 2)
+		delete(reconciliation.Network.ApproveArgs, "Org2MSP")
+
+		plan, err := NewPlan(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+
+		require.EqualValues(t, 2, plan.Sequence)
+		require.Equal(t, []string{"Org2MSP"}, actionsOfType(plan, ActionApprove))
+		require.Empty(t, actionsOfType(plan, ActionCommit))
+	})
+
+	t.Run("Changed version plans next sequence", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+		reconciliation.Commit(t, 2)
+		reconciliation.Desired.Version = "2.0"
+
+		plan, err := NewPlan(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+
+		require.EqualValues(t, 3, plan.Sequence)
+		require.Equal(t, []string{"Org1MSP", "Org2MSP"}, actionsOfType(plan, ActionApprove))
+		require.Equal(t, "version 1.0 != 2.0", plan.Actions[len(plan.Actions)-1].Reason)
+	})
+
+	for name, testCase := range map[string]struct {
+		change   func(*Desired)
+		expected string
+	}{
+		"endorsement policy": {
+			change:   func(d *Desired) { d.SignaturePolicy = "OR('Org1MSP.peer','Org2MSP.peer')" },
+			expected: "endorsement policy changed",
+		},
+		"collections": {
+			change: func(d *Desired) {
+				d.Collections = []*Collection{{Name: "COLLECTION", Policy: "OR('Org1MSP.member')", RequiredPeerCount: 1}}
+			},
+			expected: "collections changed",
+		},
+		"init required": {
+			change:   func(d *Desired) { d.InitRequired = true },
+			expected: "init required changed",
+		},
+		"endorsement plugin": {
+			change:   func(d *Desired) { d.EndorsementPlugin = "CUSTOM_ESCC" },
+			expected: "endorsement plugin changed",
+		},
+		"validation plugin": {
+			change:   func(d *Desired) { d.ValidationPlugin = "CUSTOM_VSCC" },
+			expected: "validation plugin changed",
+		},
+	} {
+		t.Run("Changed "+name+" plans commit", func(t *testing.T) {
+			controller, ctx := gomock.WithContext(context.Background(), t)
+			defer controller.Finish()
+			reconciliation := NewTestReconciliation(t, controller)
+			reconciliation.Commit(t, 1)
+			testCase.change(reconciliation.Desired)
+
+			plan, err := NewPlan(ctx, reconciliation.Desired, reconciliation.Options...)
+			require.NoError(t, err)
+
+			require.EqualValues(t, 2, plan.Sequence)
+			require.Equal(t, []string{"CHANNEL"}, actionsOfType(plan, ActionCommit))
+			require.Equal(t, testCase.expected, plan.Actions[len(plan.Actions)-1].Reason)
+		})
+	}
+
+	t.Run("Organizations that have approved are not planned for approval", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+		reconciliation.Network.Approvals["Org1MSP"] = true
+
+		plan, err := NewPlan(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"Org2MSP"}, actionsOfType(plan, ActionApprove))
+	})
+
+	for name, testCase := range map[string]struct {
+		options  func(controller *gomock.Controller, network *FakeNetwork) []Option
+		change   func(*Desired)
+		expected string
+	}{
+		"no organizations": {
+			options:  func(*gomock.Controller, *FakeNetwork) []Option { return nil },
+			expected: "organizations",
+		},
+		"organization without peers": {
+			options: func(controller *gomock.Controller, _ *FakeNetwork) []Option {
+				return []Option{WithOrganization(&deploy.Organization{SigningID: NewSigningIdentity(controller, "Org1MSP")})}
+			},
+			expected: "Org1MSP",
+		},
+		"no channel": {
+			options: func(controller *gomock.Controller, network *FakeNetwork) []Option {
+				return []Option{WithOrganization(&deploy.Organization{
+					SigningID: NewSigningIdentity(controller, "Org1MSP"),
+					Peers:     []*deploy.Peer{network.Peer("peer0")},
+				})}
+			},
+			change:   func(d *Desired) { d.Channel = "" },
+			expected: "channel",
+		},
+	} {
+		t.Run("Invalid options gives error: "+name, func(t *testing.T) {
+			controller, ctx := gomock.WithContext(context.Background(), t)
+			defer controller.Finish()
+			network := NewFakeNetwork()
+			desired := NewDesired(t, NewChaincodePackage(t, "basic_1.0"))
+			if testCase.change != nil {
+				testCase.change(desired)
+			}
+
+			_, err := NewPlan(ctx, desired, testCase.options(controller, network)...)
+			require.ErrorContains(t, err, testCase.expected)
+			require.Empty(t, network.Calls)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Run("Deploys chaincode that is not committed", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+
+		plan, err := Apply(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+		require.Len(t, plan.Actions, 6)
+
+		network := reconciliation.Network
+		for _, peerName := range allPeers {
+			require.Equal(t, []string{reconciliation.PackageID}, network.Installed[peerName], "installed on %s", peerName)
+		}
+		require.EqualValues(t, 1, network.Committed["basic"].GetSequence())
+		require.Equal(t, "1.0", network.Committed["basic"].GetVersion())
+
+		plan, err = NewPlan(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+		require.True(t, plan.InSync(), "actions: %v", plan.Actions)
+	})
+
+	t.Run("Upgrades changed chaincode definition", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+		reconciliation.Commit(t, 1)
+		reconciliation.Desired.Version = "2.0"
+		reconciliation.Desired.SignaturePolicy = "OR('Org1MSP.peer','Org2MSP.peer')"
+
+		_, err := Apply(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+
+		network := reconciliation.Network
+		require.EqualValues(t, 2, network.ApproveArgs["Org1MSP"].GetSequence())
+		require.Equal(t, reconciliation.PackageID, network.ApproveArgs["Org2MSP"].GetSource().GetLocalPackage().GetPackageId())
+		require.EqualValues(t, 2, network.Committed["basic"].GetSequence())
+		require.Equal(t, "2.0", network.Committed["basic"].GetVersion())
+		require.Equal(t, 0, network.CallCount("peer0.org1", "InstallChaincode"))
+	})
+
+	t.Run("Installs package without changing committed definition", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+		reconciliation.Commit(t, 1)
+		delete(reconciliation.Network.Installed, "peer0.org2")
+
+		_, err := Apply(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+
+		network := reconciliation.Network
+		require.Equal(t, []string{reconciliation.PackageID}, network.Installed["peer0.org2"])
+		require.Equal(t, 1, network.CallCount("peer0.org2", "InstallChaincode"))
+		require.Equal(t, 0, network.CallCount("peer0.org1", "InstallChaincode"))
+		require.Equal(t, 0, network.CallCount("peer0.org1", "ApproveChaincodeDefinitionForMyOrg"))
+		require.Equal(t, 0, network.CallCount("peer0.org2", "ApproveChaincodeDefinitionForMyOrg"))
+		require.EqualValues(t, 1, network.Committed["basic"].GetSequence())
+	})
+
+	t.Run("Approves changed package without committing", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+		reconciliation.Commit(t, 1)
+
+		desired := NewDesired(t, NewChaincodePackage(t, "basic_1.1"))
+		chaincodePackage, err := desired.ChaincodePackage()
+		require.NoError(t, err)
+		packageID, err := deploy.PackageID(chaincodePackage)
+		require.NoError(t, err)
+
+		plan, err := Apply(ctx, desired, reconciliation.Options...)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Org1MSP", "Org2MSP"}, actionsOfType(plan, ActionApprove))
+
+		network := reconciliation.Network
+		for _, peerName := range allPeers {
+			require.Contains(t, network.Installed[peerName], packageID, "installed on %s", peerName)
+		}
+		for _, mspID := range []string{"Org1MSP", "Org2MSP"} {
+			require.EqualValues(t, 1, network.ApproveArgs[mspID].GetSequence(), "sequence approved by %s", mspID)
+			require.Equal(t, packageID, network.ApproveArgs[mspID].GetSource().GetLocalPackage().GetPackageId(), "package approved by %s", mspID)
+		}
+		require.Equal(t, 0, network.CallCount("peer0.org1", "CommitChaincodeDefinition"))
+		require.EqualValues(t, 1, network.Committed["basic"].GetSequence())
+
+		plan, err = NewPlan(ctx, desired, reconciliation.Options...)
+		require.NoError(t, err)
+		require.True(t, plan.InSync(), "actions: %v", plan.Actions)
+	})
+
+	t.Run("In sync chaincode makes no changes", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		reconciliation := NewTestReconciliation(t, controller)
+		reconciliation.Commit(t, 1)
+
+		plan, err := Apply(ctx, reconciliation.Desired, reconciliation.Options...)
+		require.NoError(t, err)
+		require.True(t, plan.InSync())
+
+		for _, call := range reconciliation.Network.Calls {
+			require.NotContains(t, call, "InstallChaincode")
+			require.NotContains(t, call, "ApproveChaincodeDefinitionForMyOrg")
+			require.NotContains(t, call, "CommitChaincodeDefinition")
+		}
+	})
+}
@@ -0,0 +1,128 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package addorg
+
+import (
+	"context"
+	"errors"
+
+	admincommon "github.com/bestbeforetoday/fabric-admin/internal/common"
+	"github.com/bestbeforetoday/fabric-admin/pkg/channel/getconfig"
+	"github.com/bestbeforetoday/fabric-admin/pkg/channelconfig"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"google.golang.org/grpc"
+)
+
+// Prepare a config update envelope that adds a new organization to the application group of a channel. The current
+// channel config is retrieved from a peer, the organization is added with default Readers, Writers, Admins and
+// Endorsement policies, and the resulting config update is signed by the supplied identity. Signatures from other
+// organization admins can be added using channelconfig.SignUpdateEnvelope before the envelope is submitted to the
+// ordering service.
+func Prepare(ctx context.Context, signingID identity.SigningIdentity, options ...Option) (*common.Envelope, error) {
+	addOrgCommand := &command{
+		signingID: signingID,
+	}
+
+	if err := admincommon.ApplyOptions(addOrgCommand, options...); err != nil {
+		return nil, err
+	}
+
+	return addOrgCommand.run(ctx)
+}
+
+type command struct {
+	signingID       identity.SigningIdentity
+	grpcConnection  grpc.ClientConnInterface
+	grpcOptions     []grpc.CallOption
+	channelName     string
+	fabricMSPConfig *msp.FabricMSPConfig
+	tlsCertHash     []byte
+}
+
+func (c *command) run(ctx context.Context) (*common.Envelope, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	original, err := getconfig.Get(
+		ctx,
+		c.signingID,
+		getconfig.WithClientConnection(c.grpcConnection),
+		getconfig.WithChannel(c.channelName),
+		getconfig.WithCallOptions(c.grpcOptions...),
+		getconfig.WithTLSCertHash(c.tlsCertHash),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := channelconfig.AddApplicationOrg(original, c.fabricMSPConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return channelconfig.NewUpdateEnvelope(ctx, c.signingID, c.channelName, original, updated)
+}
+
+func (c *command) validate() error {
+	if c.grpcConnection == nil {
+		return errors.New("no gRPC client supplied")
+	}
+	if c.channelName == "" {
+		return errors.New("no channel name supplied")
+	}
+	if c.fabricMSPConfig == nil {
+		return errors.New("no organization MSP definition supplied")
+	}
+
+	return nil
+}
+
+type Option = func(*command) error
+
+// WithClientConnection uses the supplied gRPC client connection to a peer, which is used to retrieve the current
+// channel config. This should be shared by all commands connecting to the same network node.
+func WithClientConnection(clientConnection grpc.ClientConnInterface) Option {
+	return func(c *command) error {
+		c.grpcConnection = clientConnection
+		return nil
+	}
+}
+
+// WithChannel specifies the name of the channel to which the organization is added.
+func WithChannel(channelName string) Option {
+	return func(c *command) error {
+		c.channelName = channelName
+		return nil
+	}
+}
+
+// WithOrganization specifies the MSP definition of the organization to be added.
+func WithOrganization(fabricMSPConfig *msp.FabricMSPConfig) Option {
+	return func(c *command) error {
+		c.fabricMSPConfig = fabricMSPConfig
+		return nil
+	}
+}
+
+// WithCallOptions specifies the gRPC call options to be used.
+func WithCallOptions(options ...grpc.CallOption) Option {
+	return func(c *command) error {
+		c.grpcOptions = append(c.grpcOptions, options...)
+		return nil
+	}
+}
+
+// WithTLSCertHash specifies the hash of the client TLS certificate used to connect to the peer from which the current
+// channel configuration is obtained.
+func WithTLSCertHash(hash []byte) Option {
+	return func(c *command) error {
+		c.tlsCertHash = hash
+		return nil
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package addorg
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bestbeforetoday/fabric-admin/pkg/channelconfig"
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:generate mockgen -destination ./clientconn_mock_test.go -package ${GOPACKAGE} google.golang.org/grpc ClientConnInterface
+//go:generate mockgen -destination ./signingidentity_mock_test.go -package ${GOPACKAGE} github.com/bestbeforetoday/fabric-admin/pkg/identity SigningIdentity
+
+const processProposalMethod = "/protos.Endorser/ProcessProposal"
+
+func NewSigningIdentity(controller *gomock.Controller, signature []byte) *MockSigningIdentity {
+	mockIdentity := NewMockSigningIdentity(controller)
+	mockIdentity.EXPECT().MspID().AnyTimes()
+	mockIdentity.EXPECT().Credentials().AnyTimes()
+	mockIdentity.EXPECT().Sign(gomock.Any()).Return(signature, nil).AnyTimes()
+
+	return mockIdentity
+}
+
+func NewClientConnection(controller *gomock.Controller, response *peer.ProposalResponse) *MockClientConnInterface {
+	mockConnection := NewMockClientConnInterface(controller)
+	mockConnection.EXPECT().
+		Invoke(gomock.Any(), gomock.Eq(processProposalMethod), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ interface{}, out interface{}, _ ...grpc.CallOption) error {
+			proto.Merge(out.(*peer.ProposalResponse), response)
+			return nil
+		}).
+		AnyTimes()
+
+	return mockConnection
+}
+
+func NewConfigResponse(t *testing.T, channelConfig *common.Config) *peer.ProposalResponse {
+	return &peer.ProposalResponse{
+		Response: &peer.Response{
+			Status:  int32(common.Status_SUCCESS),
+			Payload: AssertMarshal(t, channelConfig),
+		},
+	}
+}
+
+func NewChannelConfig() *common.Config {
+	return &common.Config{
+		ChannelGroup: &common.ConfigGroup{
+			Groups: map[string]*common.ConfigGroup{
+				channelconfig.ApplicationGroupKey: {
+					ModPolicy: channelconfig.AdminsPolicyKey,
+				},
+			},
+			ModPolicy: channelconfig.AdminsPolicyKey,
+		},
+	}
+}
+
+func AssertMarshal(t *testing.T, m protoreflect.ProtoMessage) []byte {
+	result, err := proto.Marshal(m)
+	require.NoError(t, err)
+	return result
+}
+
+// AssertUnmarshal ensures that a protobuf is umarshaled without error
+func AssertUnmarshal(t *testing.T, b []byte, m protoreflect.ProtoMessage) {
+	err := proto.Unmarshal(b, m)
+	require.NoError(t, err)
+}
+
+func TestPrepare(t *testing.T) {
+	channelName := "CHANNEL"
+	organization := &msp.FabricMSPConfig{
+		Name: "Org2MSP",
+	}
+
+	t.Run("Missing gRPC connection gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Prepare(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithChannel(channelName),
+			WithOrganization(organization),
+		)
+		require.ErrorContains(t, err, "gRPC")
+	})
+
+	t.Run("Missing channel name gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Prepare(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithClientConnection(NewMockClientConnInterface(controller)),
+			WithOrganization(organization),
+		)
+		require.ErrorContains(t, err, "channel")
+	})
+
+	t.Run("Missing organization gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Prepare(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithClientConnection(NewMockClientConnInterface(controller)),
+			WithChannel(channelName),
+		)
+		require.ErrorContains(t, err, "organization")
+	})
+
+	t.Run("Config retrieval errors returned", func(t *testing.T) {
+		expectedErr := errors.New("EXPECTED_ERROR")
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockConnection := NewMockClientConnInterface(controller)
+		mockConnection.EXPECT().
+			Invoke(gomock.Eq(ctx), gomock.Eq(processProposalMethod), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(expectedErr)
+
+		_, err := Prepare(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithClientConnection(mockConnection),
+			WithChannel(channelName),
+			WithOrganization(organization),
+		)
+		require.ErrorIs(t, err, expectedErr)
+	})
+
+	t.Run("Existing organization gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		channelConfig, err := channelconfig.AddApplicationOrg(NewChannelConfig(), organization)
+		require.NoError(t, err)
+
+		_, err = Prepare(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithClientConnection(NewClientConnection(controller, NewConfigResponse(t, channelConfig))),
+			WithChannel(channelName),
+			WithOrganization(organization),
+		)
+		require.ErrorContains(t, err, organization.GetName())
+	})
+
+	t.Run("Returns signed config update adding organization", func(t *testing.T) {
+		expectedSignature := []byte("SIGNATURE")
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		actual, err := Prepare(
+			ctx,
+			NewSigningIdentity(controller, expectedSignature),
+			WithClientConnection(NewClientConnection(controller, NewConfigResponse(t, NewChannelConfig()))),
+			WithChannel(channelName),
+			WithOrganization(organization),
+		)
+		require.NoError(t, err)
+
+		require.EqualValues(t, expectedSignature, actual.GetSignature(), "envelope signature")
+
+		payload := &common.Payload{}
+		AssertUnmarshal(t, actual.GetPayload(), payload)
+
+		configUpdateEnvelope := &common.ConfigUpdateEnvelope{}
+		AssertUnmarshal(t, payload.GetData(), configUpdateEnvelope)
+		require.Len(t, configUpdateEnvelope.GetSignatures(), 1, "config signatures")
+
+		configUpdate := &common.ConfigUpdate{}
+		AssertUnmarshal(t, configUpdateEnvelope.GetConfigUpdate(), configUpdate)
+		require.Equal(t, channelName, configUpdate.GetChannelId())
+
+		writeApplication := configUpdate.GetWriteSet().GetGroups()[channelconfig.ApplicationGroupKey]
+		require.Contains(t, writeApplication.GetGroups(), organization.GetName())
+	})
+
+	t.Run("Config retrieved with supplied gRPC call options", func(t *testing.T) {
+		callOption := grpc.WaitForReady(true)
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		response := NewConfigResponse(t, NewChannelConfig())
+		mockConnection := NewMockClientConnInterface(controller)
+		mockConnection.EXPECT().
+			Invoke(gomock.Eq(ctx), gomock.Eq(processProposalMethod), gomock.Any(), gomock.Any(), gomock.InAnyOrder([]grpc.CallOption{callOption})).
+			DoAndReturn(func(_ context.Context, _ string, _ interface{}, out interface{}, _ ...grpc.CallOption) error {
+				proto.Merge(out.(*peer.ProposalResponse), response)
+				return nil
+			})
+
+		_, err := Prepare(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithClientConnection(mockConnection),
+			WithChannel(channelName),
+			WithOrganization(organization),
+			WithCallOptions(callOption),
+		)
+		require.NoError(t, err)
+	})
+}
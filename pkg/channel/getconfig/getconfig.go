@@ -0,0 +1,173 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package getconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	admincommon "github.com/bestbeforetoday/fabric-admin/internal/common"
+	"github.com/bestbeforetoday/fabric-admin/internal/proposal"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const getChannelConfigTransactionName = "GetChannelConfig"
+
+// Get the current configuration of a channel from a peer.
+func Get(ctx context.Context, signingID identity.SigningIdentity, options ...Option) (*common.Config, error) {
+	getConfigCommand := &command{}
+
+	if err := admincommon.ApplyOptions(getConfigCommand, options...); err != nil {
+		return nil, err
+	}
+
+	return getConfigCommand.run(ctx, signingID)
+}
+
+// NewProposal creates an unsigned proposal to get the configuration of a channel for the supplied identity. The
+// proposal can be signed separately, such as on an offline machine, and then submitted using Submit.
+func NewProposal(id gatewayid.Identity, options ...Option) (*peer.Proposal, error) {
+	getConfigCommand := &command{}
+
+	if err := admincommon.ApplyOptions(getConfigCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := getConfigCommand.validateProposal(); err != nil {
+		return nil, err
+	}
+
+	return getConfigCommand.newProposal(id)
+}
+
+// Submit a signed proposal to get the configuration of a channel, created using NewProposal, to a peer.
+func Submit(ctx context.Context, signedProposal *peer.SignedProposal, options ...Option) (*common.Config, error) {
+	getConfigCommand := &command{}
+
+	if err := admincommon.ApplyOptions(getConfigCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := getConfigCommand.validateSubmit(); err != nil {
+		return nil, err
+	}
+
+	return getConfigCommand.submit(ctx, signedProposal)
+}
+
+type command struct {
+	grpcClient  peer.EndorserClient
+	grpcOptions []grpc.CallOption
+	channelName string
+	tlsCertHash []byte
+}
+
+func (c *command) run(ctx context.Context, signingID identity.SigningIdentity) (*common.Config, error) {
+	if err := c.validateSubmit(); err != nil {
+		return nil, err
+	}
+	if err := c.validateProposal(); err != nil {
+		return nil, err
+	}
+
+	unsignedProposal, err := c.newProposal(signingID)
+	if err != nil {
+		return nil, err
+	}
+
+	signedProposal, err := proposal.Sign(ctx, signingID, unsignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.submit(ctx, signedProposal)
+}
+
+func (c *command) validateProposal() error {
+	if c.channelName == "" {
+		return errors.New("no channel name supplied")
+	}
+
+	return nil
+}
+
+func (c *command) validateSubmit() error {
+	if c.grpcClient == nil {
+		return errors.New("no gRPC client supplied")
+	}
+
+	return nil
+}
+
+func (c *command) newProposal(id gatewayid.Identity) (*peer.Proposal, error) {
+	return proposal.New(
+		id,
+		admincommon.ConfigurationChaincodeName,
+		getChannelConfigTransactionName,
+		proposal.WithArguments(c.channelName),
+		proposal.WithTLSCertHash(c.tlsCertHash),
+	)
+}
+
+func (c *command) submit(ctx context.Context, signedProposal *peer.SignedProposal) (*common.Config, error) {
+	proposalResponse, err := c.grpcClient.ProcessProposal(ctx, signedProposal, c.grpcOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = proposal.CheckSuccessfulResponse(proposalResponse); err != nil {
+		return nil, err
+	}
+
+	result := &common.Config{}
+	if err = proto.Unmarshal(proposalResponse.GetResponse().GetPayload(), result); err != nil {
+		return nil, fmt.Errorf("failed to deserialize channel config: %w", err)
+	}
+
+	return result, nil
+}
+
+type Option = func(*command) error
+
+// WithClientConnection uses the supplied gRPC client connection. This should be shared by all commands
+// connecting to the same network node.
+func WithClientConnection(clientConnection grpc.ClientConnInterface) Option {
+	return func(c *command) error {
+		c.grpcClient = peer.NewEndorserClient(clientConnection)
+		return nil
+	}
+}
+
+// WithChannel specifies the name of the channel whose configuration is retrieved.
+func WithChannel(channelName string) Option {
+	return func(c *command) error {
+		c.channelName = channelName
+		return nil
+	}
+}
+
+// WithCallOptions specifies the gRPC call options to be used.
+func WithCallOptions(options ...grpc.CallOption) Option {
+	return func(c *command) error {
+		c.grpcOptions = append(c.grpcOptions, options...)
+		return nil
+	}
+}
+
+// WithTLSCertHash specifies the hash of the client TLS certificate used to connect to the peer. This is required if the
+// peer checks that the get config proposal is bound to the mutual TLS connection on which it is received.
+func WithTLSCertHash(hash []byte) Option {
+	return func(c *command) error {
+		c.tlsCertHash = hash
+		return nil
+	}
+}
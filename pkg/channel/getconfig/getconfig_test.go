@@ -0,0 +1,405 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package getconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	adminproposal "github.com/bestbeforetoday/fabric-admin/pkg/proposal"
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:generate mockgen -destination ./endorser_mock_test.go -package ${GOPACKAGE} github.com/hyperledger/fabric-protos-go-apiv2/peer EndorserClient
+//go:generate mockgen -destination ./signingidentity_mock_test.go -package ${GOPACKAGE} github.com/bestbeforetoday/fabric-admin/pkg/identity SigningIdentity
+
+func WithEndorserClient(grpcClient peer.EndorserClient) Option {
+	return func(b *command) error {
+		b.grpcClient = grpcClient
+		return nil
+	}
+}
+
+func NewSigningIdentity(controller *gomock.Controller, signature []byte) *MockSigningIdentity {
+	mockIdentity := NewMockSigningIdentity(controller)
+	mockIdentity.EXPECT().MspID().AnyTimes()
+	mockIdentity.EXPECT().Credentials().AnyTimes()
+	mockIdentity.EXPECT().Sign(gomock.Any()).Return(signature, nil).AnyTimes()
+
+	return mockIdentity
+}
+
+func NewProposalResponse(status common.Status, message string) *peer.ProposalResponse {
+	return &peer.ProposalResponse{
+		Response: &peer.Response{
+			Status:  int32(status),
+			Message: message,
+		},
+	}
+}
+
+func AssertMarshal(t *testing.T, m protoreflect.ProtoMessage) []byte {
+	result, err := proto.Marshal(m)
+	require.NoError(t, err)
+	return result
+}
+
+// AssertUnmarshal ensures that a protobuf is umarshaled without error
+func AssertUnmarshal(t *testing.T, b []byte, m protoreflect.ProtoMessage) {
+	err := proto.Unmarshal(b, m)
+	require.NoError(t, err)
+}
+
+// AssertUnmarshalInvocationSpec ensures that a ChaincodeInvocationSpec protobuf is umarshalled without error
+func AssertUnmarshalInvocationSpec(t *testing.T, signedProposal *peer.SignedProposal) *peer.ChaincodeInvocationSpec {
+	proposal := &peer.Proposal{}
+	AssertUnmarshal(t, signedProposal.ProposalBytes, proposal)
+
+	payload := &peer.ChaincodeProposalPayload{}
+	AssertUnmarshal(t, proposal.Payload, payload)
+
+	input := &peer.ChaincodeInvocationSpec{}
+	AssertUnmarshal(t, payload.Input, input)
+
+	return input
+}
+
+// AssertProtoEqual ensures an expected protobuf message matches an actual message
+func AssertProtoEqual(t *testing.T, expected protoreflect.ProtoMessage, actual protoreflect.ProtoMessage) {
+	require.True(t, proto.Equal(expected, actual), "Expected %v, got %v", expected, actual)
+}
+
+func TestGet(t *testing.T) {
+	channelName := "CHANNEL"
+
+	t.Run("Missing gRPC connection gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Get(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithChannel(channelName),
+		)
+		require.ErrorContains(t, err, "gRPC")
+	})
+
+	t.Run("Missing channel name gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(0)
+
+		_, err := Get(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+		)
+		require.ErrorContains(t, err, "channel")
+	})
+
+	t.Run("Endorser client called with supplied context", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Return(NewProposalResponse(common.Status_SUCCESS, ""), nil)
+
+		_, err := Get(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+			WithChannel(channelName),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("Endorser client errors returned", func(t *testing.T) {
+		expectedErr := errors.New("EXPECTED_ERROR")
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Return(nil, expectedErr)
+
+		_, err := Get(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+			WithChannel(channelName),
+		)
+		require.EqualError(t, err, expectedErr.Error())
+	})
+
+	t.Run("Unsuccessful proposal response gives error", func(t *testing.T) {
+		expectedStatus := common.Status_BAD_REQUEST
+		expectedMessage := "EXPECTED_ERROR"
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Return(NewProposalResponse(expectedStatus, expectedMessage), nil)
+
+		_, err := Get(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+			WithChannel(channelName),
+		)
+
+		require.ErrorContainsf(t, err, fmt.Sprintf("%d", expectedStatus), "status code")
+		require.ErrorContains(t, err, expectedStatus.String(), "status name")
+		require.ErrorContains(t, err, expectedMessage, "message")
+	})
+
+	t.Run("Channel config returned on successful proposal response", func(t *testing.T) {
+		expected := &common.Config{
+			Sequence: 1,
+			ChannelGroup: &common.ConfigGroup{
+				ModPolicy: "Admins",
+			},
+		}
+		response := NewProposalResponse(common.Status_SUCCESS, "")
+		response.Response.Payload = AssertMarshal(t, expected)
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Return(response, nil)
+
+		actual, err := Get(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+			WithChannel(channelName),
+		)
+		require.NoError(t, err)
+
+		AssertProtoEqual(t, expected, actual)
+	})
+
+	t.Run("Proposal includes channel name argument", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		var signedProposal *peer.SignedProposal
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, in *peer.SignedProposal, _ ...grpc.CallOption) {
+				signedProposal = in
+			}).
+			Return(NewProposalResponse(common.Status_SUCCESS, ""), nil).
+			Times(1)
+
+		_, err := Get(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+			WithChannel(channelName),
+		)
+		require.NoError(t, err)
+
+		invocationSpec := AssertUnmarshalInvocationSpec(t, signedProposal)
+		require.Equal(t, "cscc", invocationSpec.GetChaincodeSpec().GetChaincodeId().GetName(), "chaincode name")
+
+		args := invocationSpec.GetChaincodeSpec().GetInput().GetArgs()
+		require.Len(t, args, 2, "number of arguments")
+		require.EqualValues(t, getChannelConfigTransactionName, args[0], "transaction name")
+		require.EqualValues(t, channelName, args[1], "channel name")
+	})
+
+	t.Run("Proposal channel header includes TLS certificate hash", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		var signedProposal *peer.SignedProposal
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, in *peer.SignedProposal, _ ...grpc.CallOption) {
+				signedProposal = in
+			}).
+			Return(NewProposalResponse(common.Status_SUCCESS, ""), nil).
+			Times(1)
+
+		_, err := Get(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+			WithChannel(channelName),
+			WithTLSCertHash([]byte("TLS_CERT_HASH")),
+		)
+		require.NoError(t, err)
+
+		proposal := &peer.Proposal{}
+		AssertUnmarshal(t, signedProposal.GetProposalBytes(), proposal)
+		header := &common.Header{}
+		AssertUnmarshal(t, proposal.GetHeader(), header)
+		channelHeader := &common.ChannelHeader{}
+		AssertUnmarshal(t, header.GetChannelHeader(), channelHeader)
+
+		require.Equal(t, []byte("TLS_CERT_HASH"), channelHeader.GetTlsCertHash())
+	})
+
+	t.Run("Uses signer", func(t *testing.T) {
+		expected := []byte("SIGNATURE")
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		var signedProposal *peer.SignedProposal
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, in *peer.SignedProposal, _ ...grpc.CallOption) {
+				signedProposal = in
+			}).
+			Return(NewProposalResponse(common.Status_SUCCESS, ""), nil).
+			Times(1)
+
+		_, err := Get(
+			ctx,
+			NewSigningIdentity(controller, expected),
+			WithEndorserClient(mockEndorser),
+			WithChannel(channelName),
+		)
+		require.NoError(t, err)
+
+		actual := signedProposal.GetSignature()
+		require.EqualValues(t, expected, actual)
+	})
+
+	t.Run("Endorser client called with supplied gRPC call options", func(t *testing.T) {
+		callOption := grpc.WaitForReady(true)
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(
+				gomock.Eq(ctx),
+				gomock.Any(),
+				gomock.InAnyOrder([]grpc.CallOption{
+					callOption,
+				}),
+			).
+			Return(NewProposalResponse(common.Status_SUCCESS, ""), nil)
+
+		_, err := Get(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithEndorserClient(mockEndorser),
+			WithChannel(channelName),
+			WithCallOptions(callOption),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("Signing abandoned when context done", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		release := make(chan struct{})
+		defer close(release)
+
+		mockSigner := NewMockSigningIdentity(controller)
+		mockSigner.EXPECT().MspID().AnyTimes()
+		mockSigner.EXPECT().Credentials().AnyTimes()
+		mockSigner.EXPECT().Sign(gomock.Any()).
+			DoAndReturn(func(message []byte) ([]byte, error) {
+				<-release
+				return nil, nil
+			}).
+			AnyTimes()
+
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(0)
+
+		_, err := Get(
+			ctx,
+			mockSigner,
+			WithEndorserClient(mockEndorser),
+			WithChannel(channelName),
+		)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestOfflineSigning(t *testing.T) {
+	t.Run("Submits externally signed proposal", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		unsignedProposal, err := NewProposal(
+			NewSigningIdentity(controller, nil),
+			WithChannel("CHANNEL"),
+		)
+		require.NoError(t, err)
+
+		proposalBytes, err := adminproposal.Bytes(unsignedProposal)
+		require.NoError(t, err)
+
+		signedProposal, err := adminproposal.NewSignedProposal(unsignedProposal, []byte("OFFLINE_SIGNATURE"))
+		require.NoError(t, err)
+
+		var actual *peer.SignedProposal
+		mockEndorser := NewMockEndorserClient(controller)
+		mockEndorser.EXPECT().
+			ProcessProposal(gomock.Eq(ctx), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, in *peer.SignedProposal, _ ...grpc.CallOption) {
+				actual = in
+			}).
+			Return(NewProposalResponse(common.Status_SUCCESS, ""), nil)
+
+		_, err = Submit(
+			ctx,
+			signedProposal,
+			WithEndorserClient(mockEndorser),
+		)
+		require.NoError(t, err)
+
+		require.Equal(t, proposalBytes, actual.GetProposalBytes())
+		require.Equal(t, []byte("OFFLINE_SIGNATURE"), actual.GetSignature())
+	})
+
+	t.Run("Submit without gRPC client gives error", func(t *testing.T) {
+		_, err := Submit(
+			context.Background(),
+			&peer.SignedProposal{},
+		)
+		require.ErrorContains(t, err, "gRPC")
+	})
+}
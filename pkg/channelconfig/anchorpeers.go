@@ -0,0 +1,90 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// SetAnchorPeers returns a copy of the channel config with the anchor peers for the application organization with the
+// specified MSP ID replaced by the supplied host:port addresses. The supplied channel config is not modified.
+func SetAnchorPeers(channelConfig *common.Config, mspID string, anchorPeers []string) (*common.Config, error) {
+	anchorPeersValue, err := newAnchorPeers(anchorPeers)
+	if err != nil {
+		return nil, err
+	}
+
+	result := proto.Clone(channelConfig).(*common.Config)
+
+	orgGroup, err := applicationOrgGroup(result, mspID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setValue(orgGroup, AnchorPeersKey, anchorPeersValue, AdminsPolicyKey); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateAnchorPeers creates a config update envelope, signed by the supplied identity, that sets the anchor peers for
+// the application organization with the specified MSP ID to the supplied host:port addresses.
+func UpdateAnchorPeers(
+	ctx context.Context,
+	signingID identity.SigningIdentity,
+	channelName string,
+	channelConfig *common.Config,
+	mspID string,
+	anchorPeers []string,
+) (*common.Envelope, error) {
+	updated, err := SetAnchorPeers(channelConfig, mspID, anchorPeers)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUpdateEnvelope(ctx, signingID, channelName, channelConfig, updated)
+}
+
+func newAnchorPeers(addresses []string) (*peer.AnchorPeers, error) {
+	result := &peer.AnchorPeers{}
+
+	for _, address := range addresses {
+		host, port, err := splitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		result.AnchorPeers = append(result.AnchorPeers, &peer.AnchorPeer{
+			Host: host,
+			Port: port,
+		})
+	}
+
+	return result, nil
+}
+
+func splitHostPort(address string) (string, int32, error) {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in address %s: %w", address, err)
+	}
+
+	return host, int32(port), nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAnchorPeers(t *testing.T) {
+	t.Run("Unknown MSP ID gives error", func(t *testing.T) {
+		_, err := SetAnchorPeers(NewChannelConfig(t, "Org1MSP"), "UnknownMSP", []string{"peer0:7051"})
+		require.ErrorContains(t, err, "UnknownMSP")
+	})
+
+	t.Run("Missing application group gives error", func(t *testing.T) {
+		channelConfig := &common.Config{
+			ChannelGroup: &common.ConfigGroup{},
+		}
+
+		_, err := SetAnchorPeers(channelConfig, "Org1MSP", []string{"peer0:7051"})
+		require.ErrorContains(t, err, ApplicationGroupKey)
+	})
+
+	for _, address := range []string{"peer0", "peer0:PORT", "peer0:99999"} {
+		t.Run("Invalid address gives error: "+address, func(t *testing.T) {
+			_, err := SetAnchorPeers(NewChannelConfig(t, "Org1MSP"), "Org1MSP", []string{address})
+			require.ErrorContains(t, err, address)
+		})
+	}
+
+	t.Run("Sets anchor peers for organization", func(t *testing.T) {
+		actual, err := SetAnchorPeers(NewChannelConfig(t, "Org1MSP", "Org2MSP"), "Org1MSP", []string{"peer0:7051", "peer1.org1:8051"})
+		require.NoError(t, err)
+
+		orgGroup := actual.GetChannelGroup().GetGroups()[ApplicationGroupKey].GetGroups()["Org1MSP"]
+		configValue := orgGroup.GetValues()[AnchorPeersKey]
+		require.Equal(t, AdminsPolicyKey, configValue.GetModPolicy())
+
+		anchorPeers := &peer.AnchorPeers{}
+		AssertUnmarshal(t, configValue.GetValue(), anchorPeers)
+
+		expected := &peer.AnchorPeers{
+			AnchorPeers: []*peer.AnchorPeer{
+				{Host: "peer0", Port: 7051},
+				{Host: "peer1.org1", Port: 8051},
+			},
+		}
+		AssertProtoEqual(t, expected, anchorPeers)
+
+		require.NotContains(t, actual.GetChannelGroup().GetGroups()[ApplicationGroupKey].GetGroups()["Org2MSP"].GetValues(), AnchorPeersKey)
+	})
+
+	t.Run("Supplied config is not modified", func(t *testing.T) {
+		channelConfig := NewChannelConfig(t, "Org1MSP")
+
+		_, err := SetAnchorPeers(channelConfig, "Org1MSP", []string{"peer0:7051"})
+		require.NoError(t, err)
+
+		AssertProtoEqual(t, NewChannelConfig(t, "Org1MSP"), channelConfig)
+	})
+}
+
+func TestUpdateAnchorPeers(t *testing.T) {
+	t.Run("Creates signed config update envelope", func(t *testing.T) {
+		expectedSignature := []byte("SIGNATURE")
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		actual, err := UpdateAnchorPeers(
+			ctx,
+			NewSigningIdentity(controller, expectedSignature),
+			"CHANNEL",
+			NewChannelConfig(t, "Org1MSP"),
+			"Org1MSP",
+			[]string{"peer0:7051"},
+		)
+		require.NoError(t, err)
+
+		require.EqualValues(t, expectedSignature, actual.GetSignature(), "envelope signature")
+
+		channelHeader, configUpdateEnvelope, configUpdate := AssertUnmarshalConfigUpdate(t, actual)
+		require.EqualValues(t, common.HeaderType_CONFIG_UPDATE, channelHeader.GetType(), "header type")
+		require.Equal(t, "CHANNEL", channelHeader.GetChannelId(), "header channel")
+		require.Equal(t, "CHANNEL", configUpdate.GetChannelId(), "config update channel")
+
+		require.Len(t, configUpdateEnvelope.GetSignatures(), 1)
+		require.EqualValues(t, expectedSignature, configUpdateEnvelope.GetSignatures()[0].GetSignature(), "config signature")
+
+		writeOrg := configUpdate.GetWriteSet().GetGroups()[ApplicationGroupKey].GetGroups()["Org1MSP"]
+		require.Contains(t, writeOrg.GetValues(), AnchorPeersKey)
+		require.EqualValues(t, 1, writeOrg.GetVersion(), "org group version")
+	})
+}
@@ -0,0 +1,264 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"google.golang.org/protobuf/proto"
+)
+
+// BFTConsensusType is the consensus type name used by the SmartBFT ordering service.
+const BFTConsensusType = "BFT"
+
+// OrderersKey is the name of the orderer group value containing the BFT consenter mapping.
+const OrderersKey = "Orderers"
+
+// BFTConsenter is a member of the BFT consenter set, as defined by the common.Consenter protobuf message.
+type BFTConsenter struct {
+	ID            uint32
+	Host          string
+	Port          uint32
+	MspID         string
+	Identity      []byte // PEM encoded signing certificate.
+	ClientTLSCert []byte // PEM encoded client TLS certificate.
+	ServerTLSCert []byte // PEM encoded server TLS certificate.
+	unknownFields []byte
+}
+
+// BFTLeaderRotation specifies whether leader rotation is enabled for BFT consensus.
+type BFTLeaderRotation int32
+
+const (
+	BFTLeaderRotationUnspecified BFTLeaderRotation = 0
+	BFTLeaderRotationOff         BFTLeaderRotation = 1
+	BFTLeaderRotationOn          BFTLeaderRotation = 2
+)
+
+// BFTOptions are the BFT consensus options, as defined by the orderer.smartbft.Options protobuf message, that are
+// stored as the consensus type metadata. Durations are expressed as strings parsable by time.ParseDuration.
+type BFTOptions struct {
+	RequestBatchMaxCount      uint64
+	RequestBatchMaxBytes      uint64
+	RequestBatchMaxInterval   string
+	IncomingMessageBufferSize uint64
+	RequestPoolSize           uint64
+	RequestForwardTimeout     string
+	RequestComplainTimeout    string
+	RequestAutoRemoveTimeout  string
+	ViewChangeResendInterval  string
+	ViewChangeTimeout         string
+	LeaderHeartbeatTimeout    string
+	LeaderHeartbeatCount      uint64
+	CollectTimeout            string
+	SyncOnStart               bool
+	SpeedUpViewChange         bool
+	LeaderRotation            BFTLeaderRotation
+	DecisionsPerLeader        uint64
+	RequestMaxBytes           uint64
+	RequestPoolSubmitTimeout  string
+	unknownFields             []byte
+}
+
+// BFTConsenters returns the BFT consenter mapping defined in the orderer group of the channel config.
+func BFTConsenters(channelConfig *common.Config) ([]*BFTConsenter, error) {
+	ordererGroup, err := childGroup(channelConfig, OrdererGroupKey)
+	if err != nil {
+		return nil, err
+	}
+
+	configValue := ordererGroup.GetValues()[OrderersKey]
+	if configValue == nil {
+		return nil, nil
+	}
+
+	return unmarshalOrderers(configValue.GetValue())
+}
+
+// SetBFTConsenters returns a copy of the channel config with the BFT consenter mapping replaced by the supplied
+// consenters. The consenters are validated against the existing consenter mapping using ValidateBFTConsenters. The
+// supplied channel config is not modified.
+func SetBFTConsenters(channelConfig *common.Config, consenters []*BFTConsenter) (*common.Config, error) {
+	existing, err := BFTConsenters(channelConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateBFTConsenters(existing, consenters); err != nil {
+		return nil, err
+	}
+
+	result := proto.Clone(channelConfig).(*common.Config)
+
+	ordererGroup, err := childGroup(result, OrdererGroupKey)
+	if err != nil {
+		return nil, err
+	}
+
+	setRawValue(ordererGroup, OrderersKey, marshalOrderers(consenters), AdminsPolicyKey)
+
+	return result, nil
+}
+
+// AddBFTConsenter returns a copy of the channel config with the supplied consenter added to the BFT consenter mapping.
+// The supplied channel config is not modified.
+func AddBFTConsenter(channelConfig *common.Config, consenter *BFTConsenter) (*common.Config, error) {
+	consenters, err := BFTConsenters(channelConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	consenters = append(consenters, consenter)
+	return SetBFTConsenters(channelConfig, consenters)
+}
+
+// RemoveBFTConsenter returns a copy of the channel config with the consenter with the specified ID removed from the
+// BFT consenter mapping. The supplied channel config is not modified.
+func RemoveBFTConsenter(channelConfig *common.Config, id uint32) (*common.Config, error) {
+	consenters, err := BFTConsenters(channelConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	index := bftConsenterIndex(consenters, id)
+	if index < 0 {
+		return nil, fmt.Errorf("consenter not found with ID: %d", id)
+	}
+
+	consenters = append(consenters[:index], consenters[index+1:]...)
+	return SetBFTConsenters(channelConfig, consenters)
+}
+
+// UpdateBFTConsenter returns a copy of the channel config with the consenter that has the same ID as the supplied
+// consenter replaced. This is used to rotate the identity or TLS certificates of a consenter. The supplied channel
+// config is not modified.
+func UpdateBFTConsenter(channelConfig *common.Config, consenter *BFTConsenter) (*common.Config, error) {
+	consenters, err := BFTConsenters(channelConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	index := bftConsenterIndex(consenters, consenter.ID)
+	if index < 0 {
+		return nil, fmt.Errorf("consenter not found with ID: %d", consenter.ID)
+	}
+
+	consenters[index] = consenter
+	return SetBFTConsenters(channelConfig, consenters)
+}
+
+// ValidateBFTConsenters checks that an updated BFT consenter set is valid, and that it can be safely applied to a
+// channel currently using the original consenter set. Consenter IDs and endpoints must be unique, and consenters that
+// are present in both the original and updated sets must be sufficient to form a quorum of the updated set, so that
+// consensus can continue before any newly added consenters are running. If the original set is empty, only the
+// updated set is validated.
+func ValidateBFTConsenters(original []*BFTConsenter, updated []*BFTConsenter) error {
+	if len(updated) == 0 {
+		return errors.New("no consenters supplied")
+	}
+
+	ids := make(map[uint32]bool, len(updated))
+	endpoints := make(map[string]bool, len(updated))
+	for _, consenter := range updated {
+		if ids[consenter.ID] {
+			return fmt.Errorf("duplicate consenter ID: %d", consenter.ID)
+		}
+		ids[consenter.ID] = true
+
+		endpoint := fmt.Sprintf("%s:%d", consenter.Host, consenter.Port)
+		if endpoints[endpoint] {
+			return fmt.Errorf("duplicate consenter endpoint: %s", endpoint)
+		}
+		endpoints[endpoint] = true
+
+		if consenter.MspID == "" {
+			return fmt.Errorf("no MSP ID for consenter ID: %d", consenter.ID)
+		}
+	}
+
+	if len(original) == 0 {
+		return nil
+	}
+
+	retained := 0
+	for _, consenter := range original {
+		if ids[consenter.ID] {
+			retained++
+		}
+	}
+
+	if quorum := BFTQuorum(len(updated)); retained < quorum {
+		return fmt.Errorf("only %d existing consenters remain, which is less than the quorum of %d required by %d consenters",
+			retained, quorum, len(updated))
+	}
+
+	return nil
+}
+
+// BFTQuorum returns the number of consenters required to form a quorum in a BFT consenter set of the specified size.
+func BFTQuorum(consenterCount int) int {
+	faultTolerance := (consenterCount - 1) / 3
+	return (consenterCount + faultTolerance + 2) / 2
+}
+
+// BFTOptionsFromConfig returns the BFT consensus options stored as consensus type metadata in the channel config.
+func BFTOptionsFromConfig(channelConfig *common.Config) (*BFTOptions, error) {
+	_, consensusType, err := bftConsensusType(channelConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalBFTOptions(consensusType.GetMetadata())
+}
+
+// SetBFTOptions returns a copy of the channel config with the BFT consensus options replaced by the supplied options.
+// The supplied channel config is not modified.
+func SetBFTOptions(channelConfig *common.Config, options *BFTOptions) (*common.Config, error) {
+	result := proto.Clone(channelConfig).(*common.Config)
+
+	ordererGroup, consensusType, err := bftConsensusType(result)
+	if err != nil {
+		return nil, err
+	}
+
+	consensusType.Metadata = marshalBFTOptions(options)
+	if err := setValue(ordererGroup, ConsensusTypeKey, consensusType, AdminsPolicyKey); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func bftConsensusType(channelConfig *common.Config) (*common.ConfigGroup, *orderer.ConsensusType, error) {
+	ordererGroup, err := childGroup(channelConfig, OrdererGroupKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	consensusType := &orderer.ConsensusType{}
+	if err := getValue(ordererGroup, ConsensusTypeKey, consensusType); err != nil {
+		return nil, nil, err
+	}
+
+	if consensusType.GetType() != BFTConsensusType {
+		return nil, nil, fmt.Errorf("consensus type is %s, not %s", consensusType.GetType(), BFTConsensusType)
+	}
+
+	return ordererGroup, consensusType, nil
+}
+
+func bftConsenterIndex(consenters []*BFTConsenter, id uint32) int {
+	for i, consenter := range consenters {
+		if consenter.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,258 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func NewBFTConsenter(id uint32) *BFTConsenter {
+	return &BFTConsenter{
+		ID:            id,
+		Host:          "orderer",
+		Port:          7050 + id,
+		MspID:         "OrdererMSP",
+		Identity:      []byte("IDENTITY"),
+		ClientTLSCert: []byte("CLIENT_CERT"),
+		ServerTLSCert: []byte("SERVER_CERT"),
+	}
+}
+
+func NewBFTChannelConfig(t *testing.T, options *BFTOptions, consenters ...*BFTConsenter) *common.Config {
+	consensusType := &orderer.ConsensusType{
+		Type:     BFTConsensusType,
+		Metadata: marshalBFTOptions(options),
+	}
+	channelConfig := NewOrdererChannelConfig(t, consensusType, "OrdererMSP")
+
+	ordererGroup := channelConfig.GetChannelGroup().GetGroups()[OrdererGroupKey]
+	setRawValue(ordererGroup, OrderersKey, marshalOrderers(consenters), AdminsPolicyKey)
+
+	return channelConfig
+}
+
+func TestBFTWireFormat(t *testing.T) {
+	t.Run("Consenter encoding matches protobuf field numbers", func(t *testing.T) {
+		consenter := &BFTConsenter{
+			ID:   1,
+			Host: "a",
+			Port: 2,
+		}
+
+		actual := marshalBFTConsenter(consenter)
+		require.Equal(t, []byte{0x08, 0x01, 0x12, 0x01, 'a', 0x18, 0x02}, actual)
+	})
+
+	t.Run("Consenters round trip", func(t *testing.T) {
+		expected := []*BFTConsenter{NewBFTConsenter(1), NewBFTConsenter(2)}
+
+		actual, err := unmarshalOrderers(marshalOrderers(expected))
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("Options round trip", func(t *testing.T) {
+		expected := &BFTOptions{
+			RequestBatchMaxCount:     100,
+			RequestBatchMaxInterval:  "50ms",
+			ViewChangeTimeout:        "20s",
+			SyncOnStart:              true,
+			LeaderRotation:           BFTLeaderRotationOn,
+			DecisionsPerLeader:       3,
+			RequestPoolSubmitTimeout: "5s",
+		}
+
+		actual, err := unmarshalBFTOptions(marshalBFTOptions(expected))
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("Unknown fields are preserved", func(t *testing.T) {
+		unknownField := protowire.AppendTag(nil, 99, protowire.BytesType)
+		unknownField = protowire.AppendString(unknownField, "UNKNOWN")
+
+		encoded := append(marshalBFTOptions(&BFTOptions{RequestPoolSize: 10}), unknownField...)
+
+		options, err := unmarshalBFTOptions(encoded)
+		require.NoError(t, err)
+		require.EqualValues(t, 10, options.RequestPoolSize)
+		require.Equal(t, encoded, marshalBFTOptions(options))
+	})
+
+	t.Run("Malformed message gives error", func(t *testing.T) {
+		_, err := unmarshalOrderers([]byte{0x0a, 0x10})
+		require.Error(t, err)
+	})
+}
+
+func TestBFTConsenters(t *testing.T) {
+	t.Run("Returns consenters", func(t *testing.T) {
+		expected := []*BFTConsenter{NewBFTConsenter(1), NewBFTConsenter(2)}
+
+		actual, err := BFTConsenters(NewBFTChannelConfig(t, &BFTOptions{}, expected...))
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("Missing consenter mapping returns no consenters", func(t *testing.T) {
+		consensusType := &orderer.ConsensusType{
+			Type: RaftConsensusType,
+		}
+
+		actual, err := BFTConsenters(NewOrdererChannelConfig(t, consensusType))
+		require.NoError(t, err)
+		require.Empty(t, actual)
+	})
+}
+
+func TestAddBFTConsenter(t *testing.T) {
+	existing := []*BFTConsenter{NewBFTConsenter(1), NewBFTConsenter(2), NewBFTConsenter(3), NewBFTConsenter(4)}
+
+	t.Run("Adds consenter", func(t *testing.T) {
+		actual, err := AddBFTConsenter(NewBFTChannelConfig(t, &BFTOptions{}, existing...), NewBFTConsenter(5))
+		require.NoError(t, err)
+
+		consenters, err := BFTConsenters(actual)
+		require.NoError(t, err)
+		require.Len(t, consenters, 5)
+		require.Equal(t, NewBFTConsenter(5), consenters[4])
+	})
+
+	t.Run("Duplicate ID gives error", func(t *testing.T) {
+		consenter := NewBFTConsenter(5)
+		consenter.ID = 1
+
+		_, err := AddBFTConsenter(NewBFTChannelConfig(t, &BFTOptions{}, existing...), consenter)
+		require.ErrorContains(t, err, "duplicate consenter ID")
+	})
+
+	t.Run("Duplicate endpoint gives error", func(t *testing.T) {
+		consenter := NewBFTConsenter(5)
+		consenter.Port = 7051
+
+		_, err := AddBFTConsenter(NewBFTChannelConfig(t, &BFTOptions{}, existing...), consenter)
+		require.ErrorContains(t, err, "orderer:7051")
+	})
+
+	t.Run("Adding to a single consenter gives quorum error", func(t *testing.T) {
+		_, err := AddBFTConsenter(NewBFTChannelConfig(t, &BFTOptions{}, NewBFTConsenter(1)), NewBFTConsenter(2))
+		require.ErrorContains(t, err, "quorum")
+	})
+
+	t.Run("Supplied config is not modified", func(t *testing.T) {
+		channelConfig := NewBFTChannelConfig(t, &BFTOptions{}, existing...)
+
+		_, err := AddBFTConsenter(channelConfig, NewBFTConsenter(5))
+		require.NoError(t, err)
+
+		consenters, err := BFTConsenters(channelConfig)
+		require.NoError(t, err)
+		require.Len(t, consenters, 4)
+	})
+}
+
+func TestRemoveBFTConsenter(t *testing.T) {
+	existing := []*BFTConsenter{NewBFTConsenter(1), NewBFTConsenter(2), NewBFTConsenter(3), NewBFTConsenter(4)}
+
+	t.Run("Removes consenter", func(t *testing.T) {
+		actual, err := RemoveBFTConsenter(NewBFTChannelConfig(t, &BFTOptions{}, existing...), 2)
+		require.NoError(t, err)
+
+		consenters, err := BFTConsenters(actual)
+		require.NoError(t, err)
+		require.Equal(t, []*BFTConsenter{NewBFTConsenter(1), NewBFTConsenter(3), NewBFTConsenter(4)}, consenters)
+	})
+
+	t.Run("Unknown ID gives error", func(t *testing.T) {
+		_, err := RemoveBFTConsenter(NewBFTChannelConfig(t, &BFTOptions{}, existing...), 9)
+		require.ErrorContains(t, err, "9")
+	})
+
+	t.Run("Removing last consenter gives error", func(t *testing.T) {
+		_, err := RemoveBFTConsenter(NewBFTChannelConfig(t, &BFTOptions{}, NewBFTConsenter(1)), 1)
+		require.ErrorContains(t, err, "no consenters")
+	})
+}
+
+func TestUpdateBFTConsenter(t *testing.T) {
+	t.Run("Replaces consenter certificates", func(t *testing.T) {
+		consenter := NewBFTConsenter(1)
+		consenter.ServerTLSCert = []byte("NEW_SERVER_CERT")
+
+		actual, err := UpdateBFTConsenter(NewBFTChannelConfig(t, &BFTOptions{}, NewBFTConsenter(1)), consenter)
+		require.NoError(t, err)
+
+		consenters, err := BFTConsenters(actual)
+		require.NoError(t, err)
+		require.Equal(t, []*BFTConsenter{consenter}, consenters)
+	})
+
+	t.Run("Unknown ID gives error", func(t *testing.T) {
+		_, err := UpdateBFTConsenter(NewBFTChannelConfig(t, &BFTOptions{}, NewBFTConsenter(1)), NewBFTConsenter(2))
+		require.ErrorContains(t, err, "2")
+	})
+}
+
+func TestValidateBFTConsenters(t *testing.T) {
+	t.Run("Missing MSP ID gives error", func(t *testing.T) {
+		consenter := NewBFTConsenter(1)
+		consenter.MspID = ""
+
+		err := ValidateBFTConsenters(nil, []*BFTConsenter{consenter})
+		require.ErrorContains(t, err, "MSP ID")
+	})
+
+	t.Run("Replacing too many consenters gives quorum error", func(t *testing.T) {
+		original := []*BFTConsenter{NewBFTConsenter(1), NewBFTConsenter(2), NewBFTConsenter(3), NewBFTConsenter(4)}
+		updated := []*BFTConsenter{NewBFTConsenter(1), NewBFTConsenter(2), NewBFTConsenter(5), NewBFTConsenter(6)}
+
+		err := ValidateBFTConsenters(original, updated)
+		require.ErrorContains(t, err, "quorum")
+	})
+}
+
+func TestBFTQuorum(t *testing.T) {
+	for consenterCount, expected := range map[int]int{1: 1, 2: 2, 3: 2, 4: 3, 5: 4, 7: 5, 10: 7} {
+		require.Equal(t, expected, BFTQuorum(consenterCount), "consenters: %d", consenterCount)
+	}
+}
+
+func TestBFTOptions(t *testing.T) {
+	t.Run("Returns options", func(t *testing.T) {
+		expected := &BFTOptions{
+			RequestBatchMaxCount: 100,
+			ViewChangeTimeout:    "20s",
+		}
+
+		actual, err := BFTOptionsFromConfig(NewBFTChannelConfig(t, expected))
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("Non-BFT consensus type gives error", func(t *testing.T) {
+		_, err := BFTOptionsFromConfig(NewRaftChannelConfig(t))
+		require.ErrorContains(t, err, RaftConsensusType)
+	})
+
+	t.Run("Sets options", func(t *testing.T) {
+		expected := &BFTOptions{
+			RequestBatchMaxCount: 500,
+			LeaderRotation:       BFTLeaderRotationOff,
+		}
+
+		updated, err := SetBFTOptions(NewBFTChannelConfig(t, &BFTOptions{RequestBatchMaxCount: 100}), expected)
+		require.NoError(t, err)
+
+		actual, err := BFTOptionsFromConfig(updated)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+}
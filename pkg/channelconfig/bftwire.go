@@ -0,0 +1,262 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The protobuf messages used for BFT consensus configuration (common.Orderers, common.Consenter and
+// orderer.smartbft.Options) are not included in the version of fabric-protos-go-apiv2 used by this module, so they are
+// encoded and decoded here directly in the protobuf wire format. Unrecognized fields are preserved.
+//
+// Field numbers are copied from the hyperledger/fabric-protos definitions published as fabric-protos-go-apiv2 v0.3.3:
+// Orderers and Consenter in common/configuration.proto, and Options in orderer/smartbft/configuration.proto. The
+// schema used by bftwire_test.go must be kept in step with these. Once fabric-protos-go-apiv2 can be updated to a
+// version that includes these messages, this file should be replaced by the generated types.
+
+func marshalOrderers(consenters []*BFTConsenter) []byte {
+	var b []byte
+	for _, consenter := range consenters {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, marshalBFTConsenter(consenter))
+	}
+	return b
+}
+
+func unmarshalOrderers(b []byte) ([]*BFTConsenter, error) {
+	var result []*BFTConsenter
+
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, value []byte) (bool, error) {
+		if num != 1 || typ != protowire.BytesType {
+			return false, nil
+		}
+
+		consenter, err := unmarshalBFTConsenter(value)
+		if err != nil {
+			return false, err
+		}
+
+		result = append(result, consenter)
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize orderers: %w", err)
+	}
+
+	return result, nil
+}
+
+func marshalBFTConsenter(consenter *BFTConsenter) []byte {
+	var b []byte
+	b = appendVarint(b, 1, uint64(consenter.ID))
+	b = appendString(b, 2, consenter.Host)
+	b = appendVarint(b, 3, uint64(consenter.Port))
+	b = appendString(b, 4, consenter.MspID)
+	b = appendBytes(b, 5, consenter.Identity)
+	b = appendBytes(b, 6, consenter.ClientTLSCert)
+	b = appendBytes(b, 7, consenter.ServerTLSCert)
+	return append(b, consenter.unknownFields...)
+}
+
+func unmarshalBFTConsenter(b []byte) (*BFTConsenter, error) {
+	result := &BFTConsenter{}
+
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, value []byte) (bool, error) {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, err := consumeVarint(value)
+			result.ID = uint32(v)
+			return true, err
+		case num == 2 && typ == protowire.BytesType:
+			result.Host = string(value)
+		case num == 3 && typ == protowire.VarintType:
+			v, err := consumeVarint(value)
+			result.Port = uint32(v)
+			return true, err
+		case num == 4 && typ == protowire.BytesType:
+			result.MspID = string(value)
+		case num == 5 && typ == protowire.BytesType:
+			result.Identity = value
+		case num == 6 && typ == protowire.BytesType:
+			result.ClientTLSCert = value
+		case num == 7 && typ == protowire.BytesType:
+			result.ServerTLSCert = value
+		default:
+			return false, nil
+		}
+		return true, nil
+	}, &result.unknownFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize consenter: %w", err)
+	}
+
+	return result, nil
+}
+
+func marshalBFTOptions(options *BFTOptions) []byte {
+	var b []byte
+	b = appendVarint(b, 1, options.RequestBatchMaxCount)
+	b = appendVarint(b, 2, options.RequestBatchMaxBytes)
+	b = appendString(b, 3, options.RequestBatchMaxInterval)
+	b = appendVarint(b, 4, options.IncomingMessageBufferSize)
+	b = appendVarint(b, 5, options.RequestPoolSize)
+	b = appendString(b, 6, options.RequestForwardTimeout)
+	b = appendString(b, 7, options.RequestComplainTimeout)
+	b = appendString(b, 8, options.RequestAutoRemoveTimeout)
+	b = appendString(b, 9, options.ViewChangeResendInterval)
+	b = appendString(b, 10, options.ViewChangeTimeout)
+	b = appendString(b, 11, options.LeaderHeartbeatTimeout)
+	b = appendVarint(b, 12, options.LeaderHeartbeatCount)
+	b = appendString(b, 13, options.CollectTimeout)
+	b = appendVarint(b, 14, protowire.EncodeBool(options.SyncOnStart))
+	b = appendVarint(b, 15, protowire.EncodeBool(options.SpeedUpViewChange))
+	b = appendVarint(b, 16, uint64(options.LeaderRotation))
+	b = appendVarint(b, 17, options.DecisionsPerLeader)
+	b = appendVarint(b, 18, options.RequestMaxBytes)
+	b = appendString(b, 19, options.RequestPoolSubmitTimeout)
+	return append(b, options.unknownFields...)
+}
+
+func unmarshalBFTOptions(b []byte) (*BFTOptions, error) {
+	result := &BFTOptions{}
+
+	varints := map[protowire.Number]*uint64{
+		1:  &result.RequestBatchMaxCount,
+		2:  &result.RequestBatchMaxBytes,
+		4:  &result.IncomingMessageBufferSize,
+		5:  &result.RequestPoolSize,
+		12: &result.LeaderHeartbeatCount,
+		17: &result.DecisionsPerLeader,
+		18: &result.RequestMaxBytes,
+	}
+	strings := map[protowire.Number]*string{
+		3:  &result.RequestBatchMaxInterval,
+		6:  &result.RequestForwardTimeout,
+		7:  &result.RequestComplainTimeout,
+		8:  &result.RequestAutoRemoveTimeout,
+		9:  &result.ViewChangeResendInterval,
+		10: &result.ViewChangeTimeout,
+		11: &result.LeaderHeartbeatTimeout,
+		13: &result.CollectTimeout,
+		19: &result.RequestPoolSubmitTimeout,
+	}
+	bools := map[protowire.Number]*bool{
+		14: &result.SyncOnStart,
+		15: &result.SpeedUpViewChange,
+	}
+
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, value []byte) (bool, error) {
+		if field, ok := strings[num]; ok && typ == protowire.BytesType {
+			*field = string(value)
+			return true, nil
+		}
+
+		if typ != protowire.VarintType {
+			return false, nil
+		}
+
+		v, err := consumeVarint(value)
+		if err != nil {
+			return false, err
+		}
+
+		if field, ok := varints[num]; ok {
+			*field = v
+		} else if field, ok := bools[num]; ok {
+			*field = protowire.DecodeBool(v)
+		} else if num == 16 {
+			result.LeaderRotation = BFTLeaderRotation(v)
+		} else {
+			return false, nil
+		}
+		return true, nil
+	}, &result.unknownFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize BFT options: %w", err)
+	}
+
+	return result, nil
+}
+
+// consumeFields calls the supplied function for each field in the message. For length-delimited fields, the value is
+// the field content; for other fields, the value is the raw encoded field value. If the function returns false, the
+// complete encoded field is appended to unknownFields, if supplied.
+func consumeFields(
+	b []byte,
+	field func(num protowire.Number, typ protowire.Type, value []byte) (bool, error),
+	unknownFields ...*[]byte,
+) error {
+	for len(b) > 0 {
+		num, typ, tagLength := protowire.ConsumeTag(b)
+		if tagLength < 0 {
+			return protowire.ParseError(tagLength)
+		}
+
+		valueLength := protowire.ConsumeFieldValue(num, typ, b[tagLength:])
+		if valueLength < 0 {
+			return protowire.ParseError(valueLength)
+		}
+
+		fieldBytes := b[:tagLength+valueLength]
+		value := fieldBytes[tagLength:]
+		if typ == protowire.BytesType {
+			content, n := protowire.ConsumeBytes(value)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			value = append([]byte(nil), content...)
+		}
+
+		known, err := field(num, typ, value)
+		if err != nil {
+			return err
+		}
+		if !known {
+			for _, unknown := range unknownFields {
+				*unknown = append(*unknown, fieldBytes...)
+			}
+		}
+
+		b = b[tagLength+valueLength:]
+	}
+
+	return nil
+}
+
+func consumeVarint(b []byte) (uint64, error) {
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	return v, nil
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
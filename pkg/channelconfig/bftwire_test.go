@@ -0,0 +1,203 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Schemas of the upstream BFT configuration messages, transcribed from hyperledger/fabric-protos as published in
+// fabric-protos-go-apiv2 v0.3.3. Messages built from these are encoded and decoded by the protobuf runtime, in the
+// same way as by Fabric's generated types, and are used to check the hand-written wire format.
+const (
+	commonConfigurationSchema = `
+name: "common/configuration.proto"
+package: "common"
+syntax: "proto3"
+message_type: {
+	name: "Orderers"
+	field: {name: "consenter_mapping" number: 1 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".common.Consenter"}
+}
+message_type: {
+	name: "Consenter"
+	field: {name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_UINT32}
+	field: {name: "host" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING}
+	field: {name: "port" number: 3 label: LABEL_OPTIONAL type: TYPE_UINT32}
+	field: {name: "msp_id" number: 4 label: LABEL_OPTIONAL type: TYPE_STRING}
+	field: {name: "identity" number: 5 label: LABEL_OPTIONAL type: TYPE_BYTES}
+	field: {name: "client_tls_cert" number: 6 label: LABEL_OPTIONAL type: TYPE_BYTES}
+	field: {name: "server_tls_cert" number: 7 label: LABEL_OPTIONAL type: TYPE_BYTES}
+}
+`
+	smartBFTConfigurationSchema = `
+name: "orderer/smartbft/configuration.proto"
+package: "orderer.smartbft"
+syntax: "proto3"
+message_type: {
+	name: "Options"
+	field: {name: "request_batch_max_count" number: 1 label: LABEL_OPTIONAL type: TYPE_UINT64}
+	field: {name: "request_batch_max_bytes" number: 2 label: LABEL_OPTIONAL type: TYPE_UINT64}
+	field: {name: "request_batch_max_interval" number: 3 label: LABEL_OPTIONAL type: TYPE_STRING}
+	field: {name: "incoming_message_buffer_size" number: 4 label: LABEL_OPTIONAL type: TYPE_UINT64}
+	field: {name: "request_pool_size" number: 5 label: LABEL_OPTIONAL type: TYPE_UINT64}
+	field: {name: "request_forward_timeout" number: 6 label: LABEL_OPTIONAL type: TYPE_STRING}
+	field: {name: "request_complain_timeout" number: 7 label: LABEL_OPTIONAL type: TYPE_STRING}
+	field: {name: "request_auto_remove_timeout" number: 8 label: LABEL_OPTIONAL type: TYPE_STRING}
+	field: {name: "view_change_resend_interval" number: 9 label: LABEL_OPTIONAL type: TYPE_STRING}
+	field: {name: "view_change_timeout" number: 10 label: LABEL_OPTIONAL type: TYPE_STRING}
+	field: {name: "leader_heartbeat_timeout" number: 11 label: LABEL_OPTIONAL type: TYPE_STRING}
+	field: {name: "leader_heartbeat_count" number: 12 label: LABEL_OPTIONAL type: TYPE_UINT64}
+	field: {name: "collect_timeout" number: 13 label: LABEL_OPTIONAL type: TYPE_STRING}
+	field: {name: "sync_on_start" number: 14 label: LABEL_OPTIONAL type: TYPE_BOOL}
+	field: {name: "speed_up_view_change" number: 15 label: LABEL_OPTIONAL type: TYPE_BOOL}
+	field: {name: "leader_rotation" number: 16 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".orderer.smartbft.Options.Rotation"}
+	field: {name: "decisions_per_leader" number: 17 label: LABEL_OPTIONAL type: TYPE_UINT64}
+	field: {name: "request_max_bytes" number: 18 label: LABEL_OPTIONAL type: TYPE_UINT64}
+	field: {name: "request_pool_submit_timeout" number: 19 label: LABEL_OPTIONAL type: TYPE_STRING}
+	enum_type: {
+		name: "Rotation"
+		value: {name: "ROTATION_UNSPECIFIED" number: 0}
+		value: {name: "ROTATION_OFF" number: 1}
+		value: {name: "ROTATION_ON" number: 2}
+	}
+}
+`
+)
+
+func AssertMessageDescriptor(t *testing.T, schema string, name protoreflect.FullName) protoreflect.MessageDescriptor {
+	fileProto := &descriptorpb.FileDescriptorProto{}
+	require.NoError(t, prototext.Unmarshal([]byte(schema), fileProto))
+
+	file, err := protodesc.NewFile(fileProto, &protoregistry.Files{})
+	require.NoError(t, err)
+
+	descriptor := file.Messages().ByName(name.Name())
+	require.NotNil(t, descriptor, "message %s", name)
+	require.Equal(t, name, descriptor.FullName())
+	return descriptor
+}
+
+func SetUpstreamConsenter(message protoreflect.Message, consenter *BFTConsenter) {
+	fields := message.Descriptor().Fields()
+	message.Set(fields.ByName("id"), protoreflect.ValueOfUint32(consenter.ID))
+	message.Set(fields.ByName("host"), protoreflect.ValueOfString(consenter.Host))
+	message.Set(fields.ByName("port"), protoreflect.ValueOfUint32(consenter.Port))
+	message.Set(fields.ByName("msp_id"), protoreflect.ValueOfString(consenter.MspID))
+	message.Set(fields.ByName("identity"), protoreflect.ValueOfBytes(consenter.Identity))
+	message.Set(fields.ByName("client_tls_cert"), protoreflect.ValueOfBytes(consenter.ClientTLSCert))
+	message.Set(fields.ByName("server_tls_cert"), protoreflect.ValueOfBytes(consenter.ServerTLSCert))
+}
+
+func NewUpstreamOrderers(t *testing.T, consenters ...*BFTConsenter) *dynamicpb.Message {
+	message := dynamicpb.NewMessage(AssertMessageDescriptor(t, commonConfigurationSchema, "common.Orderers"))
+	mapping := message.Mutable(message.Descriptor().Fields().ByName("consenter_mapping")).List()
+	for _, consenter := range consenters {
+		element := mapping.NewElement()
+		SetUpstreamConsenter(element.Message(), consenter)
+		mapping.Append(element)
+	}
+	return message
+}
+
+func NewUpstreamOptions(t *testing.T, options *BFTOptions) *dynamicpb.Message {
+	message := dynamicpb.NewMessage(AssertMessageDescriptor(t, smartBFTConfigurationSchema, "orderer.smartbft.Options"))
+	fields := message.Descriptor().Fields()
+	set := func(name protoreflect.Name, value protoreflect.Value) {
+		message.Set(fields.ByName(name), value)
+	}
+	set("request_batch_max_count", protoreflect.ValueOfUint64(options.RequestBatchMaxCount))
+	set("request_batch_max_bytes", protoreflect.ValueOfUint64(options.RequestBatchMaxBytes))
+	set("request_batch_max_interval", protoreflect.ValueOfString(options.RequestBatchMaxInterval))
+	set("incoming_message_buffer_size", protoreflect.ValueOfUint64(options.IncomingMessageBufferSize))
+	set("request_pool_size", protoreflect.ValueOfUint64(options.RequestPoolSize))
+	set("request_forward_timeout", protoreflect.ValueOfString(options.RequestForwardTimeout))
+	set("request_complain_timeout", protoreflect.ValueOfString(options.RequestComplainTimeout))
+	set("request_auto_remove_timeout", protoreflect.ValueOfString(options.RequestAutoRemoveTimeout))
+	set("view_change_resend_interval", protoreflect.ValueOfString(options.ViewChangeResendInterval))
+	set("view_change_timeout", protoreflect.ValueOfString(options.ViewChangeTimeout))
+	set("leader_heartbeat_timeout", protoreflect.ValueOfString(options.LeaderHeartbeatTimeout))
+	set("leader_heartbeat_count", protoreflect.ValueOfUint64(options.LeaderHeartbeatCount))
+	set("collect_timeout", protoreflect.ValueOfString(options.CollectTimeout))
+	set("sync_on_start", protoreflect.ValueOfBool(options.SyncOnStart))
+	set("speed_up_view_change", protoreflect.ValueOfBool(options.SpeedUpViewChange))
+	set("leader_rotation", protoreflect.ValueOfEnum(protoreflect.EnumNumber(options.LeaderRotation)))
+	set("decisions_per_leader", protoreflect.ValueOfUint64(options.DecisionsPerLeader))
+	set("request_max_bytes", protoreflect.ValueOfUint64(options.RequestMaxBytes))
+	set("request_pool_submit_timeout", protoreflect.ValueOfString(options.RequestPoolSubmitTimeout))
+	return message
+}
+
+func AssertUpstreamMarshal(t *testing.T, message proto.Message) []byte {
+	result, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	require.NoError(t, err)
+	return result
+}
+
+func AssertUpstreamEqual(t *testing.T, expected proto.Message, actualBytes []byte) {
+	actual := dynamicpb.NewMessage(expected.ProtoReflect().Descriptor())
+	require.NoError(t, proto.Unmarshal(actualBytes, actual))
+	require.True(t, proto.Equal(expected, actual), "Expected %v, got %v", expected, actual)
+}
+
+func TestBFTWireFormatMatchesUpstream(t *testing.T) {
+	consenters := []*BFTConsenter{NewBFTConsenter(1), NewBFTConsenter(2)}
+	options := &BFTOptions{
+		RequestBatchMaxCount:      100,
+		RequestBatchMaxBytes:      10485760,
+		RequestBatchMaxInterval:   "50ms",
+		IncomingMessageBufferSize: 200,
+		RequestPoolSize:           100000,
+		RequestForwardTimeout:     "2s",
+		RequestComplainTimeout:    "20s",
+		RequestAutoRemoveTimeout:  "3m",
+		ViewChangeResendInterval:  "5s",
+		ViewChangeTimeout:         "20s",
+		LeaderHeartbeatTimeout:    "1m",
+		LeaderHeartbeatCount:      10,
+		CollectTimeout:            "1s",
+		SyncOnStart:               true,
+		SpeedUpViewChange:         true,
+		LeaderRotation:            BFTLeaderRotationOff,
+		DecisionsPerLeader:        3,
+		RequestMaxBytes:           10240,
+		RequestPoolSubmitTimeout:  "5s",
+	}
+
+	t.Run("Orderers encoding is decoded by upstream schema", func(t *testing.T) {
+		AssertUpstreamEqual(t, NewUpstreamOrderers(t, consenters...), marshalOrderers(consenters))
+	})
+
+	t.Run("Orderers encoded by upstream schema are decoded", func(t *testing.T) {
+		upstreamBytes := AssertUpstreamMarshal(t, NewUpstreamOrderers(t, consenters...))
+
+		actual, err := unmarshalOrderers(upstreamBytes)
+		require.NoError(t, err)
+		require.Equal(t, consenters, actual)
+		require.Equal(t, upstreamBytes, marshalOrderers(actual))
+	})
+
+	t.Run("Options encoding is decoded by upstream schema", func(t *testing.T) {
+		AssertUpstreamEqual(t, NewUpstreamOptions(t, options), marshalBFTOptions(options))
+	})
+
+	t.Run("Options encoded by upstream schema are decoded", func(t *testing.T) {
+		upstreamBytes := AssertUpstreamMarshal(t, NewUpstreamOptions(t, options))
+
+		actual, err := unmarshalBFTOptions(upstreamBytes)
+		require.NoError(t, err)
+		require.Equal(t, options, actual)
+		require.Equal(t, upstreamBytes, marshalBFTOptions(actual))
+	})
+}
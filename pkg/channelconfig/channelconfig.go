@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package channelconfig provides functions to inspect and modify channel configuration, and to create the config
+// update envelopes used to apply configuration changes to a channel.
+package channelconfig
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"google.golang.org/protobuf/proto"
+)
+
+// Names of config groups, values and policies used within channel configuration.
+const (
+	ApplicationGroupKey = "Application"
+	OrdererGroupKey     = "Orderer"
+
+	MSPKey                       = "MSP"
+	AnchorPeersKey               = "AnchorPeers"
+	ConsensusTypeKey             = "ConsensusType"
+	EndpointsKey                 = "Endpoints"
+	OrdererAddressesKey          = "OrdererAddresses"
+	HashingAlgorithmKey          = "HashingAlgorithm"
+	BlockDataHashingStructureKey = "BlockDataHashingStructure"
+	CapabilitiesKey              = "Capabilities"
+	BatchSizeKey                 = "BatchSize"
+	BatchTimeoutKey              = "BatchTimeout"
+	ACLsKey                      = "ACLs"
+
+	AdminsPolicyKey = "Admins"
+)
+
+// childGroup returns the named child group of the channel group.
+func childGroup(channelConfig *common.Config, key string) (*common.ConfigGroup, error) {
+	group := channelConfig.GetChannelGroup().GetGroups()[key]
+	if group == nil {
+		return nil, fmt.Errorf("channel config has no %s group", key)
+	}
+
+	return group, nil
+}
+
+// applicationOrgGroup returns the application organization config group for the specified MSP ID.
+func applicationOrgGroup(channelConfig *common.Config, mspID string) (*common.ConfigGroup, error) {
+	applicationGroup, err := childGroup(channelConfig, ApplicationGroupKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return orgGroup(applicationGroup, mspID)
+}
+
+// ordererOrgGroup returns the orderer organization config group for the specified MSP ID.
+func ordererOrgGroup(channelConfig *common.Config, mspID string) (*common.ConfigGroup, error) {
+	ordererGroup, err := childGroup(channelConfig, OrdererGroupKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return orgGroup(ordererGroup, mspID)
+}
+
+// errOrgNotFound is returned, wrapped, when no organization config group has the requested MSP ID.
+var errOrgNotFound = errors.New("no organization found")
+
+func orgGroup(parent *common.ConfigGroup, mspID string) (*common.ConfigGroup, error) {
+	for _, group := range parent.GetGroups() {
+		fabricMSPConfig, err := fabricMSPConfig(group)
+		if err != nil {
+			return nil, err
+		}
+
+		if fabricMSPConfig.GetName() == mspID {
+			return group, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w with MSP ID: %s", errOrgNotFound, mspID)
+}
+
+func fabricMSPConfig(orgGroup *common.ConfigGroup) (*msp.FabricMSPConfig, error) {
+	mspConfig := &msp.MSPConfig{}
+	if err := proto.Unmarshal(orgGroup.GetValues()[MSPKey].GetValue(), mspConfig); err != nil {
+		return nil, fmt.Errorf("failed to deserialize MSP config: %w", err)
+	}
+
+	result := &msp.FabricMSPConfig{}
+	if err := proto.Unmarshal(mspConfig.GetConfig(), result); err != nil {
+		return nil, fmt.Errorf("failed to deserialize Fabric MSP config: %w", err)
+	}
+
+	return result, nil
+}
+
+func getValue(group *common.ConfigGroup, key string, value proto.Message) error {
+	configValue := group.GetValues()[key]
+	if configValue == nil {
+		return fmt.Errorf("config group has no %s value", key)
+	}
+
+	if err := proto.Unmarshal(configValue.GetValue(), value); err != nil {
+		return fmt.Errorf("failed to deserialize %s value: %w", key, err)
+	}
+
+	return nil
+}
+
+func setValue(group *common.ConfigGroup, key string, value proto.Message, modPolicy string) error {
+	valueBytes, err := proto.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	setRawValue(group, key, valueBytes, modPolicy)
+	return nil
+}
+
+func setRawValue(group *common.ConfigGroup, key string, valueBytes []byte, modPolicy string) {
+	if group.Values == nil {
+		group.Values = make(map[string]*common.ConfigValue)
+	}
+
+	configValue := group.Values[key]
+	if configValue == nil {
+		configValue = &common.ConfigValue{
+			ModPolicy: modPolicy,
+		}
+		group.Values[key] = configValue
+	}
+	configValue.Value = valueBytes
+}
@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:generate mockgen -destination ./signingidentity_mock_test.go -package ${GOPACKAGE} github.com/bestbeforetoday/fabric-admin/pkg/identity SigningIdentity
+
+func NewSigningIdentity(controller *gomock.Controller, signature []byte) *MockSigningIdentity {
+	mockIdentity := NewMockSigningIdentity(controller)
+	mockIdentity.EXPECT().MspID().Return("SIGNER_MSP").AnyTimes()
+	mockIdentity.EXPECT().Credentials().Return([]byte("SIGNER_CREDENTIALS")).AnyTimes()
+	mockIdentity.EXPECT().Sign(gomock.Any()).Return(signature, nil).AnyTimes()
+
+	return mockIdentity
+}
+
+func AssertMarshal(t *testing.T, m protoreflect.ProtoMessage) []byte {
+	result, err := proto.Marshal(m)
+	require.NoError(t, err)
+	return result
+}
+
+// AssertUnmarshal ensures that a protobuf is umarshaled without error
+func AssertUnmarshal(t *testing.T, b []byte, m protoreflect.ProtoMessage) {
+	err := proto.Unmarshal(b, m)
+	require.NoError(t, err)
+}
+
+// AssertProtoEqual ensures an expected protobuf message matches an actual message
+func AssertProtoEqual(t *testing.T, expected protoreflect.ProtoMessage, actual protoreflect.ProtoMessage) {
+	require.True(t, proto.Equal(expected, actual), "Expected %v, got %v", expected, actual)
+}
+
+// AssertUnmarshalConfigUpdate ensures that a config update is unmarshaled from an envelope without error
+func AssertUnmarshalConfigUpdate(t *testing.T, envelope *common.Envelope) (*common.ChannelHeader, *common.ConfigUpdateEnvelope, *common.ConfigUpdate) {
+	payload := &common.Payload{}
+	AssertUnmarshal(t, envelope.GetPayload(), payload)
+
+	channelHeader := &common.ChannelHeader{}
+	AssertUnmarshal(t, payload.GetHeader().GetChannelHeader(), channelHeader)
+
+	configUpdateEnvelope := &common.ConfigUpdateEnvelope{}
+	AssertUnmarshal(t, payload.GetData(), configUpdateEnvelope)
+
+	configUpdate := &common.ConfigUpdate{}
+	AssertUnmarshal(t, configUpdateEnvelope.GetConfigUpdate(), configUpdate)
+
+	return channelHeader, configUpdateEnvelope, configUpdate
+}
+
+func NewOrgGroup(t *testing.T, mspID string) *common.ConfigGroup {
+	fabricMSPConfig := &msp.FabricMSPConfig{
+		Name: mspID,
+	}
+	mspConfig := &msp.MSPConfig{
+		Config: AssertMarshal(t, fabricMSPConfig),
+	}
+
+	return &common.ConfigGroup{
+		Values: map[string]*common.ConfigValue{
+			MSPKey: {
+				Value:     AssertMarshal(t, mspConfig),
+				ModPolicy: AdminsPolicyKey,
+			},
+		},
+		ModPolicy: AdminsPolicyKey,
+	}
+}
+
+func NewChannelConfig(t *testing.T, mspIDs ...string) *common.Config {
+	applicationGroup := &common.ConfigGroup{
+		Groups:    map[string]*common.ConfigGroup{},
+		ModPolicy: AdminsPolicyKey,
+	}
+
+	for _, mspID := range mspIDs {
+		applicationGroup.Groups[mspID] = NewOrgGroup(t, mspID)
+	}
+
+	return &common.Config{
+		ChannelGroup: &common.ConfigGroup{
+			Groups: map[string]*common.ConfigGroup{
+				ApplicationGroupKey: applicationGroup,
+			},
+			ModPolicy: AdminsPolicyKey,
+		},
+	}
+}
+
+func NewOrdererChannelConfig(t *testing.T, consensusType *orderer.ConsensusType, mspIDs ...string) *common.Config {
+	ordererGroup := &common.ConfigGroup{
+		Groups: map[string]*common.ConfigGroup{},
+		Values: map[string]*common.ConfigValue{
+			ConsensusTypeKey: {
+				Value:     AssertMarshal(t, consensusType),
+				ModPolicy: AdminsPolicyKey,
+			},
+		},
+		ModPolicy: AdminsPolicyKey,
+	}
+
+	for _, mspID := range mspIDs {
+		ordererGroup.Groups[mspID] = NewOrgGroup(t, mspID)
+	}
+
+	return &common.Config{
+		ChannelGroup: &common.ConfigGroup{
+			Groups: map[string]*common.ConfigGroup{
+				OrdererGroupKey: ordererGroup,
+			},
+			ModPolicy: AdminsPolicyKey,
+		},
+	}
+}
+
+func AssertConsensusType(t *testing.T, channelConfig *common.Config) *orderer.ConsensusType {
+	configValue := channelConfig.GetChannelGroup().GetGroups()[OrdererGroupKey].GetValues()[ConsensusTypeKey]
+
+	result := &orderer.ConsensusType{}
+	AssertUnmarshal(t, configValue.GetValue(), result)
+
+	return result
+}
@@ -0,0 +1,246 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewRevocationList creates a PEM encoded certificate revocation list, signed by the issuing CA's private key, that
+// revokes certificates with the supplied serial numbers. Certificates revoked by any of the existing PEM encoded CRLs
+// from the same issuer, such as those returned by RevocationLists, remain revoked in the new CRL so that it can replace
+// them. Existing CRLs from other issuers are ignored. The CRL number is derived from the current time so that
+// successive revocation lists from the same issuer have increasing numbers.
+func NewRevocationList(
+	issuer *x509.Certificate,
+	signer crypto.Signer,
+	existingCRLs [][]byte,
+	revokedSerialNumbers []*big.Int,
+	nextUpdate time.Time,
+) ([]byte, error) {
+	now := time.Now()
+
+	revokedCertificates, err := carriedForwardRevocations(issuer, existingCRLs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, serialNumber := range revokedSerialNumbers {
+		if containsSerialNumber(revokedCertificates, serialNumber) {
+			continue
+		}
+		revokedCertificates = append(revokedCertificates, pkix.RevokedCertificate{
+			SerialNumber:   serialNumber,
+			RevocationTime: now,
+		})
+	}
+
+	template := &x509.RevocationList{
+		RevokedCertificates: revokedCertificates,
+		Number:              big.NewInt(now.UnixNano()),
+		ThisUpdate:          now,
+		NextUpdate:          nextUpdate,
+	}
+
+	crl, err := x509.CreateRevocationList(rand.Reader, template, issuer, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create revocation list: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}), nil
+}
+
+func carriedForwardRevocations(issuer *x509.Certificate, existingCRLs [][]byte) ([]pkix.RevokedCertificate, error) {
+	var results []pkix.RevokedCertificate
+
+	for _, existingCRL := range existingCRLs {
+		revocationList, err := parseRevocationList(existingCRL)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(revocationList.RawIssuer, issuer.RawSubject) {
+			continue
+		}
+		if err = revocationList.CheckSignatureFrom(issuer); err != nil {
+			return nil, fmt.Errorf("existing revocation list not signed by issuer: %w", err)
+		}
+
+		for _, revoked := range revocationList.RevokedCertificates {
+			if !containsSerialNumber(results, revoked.SerialNumber) {
+				results = append(results, revoked)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func containsSerialNumber(revokedCertificates []pkix.RevokedCertificate, serialNumber *big.Int) bool {
+	for _, revoked := range revokedCertificates {
+		if revoked.SerialNumber.Cmp(serialNumber) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RevocationLists returns the PEM encoded certificate revocation lists for the organization with the specified MSP
+// ID, from the application group if present, otherwise from the orderer group.
+func RevocationLists(channelConfig *common.Config, mspID string) ([][]byte, error) {
+	group, err := applicationOrgGroup(channelConfig, mspID)
+	if err != nil {
+		if group, err = ordererOrgGroup(channelConfig, mspID); err != nil {
+			return nil, fmt.Errorf("no organization found with MSP ID: %s", mspID)
+		}
+	}
+
+	mspConfig, err := fabricMSPConfig(group)
+	if err != nil {
+		return nil, err
+	}
+
+	return mspConfig.GetRevocationList(), nil
+}
+
+// SetRevocationLists returns a copy of the channel config with the certificate revocation lists for the organization
+// with the specified MSP ID replaced by the supplied PEM encoded CRLs. The organization is updated in both the
+// application and orderer groups where present. The supplied channel config is not modified.
+func SetRevocationLists(channelConfig *common.Config, mspID string, crls [][]byte) (*common.Config, error) {
+	return updateRevocationLists(channelConfig, mspID, func([][]byte) ([][]byte, error) {
+		return crls, nil
+	})
+}
+
+// AddRevocationList returns a copy of the channel config with the supplied PEM encoded CRL added to the certificate
+// revocation lists for the organization with the specified MSP ID. Any existing CRL from the same issuer is replaced,
+// so the supplied CRL must also revoke every certificate revoked by the CRL it replaces, otherwise an error is
+// returned. Create the CRL using NewRevocationList with the organization's existing revocation lists to ensure this.
+// The organization is updated in both the application and orderer groups where present. The supplied channel config
+// is not modified.
+func AddRevocationList(channelConfig *common.Config, mspID string, crl []byte) (*common.Config, error) {
+	revocationList, err := parseRevocationList(crl)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateRevocationLists(channelConfig, mspID, func(existing [][]byte) ([][]byte, error) {
+		var result [][]byte
+		for _, existingCRL := range existing {
+			existingRevocationList, err := parseRevocationList(existingCRL)
+			if err != nil || !bytes.Equal(existingRevocationList.RawIssuer, revocationList.RawIssuer) {
+				result = append(result, existingCRL)
+				continue
+			}
+
+			if err := checkRevokesAll(revocationList, existingRevocationList); err != nil {
+				return nil, err
+			}
+		}
+
+		return append(result, crl), nil
+	})
+}
+
+func checkRevokesAll(revocationList *x509.RevocationList, replaced *x509.RevocationList) error {
+	var missing []string
+	for _, revoked := range replaced.RevokedCertificates {
+		if !containsSerialNumber(revocationList.RevokedCertificates, revoked.SerialNumber) {
+			missing = append(missing, revoked.SerialNumber.String())
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("revocation list does not revoke serial numbers revoked by the existing revocation list from the same issuer: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// UpdateRevocationList creates a config update envelope, signed by the supplied identity, that adds the supplied PEM
+// encoded CRL to the certificate revocation lists for the organization with the specified MSP ID, replacing any
+// existing CRL from the same issuer. The supplied CRL must revoke every certificate revoked by the CRL it replaces, as
+// described for AddRevocationList.
+func UpdateRevocationList(
+	ctx context.Context,
+	signingID identity.SigningIdentity,
+	channelName string,
+	channelConfig *common.Config,
+	mspID string,
+	crl []byte,
+) (*common.Envelope, error) {
+	updated, err := AddRevocationList(channelConfig, mspID, crl)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUpdateEnvelope(ctx, signingID, channelName, channelConfig, updated)
+}
+
+func updateRevocationLists(
+	channelConfig *common.Config,
+	mspID string,
+	update func(existing [][]byte) ([][]byte, error),
+) (*common.Config, error) {
+	result := proto.Clone(channelConfig).(*common.Config)
+
+	var orgGroups []*common.ConfigGroup
+	if group, err := applicationOrgGroup(result, mspID); err == nil {
+		orgGroups = append(orgGroups, group)
+	}
+	if group, err := ordererOrgGroup(result, mspID); err == nil {
+		orgGroups = append(orgGroups, group)
+	}
+	if len(orgGroups) == 0 {
+		return nil, fmt.Errorf("no organization found with MSP ID: %s", mspID)
+	}
+
+	for _, group := range orgGroups {
+		mspConfig, err := fabricMSPConfig(group)
+		if err != nil {
+			return nil, err
+		}
+
+		if mspConfig.RevocationList, err = update(mspConfig.GetRevocationList()); err != nil {
+			return nil, err
+		}
+
+		if err := setMSPConfig(group, mspConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func parseRevocationList(crl []byte) (*x509.RevocationList, error) {
+	block, _ := pem.Decode(crl)
+	if block == nil {
+		return nil, errors.New("no PEM data found in revocation list")
+	}
+
+	revocationList, err := x509.ParseRevocationList(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse revocation list: %w", err)
+	}
+
+	return revocationList, nil
+}
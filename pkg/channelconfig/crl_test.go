@@ -0,0 +1,277 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/stretchr/testify/require"
+)
+
+func NewCA(t *testing.T, commonName string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(certificateDER)
+	require.NoError(t, err)
+
+	return certificate, privateKey
+}
+
+func NewRevocationListForCA(t *testing.T, commonName string, serialNumbers ...int64) []byte {
+	certificate, privateKey := NewCA(t, commonName)
+
+	var revoked []*big.Int
+	for _, serialNumber := range serialNumbers {
+		revoked = append(revoked, big.NewInt(serialNumber))
+	}
+
+	crl, err := NewRevocationList(certificate, privateKey, nil, revoked, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	return crl
+}
+
+func AssertRevokedSerialNumbers(t *testing.T, crl []byte) []int64 {
+	block, _ := pem.Decode(crl)
+	require.NotNil(t, block)
+
+	revocationList, err := x509.ParseRevocationList(block.Bytes)
+	require.NoError(t, err)
+
+	var results []int64
+	for _, revoked := range revocationList.RevokedCertificates {
+		results = append(results, revoked.SerialNumber.Int64())
+	}
+
+	return results
+}
+
+func AssertRevocationLists(t *testing.T, orgGroup *common.ConfigGroup) [][]byte {
+	mspConfig := &msp.MSPConfig{}
+	AssertUnmarshal(t, orgGroup.GetValues()[MSPKey].GetValue(), mspConfig)
+
+	fabricMSPConfig := &msp.FabricMSPConfig{}
+	AssertUnmarshal(t, mspConfig.GetConfig(), fabricMSPConfig)
+
+	return fabricMSPConfig.GetRevocationList()
+}
+
+func TestNewRevocationList(t *testing.T) {
+	t.Run("Creates signed CRL with revoked serial numbers", func(t *testing.T) {
+		certificate, privateKey := NewCA(t, "ca.org1")
+
+		crl, err := NewRevocationList(certificate, privateKey, nil, []*big.Int{big.NewInt(42), big.NewInt(99)}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		block, _ := pem.Decode(crl)
+		require.NotNil(t, block)
+		require.Equal(t, "X509 CRL", block.Type)
+
+		revocationList, err := x509.ParseRevocationList(block.Bytes)
+		require.NoError(t, err)
+		require.NoError(t, revocationList.CheckSignatureFrom(certificate))
+
+		require.Equal(t, []int64{42, 99}, AssertRevokedSerialNumbers(t, crl))
+	})
+
+	t.Run("Carries forward serial numbers from existing CRLs of the same issuer", func(t *testing.T) {
+		certificate, privateKey := NewCA(t, "ca.org1")
+		existingCRL, err := NewRevocationList(certificate, privateKey, nil, []*big.Int{big.NewInt(1), big.NewInt(2)}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		otherCRL := NewRevocationListForCA(t, "ica.org1", 3)
+
+		crl, err := NewRevocationList(certificate, privateKey, [][]byte{existingCRL, otherCRL}, []*big.Int{big.NewInt(2), big.NewInt(4)}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		require.Equal(t, []int64{1, 2, 4}, AssertRevokedSerialNumbers(t, crl))
+	})
+
+	t.Run("Existing CRL with issuer name but different key gives error", func(t *testing.T) {
+		certificate, privateKey := NewCA(t, "ca.org1")
+		impostorCRL := NewRevocationListForCA(t, "ca.org1", 1)
+
+		_, err := NewRevocationList(certificate, privateKey, [][]byte{impostorCRL}, []*big.Int{big.NewInt(2)}, time.Now().Add(time.Hour))
+		require.ErrorContains(t, err, "signed")
+	})
+
+	t.Run("Non-CA issuer gives error", func(t *testing.T) {
+		certificate, privateKey := NewCA(t, "ca.org1")
+		certificate.KeyUsage = x509.KeyUsageDigitalSignature
+
+		_, err := NewRevocationList(certificate, privateKey, nil, nil, time.Now().Add(time.Hour))
+		require.Error(t, err)
+	})
+}
+
+func TestAddRevocationList(t *testing.T) {
+	t.Run("Unknown MSP ID gives error", func(t *testing.T) {
+		_, err := AddRevocationList(NewChannelConfig(t, "Org1MSP"), "UnknownMSP", NewRevocationListForCA(t, "ca.org1", 1))
+		require.ErrorContains(t, err, "UnknownMSP")
+	})
+
+	t.Run("Invalid CRL gives error", func(t *testing.T) {
+		_, err := AddRevocationList(NewChannelConfig(t, "Org1MSP"), "Org1MSP", []byte("NOT_A_CRL"))
+		require.ErrorContains(t, err, "PEM")
+	})
+
+	t.Run("Appends CRL for organization", func(t *testing.T) {
+		crl := NewRevocationListForCA(t, "ca.org1", 1)
+
+		actual, err := AddRevocationList(NewChannelConfig(t, "Org1MSP", "Org2MSP"), "Org1MSP", crl)
+		require.NoError(t, err)
+
+		applicationGroup := actual.GetChannelGroup().GetGroups()[ApplicationGroupKey]
+		require.Equal(t, [][]byte{crl}, AssertRevocationLists(t, applicationGroup.GetGroups()["Org1MSP"]))
+		require.Empty(t, AssertRevocationLists(t, applicationGroup.GetGroups()["Org2MSP"]))
+	})
+
+	t.Run("Replaces CRL from same issuer", func(t *testing.T) {
+		certificate, privateKey := NewCA(t, "ca.org1")
+		oldCRL, err := NewRevocationList(certificate, privateKey, nil, []*big.Int{big.NewInt(1)}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		newCRL, err := NewRevocationList(certificate, privateKey, nil, []*big.Int{big.NewInt(1), big.NewInt(2)}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		otherCRL := NewRevocationListForCA(t, "ica.org1", 3)
+
+		channelConfig, err := SetRevocationLists(NewChannelConfig(t, "Org1MSP"), "Org1MSP", [][]byte{oldCRL, otherCRL})
+		require.NoError(t, err)
+
+		actual, err := AddRevocationList(channelConfig, "Org1MSP", newCRL)
+		require.NoError(t, err)
+
+		orgGroup := actual.GetChannelGroup().GetGroups()[ApplicationGroupKey].GetGroups()["Org1MSP"]
+		require.Equal(t, [][]byte{otherCRL, newCRL}, AssertRevocationLists(t, orgGroup))
+	})
+
+	t.Run("Replacement CRL that does not revoke existing serial numbers gives error", func(t *testing.T) {
+		certificate, privateKey := NewCA(t, "ca.org1")
+		oldCRL, err := NewRevocationList(certificate, privateKey, nil, []*big.Int{big.NewInt(1)}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		newCRL, err := NewRevocationList(certificate, privateKey, nil, []*big.Int{big.NewInt(2)}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		channelConfig, err := SetRevocationLists(NewChannelConfig(t, "Org1MSP"), "Org1MSP", [][]byte{oldCRL})
+		require.NoError(t, err)
+
+		_, err = AddRevocationList(channelConfig, "Org1MSP", newCRL)
+		require.ErrorContains(t, err, "serial numbers")
+	})
+
+	t.Run("Updates orderer organization", func(t *testing.T) {
+		crl := NewRevocationListForCA(t, "ca.orderer", 1)
+		channelConfig := NewOrdererChannelConfig(t, &orderer.ConsensusType{Type: RaftConsensusType}, "OrdererMSP")
+
+		actual, err := AddRevocationList(channelConfig, "OrdererMSP", crl)
+		require.NoError(t, err)
+
+		orgGroup := actual.GetChannelGroup().GetGroups()[OrdererGroupKey].GetGroups()["OrdererMSP"]
+		require.Equal(t, [][]byte{crl}, AssertRevocationLists(t, orgGroup))
+	})
+
+	t.Run("Supplied config is not modified", func(t *testing.T) {
+		channelConfig := NewChannelConfig(t, "Org1MSP")
+
+		_, err := AddRevocationList(channelConfig, "Org1MSP", NewRevocationListForCA(t, "ca.org1", 1))
+		require.NoError(t, err)
+
+		AssertProtoEqual(t, NewChannelConfig(t, "Org1MSP"), channelConfig)
+	})
+}
+
+func TestUpdateRevocationList(t *testing.T) {
+	t.Run("Creates signed config update envelope", func(t *testing.T) {
+		expectedSignature := []byte("SIGNATURE")
+
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		actual, err := UpdateRevocationList(
+			ctx,
+			NewSigningIdentity(controller, expectedSignature),
+			"CHANNEL",
+			NewChannelConfig(t, "Org1MSP"),
+			"Org1MSP",
+			NewRevocationListForCA(t, "ca.org1", 1),
+		)
+		require.NoError(t, err)
+
+		require.EqualValues(t, expectedSignature, actual.GetSignature(), "envelope signature")
+
+		_, _, configUpdate := AssertUnmarshalConfigUpdate(t, actual)
+		writeOrg := configUpdate.GetWriteSet().GetGroups()[ApplicationGroupKey].GetGroups()["Org1MSP"]
+		require.Contains(t, writeOrg.GetValues(), MSPKey)
+		require.EqualValues(t, 1, writeOrg.GetValues()[MSPKey].GetVersion(), "MSP value version")
+	})
+	t.Run("Earlier serial numbers stay revoked after second update", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+		signingID := NewSigningIdentity(controller, []byte("SIGNATURE"))
+		certificate, privateKey := NewCA(t, "ca.org1")
+
+		firstCRL, err := NewRevocationList(certificate, privateKey, nil, []*big.Int{big.NewInt(1)}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		channelConfig, err := AddRevocationList(NewChannelConfig(t, "Org1MSP"), "Org1MSP", firstCRL)
+		require.NoError(t, err)
+
+		existingCRLs, err := RevocationLists(channelConfig, "Org1MSP")
+		require.NoError(t, err)
+		secondCRL, err := NewRevocationList(certificate, privateKey, existingCRLs, []*big.Int{big.NewInt(2)}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		actual, err := UpdateRevocationList(ctx, signingID, "CHANNEL", channelConfig, "Org1MSP", secondCRL)
+		require.NoError(t, err)
+
+		_, _, configUpdate := AssertUnmarshalConfigUpdate(t, actual)
+		writeOrg := configUpdate.GetWriteSet().GetGroups()[ApplicationGroupKey].GetGroups()["Org1MSP"]
+		crls := AssertRevocationLists(t, writeOrg)
+		require.Len(t, crls, 1)
+		require.Equal(t, []int64{1, 2}, AssertRevokedSerialNumbers(t, crls[0]))
+	})
+}
+
+func TestRevocationLists(t *testing.T) {
+	t.Run("Returns CRLs for organization", func(t *testing.T) {
+		crl := NewRevocationListForCA(t, "ca.org1", 1)
+		channelConfig, err := SetRevocationLists(NewChannelConfig(t, "Org1MSP"), "Org1MSP", [][]byte{crl})
+		require.NoError(t, err)
+
+		actual, err := RevocationLists(channelConfig, "Org1MSP")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{crl}, actual)
+	})
+
+	t.Run("Unknown MSP ID gives error", func(t *testing.T) {
+		_, err := RevocationLists(NewChannelConfig(t, "Org1MSP"), "UnknownMSP")
+		require.ErrorContains(t, err, "UnknownMSP")
+	})
+}
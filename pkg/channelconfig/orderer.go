@@ -0,0 +1,73 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"errors"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"google.golang.org/protobuf/proto"
+)
+
+// ordererAdminsPolicy is the modification policy used for orderer values held at the channel level.
+const ordererAdminsPolicy = "/Channel/Orderer/Admins"
+
+// SetOrdererEndpoints returns a copy of the channel config with the endpoints for the orderer organization with the
+// specified MSP ID replaced by the supplied host:port addresses. The supplied channel config is not modified.
+func SetOrdererEndpoints(channelConfig *common.Config, mspID string, endpoints []string) (*common.Config, error) {
+	if err := validateAddresses(endpoints); err != nil {
+		return nil, err
+	}
+
+	result := proto.Clone(channelConfig).(*common.Config)
+
+	orgGroup, err := ordererOrgGroup(result, mspID)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := &common.OrdererAddresses{
+		Addresses: endpoints,
+	}
+	if err := setValue(orgGroup, EndpointsKey, addresses, AdminsPolicyKey); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SetOrdererAddresses returns a copy of the channel config with the channel-level orderer addresses replaced by the
+// supplied host:port addresses. Channel-level orderer addresses are deprecated in favor of per-organization endpoints,
+// set using SetOrdererEndpoints, but are still used by older clients. The supplied channel config is not modified.
+func SetOrdererAddresses(channelConfig *common.Config, addresses []string) (*common.Config, error) {
+	if err := validateAddresses(addresses); err != nil {
+		return nil, err
+	}
+
+	result := proto.Clone(channelConfig).(*common.Config)
+	if result.GetChannelGroup() == nil {
+		return nil, errors.New("channel config has no channel group")
+	}
+
+	ordererAddresses := &common.OrdererAddresses{
+		Addresses: addresses,
+	}
+	if err := setValue(result.GetChannelGroup(), OrdererAddressesKey, ordererAddresses, ordererAdminsPolicy); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func validateAddresses(addresses []string) error {
+	for _, address := range addresses {
+		if _, _, err := splitHostPort(address); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
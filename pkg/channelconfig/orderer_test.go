@@ -0,0 +1,65 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetOrdererEndpoints(t *testing.T) {
+	consensusType := &orderer.ConsensusType{
+		Type: RaftConsensusType,
+	}
+
+	t.Run("Sets endpoints for orderer organization", func(t *testing.T) {
+		endpoints := []string{"orderer0:7050", "orderer1:7050"}
+
+		actual, err := SetOrdererEndpoints(NewOrdererChannelConfig(t, consensusType, "OrdererMSP"), "OrdererMSP", endpoints)
+		require.NoError(t, err)
+
+		configValue := actual.GetChannelGroup().GetGroups()[OrdererGroupKey].GetGroups()["OrdererMSP"].GetValues()[EndpointsKey]
+		require.Equal(t, AdminsPolicyKey, configValue.GetModPolicy())
+
+		addresses := &common.OrdererAddresses{}
+		AssertUnmarshal(t, configValue.GetValue(), addresses)
+		require.Equal(t, endpoints, addresses.GetAddresses())
+	})
+
+	t.Run("Unknown MSP ID gives error", func(t *testing.T) {
+		_, err := SetOrdererEndpoints(NewOrdererChannelConfig(t, consensusType, "OrdererMSP"), "UnknownMSP", []string{"orderer0:7050"})
+		require.ErrorContains(t, err, "UnknownMSP")
+	})
+
+	t.Run("Invalid address gives error", func(t *testing.T) {
+		_, err := SetOrdererEndpoints(NewOrdererChannelConfig(t, consensusType, "OrdererMSP"), "OrdererMSP", []string{"orderer0"})
+		require.ErrorContains(t, err, "orderer0")
+	})
+}
+
+func TestSetOrdererAddresses(t *testing.T) {
+	t.Run("Sets channel-level orderer addresses", func(t *testing.T) {
+		addresses := []string{"orderer0:7050"}
+
+		actual, err := SetOrdererAddresses(NewChannelConfig(t, "Org1MSP"), addresses)
+		require.NoError(t, err)
+
+		configValue := actual.GetChannelGroup().GetValues()[OrdererAddressesKey]
+		require.Equal(t, "/Channel/Orderer/Admins", configValue.GetModPolicy())
+
+		ordererAddresses := &common.OrdererAddresses{}
+		AssertUnmarshal(t, configValue.GetValue(), ordererAddresses)
+		require.Equal(t, addresses, ordererAddresses.GetAddresses())
+	})
+
+	t.Run("Missing channel group gives error", func(t *testing.T) {
+		_, err := SetOrdererAddresses(&common.Config{}, []string{"orderer0:7050"})
+		require.ErrorContains(t, err, "channel group")
+	})
+}
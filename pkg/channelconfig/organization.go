@@ -0,0 +1,123 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewApplicationOrgGroup creates an application organization config group for the supplied MSP definition, with
+// default Readers, Writers, Admins and Endorsement policies. If the MSP has node organizational units enabled, the
+// policies use the admin, peer and client roles; otherwise they use the member and admin roles.
+func NewApplicationOrgGroup(fabricMSPConfig *msp.FabricMSPConfig) (*common.ConfigGroup, error) {
+	return newOrgGroup(fabricMSPConfig, defaultApplicationOrgPolicyRoles(fabricMSPConfig))
+}
+
+// NewOrdererOrgGroup creates an orderer organization config group for the supplied MSP definition, with default
+// Readers and Writers policies using the member role, and an Admins policy using the admin role.
+func NewOrdererOrgGroup(fabricMSPConfig *msp.FabricMSPConfig) (*common.ConfigGroup, error) {
+	return newOrgGroup(fabricMSPConfig, map[string][]msp.MSPRole_MSPRoleType{
+		ReadersPolicyKey: {msp.MSPRole_MEMBER},
+		WritersPolicyKey: {msp.MSPRole_MEMBER},
+		AdminsPolicyKey:  {msp.MSPRole_ADMIN},
+	})
+}
+
+func newOrgGroup(fabricMSPConfig *msp.FabricMSPConfig, policyRoles map[string][]msp.MSPRole_MSPRoleType) (*common.ConfigGroup, error) {
+	mspID := fabricMSPConfig.GetName()
+	if mspID == "" {
+		return nil, errors.New("no MSP ID supplied")
+	}
+
+	group := &common.ConfigGroup{
+		ModPolicy: AdminsPolicyKey,
+	}
+
+	if err := setMSPConfig(group, fabricMSPConfig); err != nil {
+		return nil, err
+	}
+
+	for key, roles := range policyRoles {
+		policy, err := SignaturePolicyAnyOf(mspID, roles...)
+		if err != nil {
+			return nil, err
+		}
+
+		setPolicy(group, key, policy, AdminsPolicyKey)
+	}
+
+	return group, nil
+}
+
+func defaultApplicationOrgPolicyRoles(fabricMSPConfig *msp.FabricMSPConfig) map[string][]msp.MSPRole_MSPRoleType {
+	if fabricMSPConfig.GetFabricNodeOus().GetEnable() {
+		return map[string][]msp.MSPRole_MSPRoleType{
+			ReadersPolicyKey:     {msp.MSPRole_ADMIN, msp.MSPRole_PEER, msp.MSPRole_CLIENT},
+			WritersPolicyKey:     {msp.MSPRole_ADMIN, msp.MSPRole_CLIENT},
+			AdminsPolicyKey:      {msp.MSPRole_ADMIN},
+			EndorsementPolicyKey: {msp.MSPRole_PEER},
+		}
+	}
+
+	return map[string][]msp.MSPRole_MSPRoleType{
+		ReadersPolicyKey:     {msp.MSPRole_MEMBER},
+		WritersPolicyKey:     {msp.MSPRole_MEMBER},
+		AdminsPolicyKey:      {msp.MSPRole_ADMIN},
+		EndorsementPolicyKey: {msp.MSPRole_MEMBER},
+	}
+}
+
+// AddApplicationOrg returns a copy of the channel config with an organization for the supplied MSP definition added
+// to the application group. The organization is created with default policies, as described for
+// NewApplicationOrgGroup. The supplied channel config is not modified.
+func AddApplicationOrg(channelConfig *common.Config, fabricMSPConfig *msp.FabricMSPConfig) (*common.Config, error) {
+	newOrgGroup, err := NewApplicationOrgGroup(fabricMSPConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	result := proto.Clone(channelConfig).(*common.Config)
+
+	applicationGroup, err := childGroup(result, ApplicationGroupKey)
+	if err != nil {
+		return nil, err
+	}
+
+	mspID := fabricMSPConfig.GetName()
+	if _, err := orgGroup(applicationGroup, mspID); err == nil {
+		return nil, fmt.Errorf("organization already exists with MSP ID: %s", mspID)
+	} else if !errors.Is(err, errOrgNotFound) {
+		return nil, err
+	}
+	if _, exists := applicationGroup.GetGroups()[mspID]; exists {
+		return nil, fmt.Errorf("organization already exists with name: %s", mspID)
+	}
+
+	if applicationGroup.Groups == nil {
+		applicationGroup.Groups = make(map[string]*common.ConfigGroup)
+	}
+	applicationGroup.Groups[mspID] = newOrgGroup
+
+	return result, nil
+}
+
+func setMSPConfig(group *common.ConfigGroup, fabricMSPConfig *msp.FabricMSPConfig) error {
+	fabricMSPConfigBytes, err := proto.Marshal(fabricMSPConfig)
+	if err != nil {
+		return err
+	}
+
+	mspConfig := &msp.MSPConfig{
+		Type:   0, // FABRIC
+		Config: fabricMSPConfigBytes,
+	}
+	return setValue(group, MSPKey, mspConfig, AdminsPolicyKey)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/stretchr/testify/require"
+)
+
+func AssertSignaturePolicyRoles(t *testing.T, configPolicy *common.ConfigPolicy, mspID string, expected ...msp.MSPRole_MSPRoleType) {
+	require.EqualValues(t, common.Policy_SIGNATURE, configPolicy.GetPolicy().GetType(), "policy type")
+
+	policyEnvelope := &common.SignaturePolicyEnvelope{}
+	AssertUnmarshal(t, configPolicy.GetPolicy().GetValue(), policyEnvelope)
+
+	var actual []msp.MSPRole_MSPRoleType
+	for _, principal := range policyEnvelope.GetIdentities() {
+		role := &msp.MSPRole{}
+		AssertUnmarshal(t, principal.GetPrincipal(), role)
+		require.Equal(t, mspID, role.GetMspIdentifier())
+		actual = append(actual, role.GetRole())
+	}
+
+	require.ElementsMatch(t, expected, actual)
+	require.EqualValues(t, 1, policyEnvelope.GetRule().GetNOutOf().GetN(), "required signatures")
+	require.Len(t, policyEnvelope.GetRule().GetNOutOf().GetRules(), len(expected), "signature rules")
+}
+
+func TestNewApplicationOrgGroup(t *testing.T) {
+	t.Run("Missing MSP ID gives error", func(t *testing.T) {
+		_, err := NewApplicationOrgGroup(&msp.FabricMSPConfig{})
+		require.ErrorContains(t, err, "MSP ID")
+	})
+
+	t.Run("Includes MSP definition", func(t *testing.T) {
+		expected := &msp.FabricMSPConfig{
+			Name:      "Org1MSP",
+			RootCerts: [][]byte{[]byte("ROOT_CERT")},
+		}
+
+		orgGroup, err := NewApplicationOrgGroup(expected)
+		require.NoError(t, err)
+
+		actual, err := fabricMSPConfig(orgGroup)
+		require.NoError(t, err)
+		AssertProtoEqual(t, expected, actual)
+		require.Equal(t, AdminsPolicyKey, orgGroup.GetModPolicy())
+	})
+
+	t.Run("Default policies with node OUs enabled use node roles", func(t *testing.T) {
+		orgGroup, err := NewApplicationOrgGroup(&msp.FabricMSPConfig{
+			Name: "Org1MSP",
+			FabricNodeOus: &msp.FabricNodeOUs{
+				Enable: true,
+			},
+		})
+		require.NoError(t, err)
+
+		policies := orgGroup.GetPolicies()
+		AssertSignaturePolicyRoles(t, policies[ReadersPolicyKey], "Org1MSP", msp.MSPRole_ADMIN, msp.MSPRole_PEER, msp.MSPRole_CLIENT)
+		AssertSignaturePolicyRoles(t, policies[WritersPolicyKey], "Org1MSP", msp.MSPRole_ADMIN, msp.MSPRole_CLIENT)
+		AssertSignaturePolicyRoles(t, policies[AdminsPolicyKey], "Org1MSP", msp.MSPRole_ADMIN)
+		AssertSignaturePolicyRoles(t, policies[EndorsementPolicyKey], "Org1MSP", msp.MSPRole_PEER)
+	})
+
+	t.Run("Default policies without node OUs use member roles", func(t *testing.T) {
+		orgGroup, err := NewApplicationOrgGroup(&msp.FabricMSPConfig{
+			Name: "Org1MSP",
+		})
+		require.NoError(t, err)
+
+		policies := orgGroup.GetPolicies()
+		AssertSignaturePolicyRoles(t, policies[ReadersPolicyKey], "Org1MSP", msp.MSPRole_MEMBER)
+		AssertSignaturePolicyRoles(t, policies[WritersPolicyKey], "Org1MSP", msp.MSPRole_MEMBER)
+		AssertSignaturePolicyRoles(t, policies[AdminsPolicyKey], "Org1MSP", msp.MSPRole_ADMIN)
+		AssertSignaturePolicyRoles(t, policies[EndorsementPolicyKey], "Org1MSP", msp.MSPRole_MEMBER)
+	})
+}
+
+func TestAddApplicationOrg(t *testing.T) {
+	t.Run("Adds organization to application group", func(t *testing.T) {
+		actual, err := AddApplicationOrg(NewChannelConfig(t, "Org1MSP"), &msp.FabricMSPConfig{Name: "Org2MSP"})
+		require.NoError(t, err)
+
+		_, err = applicationOrgGroup(actual, "Org2MSP")
+		require.NoError(t, err)
+	})
+
+	t.Run("Existing MSP ID gives error", func(t *testing.T) {
+		_, err := AddApplicationOrg(NewChannelConfig(t, "Org1MSP"), &msp.FabricMSPConfig{Name: "Org1MSP"})
+		require.ErrorContains(t, err, "Org1MSP")
+	})
+
+	t.Run("Invalid MSP config in existing organization gives error", func(t *testing.T) {
+		channelConfig := NewChannelConfig(t, "Org1MSP")
+		orgGroup, err := applicationOrgGroup(channelConfig, "Org1MSP")
+		require.NoError(t, err)
+		orgGroup.GetValues()[MSPKey].Value = []byte("INVALID")
+
+		_, err = AddApplicationOrg(channelConfig, &msp.FabricMSPConfig{Name: "Org2MSP"})
+		require.ErrorContains(t, err, "MSP config")
+	})
+
+	t.Run("Missing application group gives error", func(t *testing.T) {
+		channelConfig := &common.Config{
+			ChannelGroup: &common.ConfigGroup{},
+		}
+
+		_, err := AddApplicationOrg(channelConfig, &msp.FabricMSPConfig{Name: "Org1MSP"})
+		require.ErrorContains(t, err, ApplicationGroupKey)
+	})
+
+	t.Run("Supplied config is not modified", func(t *testing.T) {
+		channelConfig := NewChannelConfig(t, "Org1MSP")
+
+		_, err := AddApplicationOrg(channelConfig, &msp.FabricMSPConfig{Name: "Org2MSP"})
+		require.NoError(t, err)
+
+		AssertProtoEqual(t, NewChannelConfig(t, "Org1MSP"), channelConfig)
+	})
+}
+
+func TestNewOrdererOrgGroup(t *testing.T) {
+	t.Run("Default policies use member and admin roles", func(t *testing.T) {
+		orgGroup, err := NewOrdererOrgGroup(&msp.FabricMSPConfig{
+			Name: "OrdererMSP",
+		})
+		require.NoError(t, err)
+
+		policies := orgGroup.GetPolicies()
+		require.Len(t, policies, 3)
+		AssertSignaturePolicyRoles(t, policies[ReadersPolicyKey], "OrdererMSP", msp.MSPRole_MEMBER)
+		AssertSignaturePolicyRoles(t, policies[WritersPolicyKey], "OrdererMSP", msp.MSPRole_MEMBER)
+		AssertSignaturePolicyRoles(t, policies[AdminsPolicyKey], "OrdererMSP", msp.MSPRole_ADMIN)
+	})
+
+	t.Run("Missing MSP ID gives error", func(t *testing.T) {
+		_, err := NewOrdererOrgGroup(&msp.FabricMSPConfig{})
+		require.ErrorContains(t, err, "MSP ID")
+	})
+}
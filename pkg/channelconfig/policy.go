@@ -0,0 +1,98 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"google.golang.org/protobuf/proto"
+)
+
+// Names of standard policies used within channel configuration.
+const (
+	ReadersPolicyKey              = "Readers"
+	WritersPolicyKey              = "Writers"
+	EndorsementPolicyKey          = "Endorsement"
+	LifecycleEndorsementPolicyKey = "LifecycleEndorsement"
+	BlockValidationPolicyKey      = "BlockValidation"
+)
+
+// SignaturePolicyAnyOf creates a policy that is satisfied by a signature from any of the specified roles within the
+// organization with the specified MSP ID.
+func SignaturePolicyAnyOf(mspID string, roles ...msp.MSPRole_MSPRoleType) (*common.Policy, error) {
+	identities := make([]*msp.MSPPrincipal, 0, len(roles))
+	rules := make([]*common.SignaturePolicy, 0, len(roles))
+
+	for i, role := range roles {
+		principalBytes, err := proto.Marshal(&msp.MSPRole{
+			MspIdentifier: mspID,
+			Role:          role,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		identities = append(identities, &msp.MSPPrincipal{
+			PrincipalClassification: msp.MSPPrincipal_ROLE,
+			Principal:               principalBytes,
+		})
+		rules = append(rules, &common.SignaturePolicy{
+			Type: &common.SignaturePolicy_SignedBy{
+				SignedBy: int32(i),
+			},
+		})
+	}
+
+	policyEnvelope := &common.SignaturePolicyEnvelope{
+		Version: 0,
+		Rule: &common.SignaturePolicy{
+			Type: &common.SignaturePolicy_NOutOf_{
+				NOutOf: &common.SignaturePolicy_NOutOf{
+					N:     1,
+					Rules: rules,
+				},
+			},
+		},
+		Identities: identities,
+	}
+	return newPolicy(common.Policy_SIGNATURE, policyEnvelope)
+}
+
+// ImplicitMetaPolicy creates a policy that is evaluated against the named sub-policy of child config groups.
+func ImplicitMetaPolicy(subPolicy string, rule common.ImplicitMetaPolicy_Rule) (*common.Policy, error) {
+	return newPolicy(common.Policy_IMPLICIT_META, &common.ImplicitMetaPolicy{
+		SubPolicy: subPolicy,
+		Rule:      rule,
+	})
+}
+
+func newPolicy(policyType common.Policy_PolicyType, value proto.Message) (*common.Policy, error) {
+	valueBytes, err := proto.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &common.Policy{
+		Type:  int32(policyType),
+		Value: valueBytes,
+	}
+	return policy, nil
+}
+
+func setPolicy(group *common.ConfigGroup, key string, policy *common.Policy, modPolicy string) {
+	if group.Policies == nil {
+		group.Policies = make(map[string]*common.ConfigPolicy)
+	}
+
+	configPolicy := group.Policies[key]
+	if configPolicy == nil {
+		configPolicy = &common.ConfigPolicy{
+			ModPolicy: modPolicy,
+		}
+		group.Policies[key] = configPolicy
+	}
+	configPolicy.Policy = policy
+}
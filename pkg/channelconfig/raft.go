@@ -0,0 +1,144 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer/etcdraft"
+	"google.golang.org/protobuf/proto"
+)
+
+// RaftConsensusType is the consensus type name used by the etcdraft ordering service.
+const RaftConsensusType = "etcdraft"
+
+// RaftConsenters returns the etcdraft consenters defined in the orderer group of the channel config.
+func RaftConsenters(channelConfig *common.Config) ([]*etcdraft.Consenter, error) {
+	_, metadata, err := raftConfig(channelConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata.GetConsenters(), nil
+}
+
+// AddRaftConsenter returns a copy of the channel config with the supplied etcdraft consenter added. The supplied
+// channel config is not modified. Only one consenter can be added, removed or changed in each config update.
+func AddRaftConsenter(channelConfig *common.Config, consenter *etcdraft.Consenter) (*common.Config, error) {
+	return updateRaftConsenters(channelConfig, func(metadata *etcdraft.ConfigMetadata) error {
+		if index := raftConsenterIndex(metadata, consenter.GetHost(), consenter.GetPort()); index >= 0 {
+			return fmt.Errorf("consenter already exists: %s:%d", consenter.GetHost(), consenter.GetPort())
+		}
+
+		metadata.Consenters = append(metadata.Consenters, proto.Clone(consenter).(*etcdraft.Consenter))
+		return nil
+	})
+}
+
+// RemoveRaftConsenter returns a copy of the channel config with the etcdraft consenter at the specified host and port
+// removed. The supplied channel config is not modified. Only one consenter can be added, removed or changed in each
+// config update.
+func RemoveRaftConsenter(channelConfig *common.Config, host string, port uint32) (*common.Config, error) {
+	return updateRaftConsenters(channelConfig, func(metadata *etcdraft.ConfigMetadata) error {
+		index := raftConsenterIndex(metadata, host, port)
+		if index < 0 {
+			return fmt.Errorf("consenter not found: %s:%d", host, port)
+		}
+		if len(metadata.GetConsenters()) == 1 {
+			return fmt.Errorf("cannot remove the last consenter: %s:%d", host, port)
+		}
+
+		metadata.Consenters = append(metadata.Consenters[:index], metadata.Consenters[index+1:]...)
+		return nil
+	})
+}
+
+// UpdateRaftConsenterTLS returns a copy of the channel config with the client and server TLS certificates of the
+// etcdraft consenter at the specified host and port replaced by the supplied PEM encoded certificates. This is used to
+// rotate consenter TLS certificates before they expire. The supplied channel config is not modified. Only one
+// consenter can be added, removed or changed in each config update.
+func UpdateRaftConsenterTLS(
+	channelConfig *common.Config,
+	host string,
+	port uint32,
+	clientTLSCert []byte,
+	serverTLSCert []byte,
+) (*common.Config, error) {
+	return updateRaftConsenters(channelConfig, func(metadata *etcdraft.ConfigMetadata) error {
+		index := raftConsenterIndex(metadata, host, port)
+		if index < 0 {
+			return fmt.Errorf("consenter not found: %s:%d", host, port)
+		}
+
+		consenter := metadata.Consenters[index]
+		consenter.ClientTlsCert = clientTLSCert
+		consenter.ServerTlsCert = serverTLSCert
+		return nil
+	})
+}
+
+func updateRaftConsenters(channelConfig *common.Config, update func(*etcdraft.ConfigMetadata) error) (*common.Config, error) {
+	result := proto.Clone(channelConfig).(*common.Config)
+
+	consensusType, metadata, err := raftConfig(result)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := update(metadata); err != nil {
+		return nil, err
+	}
+
+	if consensusType.Metadata, err = proto.Marshal(metadata); err != nil {
+		return nil, err
+	}
+
+	ordererGroup, err := childGroup(result, OrdererGroupKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setValue(ordererGroup, ConsensusTypeKey, consensusType, AdminsPolicyKey); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func raftConfig(channelConfig *common.Config) (*orderer.ConsensusType, *etcdraft.ConfigMetadata, error) {
+	ordererGroup, err := childGroup(channelConfig, OrdererGroupKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	consensusType := &orderer.ConsensusType{}
+	if err := getValue(ordererGroup, ConsensusTypeKey, consensusType); err != nil {
+		return nil, nil, err
+	}
+
+	if consensusType.GetType() != RaftConsensusType {
+		return nil, nil, fmt.Errorf("consensus type is %s, not %s", consensusType.GetType(), RaftConsensusType)
+	}
+
+	metadata := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(consensusType.GetMetadata(), metadata); err != nil {
+		return nil, nil, fmt.Errorf("failed to deserialize etcdraft metadata: %w", err)
+	}
+
+	return consensusType, metadata, nil
+}
+
+func raftConsenterIndex(metadata *etcdraft.ConfigMetadata, host string, port uint32) int {
+	for i, consenter := range metadata.GetConsenters() {
+		if consenter.GetHost() == host && consenter.GetPort() == port {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,176 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer/etcdraft"
+	"github.com/stretchr/testify/require"
+)
+
+func NewRaftChannelConfig(t *testing.T, consenters ...*etcdraft.Consenter) *common.Config {
+	metadata := &etcdraft.ConfigMetadata{
+		Consenters: consenters,
+		Options: &etcdraft.Options{
+			TickInterval: "500ms",
+		},
+	}
+	consensusType := &orderer.ConsensusType{
+		Type:     RaftConsensusType,
+		Metadata: AssertMarshal(t, metadata),
+	}
+
+	return NewOrdererChannelConfig(t, consensusType, "OrdererMSP")
+}
+
+func AssertRaftMetadata(t *testing.T, channelConfig *common.Config) *etcdraft.ConfigMetadata {
+	consensusType := AssertConsensusType(t, channelConfig)
+	require.Equal(t, RaftConsensusType, consensusType.GetType())
+
+	result := &etcdraft.ConfigMetadata{}
+	AssertUnmarshal(t, consensusType.GetMetadata(), result)
+
+	return result
+}
+
+func TestRaftConsenters(t *testing.T) {
+	consenter := &etcdraft.Consenter{
+		Host:          "orderer0",
+		Port:          7050,
+		ClientTlsCert: []byte("CLIENT_CERT"),
+		ServerTlsCert: []byte("SERVER_CERT"),
+	}
+
+	t.Run("Returns consenters", func(t *testing.T) {
+		actual, err := RaftConsenters(NewRaftChannelConfig(t, consenter))
+		require.NoError(t, err)
+
+		require.Len(t, actual, 1)
+		AssertProtoEqual(t, consenter, actual[0])
+	})
+
+	t.Run("Non-etcdraft consensus type gives error", func(t *testing.T) {
+		consensusType := &orderer.ConsensusType{
+			Type: "solo",
+		}
+
+		_, err := RaftConsenters(NewOrdererChannelConfig(t, consensusType))
+		require.ErrorContains(t, err, "solo")
+	})
+
+	t.Run("Missing orderer group gives error", func(t *testing.T) {
+		_, err := RaftConsenters(NewChannelConfig(t, "Org1MSP"))
+		require.ErrorContains(t, err, OrdererGroupKey)
+	})
+}
+
+func TestAddRaftConsenter(t *testing.T) {
+	existing := &etcdraft.Consenter{
+		Host: "orderer0",
+		Port: 7050,
+	}
+
+	t.Run("Adds consenter and preserves options", func(t *testing.T) {
+		consenter := &etcdraft.Consenter{
+			Host:          "orderer1",
+			Port:          7050,
+			ClientTlsCert: []byte("CLIENT_CERT"),
+			ServerTlsCert: []byte("SERVER_CERT"),
+		}
+
+		actual, err := AddRaftConsenter(NewRaftChannelConfig(t, existing), consenter)
+		require.NoError(t, err)
+
+		metadata := AssertRaftMetadata(t, actual)
+		require.Len(t, metadata.GetConsenters(), 2)
+		AssertProtoEqual(t, consenter, metadata.GetConsenters()[1])
+		require.Equal(t, "500ms", metadata.GetOptions().GetTickInterval())
+	})
+
+	t.Run("Duplicate consenter gives error", func(t *testing.T) {
+		_, err := AddRaftConsenter(NewRaftChannelConfig(t, existing), existing)
+		require.ErrorContains(t, err, "orderer0:7050")
+	})
+
+	t.Run("Supplied config is not modified", func(t *testing.T) {
+		channelConfig := NewRaftChannelConfig(t, existing)
+
+		_, err := AddRaftConsenter(channelConfig, &etcdraft.Consenter{Host: "orderer1", Port: 7050})
+		require.NoError(t, err)
+
+		require.Len(t, AssertRaftMetadata(t, channelConfig).GetConsenters(), 1)
+	})
+}
+
+func TestRemoveRaftConsenter(t *testing.T) {
+	consenters := []*etcdraft.Consenter{
+		{Host: "orderer0", Port: 7050},
+		{Host: "orderer1", Port: 7050},
+	}
+
+	t.Run("Removes consenter", func(t *testing.T) {
+		actual, err := RemoveRaftConsenter(NewRaftChannelConfig(t, consenters...), "orderer0", 7050)
+		require.NoError(t, err)
+
+		metadata := AssertRaftMetadata(t, actual)
+		require.Len(t, metadata.GetConsenters(), 1)
+		require.Equal(t, "orderer1", metadata.GetConsenters()[0].GetHost())
+	})
+
+	t.Run("Unknown consenter gives error", func(t *testing.T) {
+		_, err := RemoveRaftConsenter(NewRaftChannelConfig(t, consenters...), "orderer0", 8050)
+		require.ErrorContains(t, err, "orderer0:8050")
+	})
+
+	t.Run("Last consenter gives error", func(t *testing.T) {
+		_, err := RemoveRaftConsenter(NewRaftChannelConfig(t, consenters[0]), "orderer0", 7050)
+		require.ErrorContains(t, err, "last")
+	})
+}
+
+func TestUpdateRaftConsenterTLS(t *testing.T) {
+	consenter := &etcdraft.Consenter{
+		Host:          "orderer0",
+		Port:          7050,
+		ClientTlsCert: []byte("OLD_CLIENT_CERT"),
+		ServerTlsCert: []byte("OLD_SERVER_CERT"),
+	}
+
+	t.Run("Replaces TLS certificates", func(t *testing.T) {
+		actual, err := UpdateRaftConsenterTLS(NewRaftChannelConfig(t, consenter), "orderer0", 7050, []byte("NEW_CLIENT_CERT"), []byte("NEW_SERVER_CERT"))
+		require.NoError(t, err)
+
+		expected := &etcdraft.Consenter{
+			Host:          "orderer0",
+			Port:          7050,
+			ClientTlsCert: []byte("NEW_CLIENT_CERT"),
+			ServerTlsCert: []byte("NEW_SERVER_CERT"),
+		}
+		AssertProtoEqual(t, expected, AssertRaftMetadata(t, actual).GetConsenters()[0])
+	})
+
+	t.Run("Unknown consenter gives error", func(t *testing.T) {
+		_, err := UpdateRaftConsenterTLS(NewRaftChannelConfig(t, consenter), "orderer1", 7050, nil, nil)
+		require.ErrorContains(t, err, "orderer1:7050")
+	})
+
+	t.Run("Config update includes only consensus type value", func(t *testing.T) {
+		original := NewRaftChannelConfig(t, consenter)
+
+		updated, err := UpdateRaftConsenterTLS(original, "orderer0", 7050, []byte("NEW_CLIENT_CERT"), []byte("NEW_SERVER_CERT"))
+		require.NoError(t, err)
+
+		configUpdate, err := ComputeUpdate("CHANNEL", original, updated)
+		require.NoError(t, err)
+
+		writeOrderer := configUpdate.GetWriteSet().GetGroups()[OrdererGroupKey]
+		require.Len(t, writeOrderer.GetValues(), 1)
+		require.EqualValues(t, 1, writeOrderer.GetValues()[ConsensusTypeKey].GetVersion())
+	})
+}
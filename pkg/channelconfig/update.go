@@ -0,0 +1,349 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/envelope"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"google.golang.org/protobuf/proto"
+)
+
+// ComputeUpdate computes the config update required to move a channel from the original config to the updated
+// config. The read set contains the versions of all config elements on which the update depends, and the write set
+// contains the modified elements with incremented versions.
+func ComputeUpdate(channelName string, original *common.Config, updated *common.Config) (*common.ConfigUpdate, error) {
+	if original.GetChannelGroup() == nil {
+		return nil, errors.New("no channel group included for original config")
+	}
+	if updated.GetChannelGroup() == nil {
+		return nil, errors.New("no channel group included for updated config")
+	}
+
+	readSet, writeSet, groupUpdated := computeGroupUpdate(original.GetChannelGroup(), updated.GetChannelGroup())
+	if !groupUpdated {
+		return nil, errors.New("no differences detected between original and updated config")
+	}
+
+	configUpdate := &common.ConfigUpdate{
+		ChannelId: channelName,
+		ReadSet:   readSet,
+		WriteSet:  writeSet,
+	}
+	return configUpdate, nil
+}
+
+// NewUpdateEnvelope computes the config update between the original and updated config, and creates a config update
+// envelope signed by the supplied identity. The envelope is ready to be submitted to an ordering service, or to have
+// further signatures added using SignUpdateEnvelope if the channel modification policy requires them.
+func NewUpdateEnvelope(
+	ctx context.Context,
+	signingID identity.SigningIdentity,
+	channelName string,
+	original *common.Config,
+	updated *common.Config,
+) (*common.Envelope, error) {
+	configUpdate, err := ComputeUpdate(channelName, original, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUpdateEnvelopeFromUpdate(ctx, signingID, configUpdate)
+}
+
+// NewUpdateEnvelopeFromUpdate creates a config update envelope for the supplied config update, signed by the supplied
+// identity.
+func NewUpdateEnvelopeFromUpdate(
+	ctx context.Context,
+	signingID identity.SigningIdentity,
+	configUpdate *common.ConfigUpdate,
+) (*common.Envelope, error) {
+	configUpdateBytes, err := proto.Marshal(configUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	configSignature, err := newConfigSignature(ctx, signingID, configUpdateBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	configUpdateEnvelope := &common.ConfigUpdateEnvelope{
+		ConfigUpdate: configUpdateBytes,
+		Signatures:   []*common.ConfigSignature{configSignature},
+	}
+
+	channelHeader := envelope.NewChannelHeader(common.HeaderType_CONFIG_UPDATE, configUpdate.GetChannelId())
+	return envelope.NewSigned(ctx, signingID, channelHeader, configUpdateEnvelope)
+}
+
+// SignUpdateEnvelope adds the signature of the supplied identity to a config update envelope. The resulting envelope
+// is signed by the supplied identity so it can be submitted to an ordering service by the last signer.
+func SignUpdateEnvelope(
+	ctx context.Context,
+	signingID identity.SigningIdentity,
+	updateEnvelope *common.Envelope,
+) (*common.Envelope, error) {
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(updateEnvelope.GetPayload(), payload); err != nil {
+		return nil, err
+	}
+
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), channelHeader); err != nil {
+		return nil, err
+	}
+	if channelHeader.GetType() != int32(common.HeaderType_CONFIG_UPDATE) {
+		return nil, errors.New("envelope is not a config update")
+	}
+
+	configUpdateEnvelope := &common.ConfigUpdateEnvelope{}
+	if err := proto.Unmarshal(payload.GetData(), configUpdateEnvelope); err != nil {
+		return nil, err
+	}
+
+	configSignature, err := newConfigSignature(ctx, signingID, configUpdateEnvelope.GetConfigUpdate())
+	if err != nil {
+		return nil, err
+	}
+	configUpdateEnvelope.Signatures = append(configUpdateEnvelope.Signatures, configSignature)
+
+	return envelope.NewSigned(ctx, signingID, channelHeader, configUpdateEnvelope)
+}
+
+func newConfigSignature(ctx context.Context, signingID identity.SigningIdentity, configUpdateBytes []byte) (*common.ConfigSignature, error) {
+	signatureHeader, err := envelope.NewSignatureHeader(signingID)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureHeaderBytes, err := proto.Marshal(signatureHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	message := make([]byte, 0, len(signatureHeaderBytes)+len(configUpdateBytes))
+	message = append(message, signatureHeaderBytes...)
+	message = append(message, configUpdateBytes...)
+
+	signature, err := identity.SignContext(ctx, signingID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	configSignature := &common.ConfigSignature{
+		SignatureHeader: signatureHeaderBytes,
+		Signature:       signature,
+	}
+	return configSignature, nil
+}
+
+func computePoliciesMapUpdate(
+	original map[string]*common.ConfigPolicy,
+	updated map[string]*common.ConfigPolicy,
+) (readSet, writeSet, sameSet map[string]*common.ConfigPolicy, updatedMembers bool) {
+	readSet = make(map[string]*common.ConfigPolicy)
+	writeSet = make(map[string]*common.ConfigPolicy)
+	sameSet = make(map[string]*common.ConfigPolicy)
+
+	for policyName, originalPolicy := range original {
+		updatedPolicy, ok := updated[policyName]
+		if !ok {
+			updatedMembers = true
+			continue
+		}
+
+		if originalPolicy.GetModPolicy() == updatedPolicy.GetModPolicy() && proto.Equal(originalPolicy.GetPolicy(), updatedPolicy.GetPolicy()) {
+			sameSet[policyName] = &common.ConfigPolicy{
+				Version: originalPolicy.GetVersion(),
+			}
+			continue
+		}
+
+		writeSet[policyName] = &common.ConfigPolicy{
+			Version:   originalPolicy.GetVersion() + 1,
+			ModPolicy: updatedPolicy.GetModPolicy(),
+			Policy:    updatedPolicy.GetPolicy(),
+		}
+	}
+
+	for policyName, updatedPolicy := range updated {
+		if _, ok := original[policyName]; ok {
+			continue
+		}
+
+		updatedMembers = true
+		writeSet[policyName] = &common.ConfigPolicy{
+			Version:   0,
+			ModPolicy: updatedPolicy.GetModPolicy(),
+			Policy:    updatedPolicy.GetPolicy(),
+		}
+	}
+
+	return
+}
+
+func computeValuesMapUpdate(
+	original map[string]*common.ConfigValue,
+	updated map[string]*common.ConfigValue,
+) (readSet, writeSet, sameSet map[string]*common.ConfigValue, updatedMembers bool) {
+	readSet = make(map[string]*common.ConfigValue)
+	writeSet = make(map[string]*common.ConfigValue)
+	sameSet = make(map[string]*common.ConfigValue)
+
+	for valueName, originalValue := range original {
+		updatedValue, ok := updated[valueName]
+		if !ok {
+			updatedMembers = true
+			continue
+		}
+
+		if originalValue.GetModPolicy() == updatedValue.GetModPolicy() && bytes.Equal(originalValue.GetValue(), updatedValue.GetValue()) {
+			sameSet[valueName] = &common.ConfigValue{
+				Version: originalValue.GetVersion(),
+			}
+			continue
+		}
+
+		writeSet[valueName] = &common.ConfigValue{
+			Version:   originalValue.GetVersion() + 1,
+			ModPolicy: updatedValue.GetModPolicy(),
+			Value:     updatedValue.GetValue(),
+		}
+	}
+
+	for valueName, updatedValue := range updated {
+		if _, ok := original[valueName]; ok {
+			continue
+		}
+
+		updatedMembers = true
+		writeSet[valueName] = &common.ConfigValue{
+			Version:   0,
+			ModPolicy: updatedValue.GetModPolicy(),
+			Value:     updatedValue.GetValue(),
+		}
+	}
+
+	return
+}
+
+func computeGroupsMapUpdate(
+	original map[string]*common.ConfigGroup,
+	updated map[string]*common.ConfigGroup,
+) (readSet, writeSet, sameSet map[string]*common.ConfigGroup, updatedMembers bool) {
+	readSet = make(map[string]*common.ConfigGroup)
+	writeSet = make(map[string]*common.ConfigGroup)
+	sameSet = make(map[string]*common.ConfigGroup)
+
+	for groupName, originalGroup := range original {
+		updatedGroup, ok := updated[groupName]
+		if !ok {
+			updatedMembers = true
+			continue
+		}
+
+		groupReadSet, groupWriteSet, groupUpdated := computeGroupUpdate(originalGroup, updatedGroup)
+		if !groupUpdated {
+			sameSet[groupName] = groupReadSet
+			continue
+		}
+
+		readSet[groupName] = groupReadSet
+		writeSet[groupName] = groupWriteSet
+	}
+
+	for groupName, updatedGroup := range updated {
+		if _, ok := original[groupName]; ok {
+			continue
+		}
+
+		updatedMembers = true
+		_, groupWriteSet, _ := computeGroupUpdate(&common.ConfigGroup{}, updatedGroup)
+		writeSet[groupName] = &common.ConfigGroup{
+			Version:   0,
+			ModPolicy: updatedGroup.GetModPolicy(),
+			Policies:  groupWriteSet.GetPolicies(),
+			Values:    groupWriteSet.GetValues(),
+			Groups:    groupWriteSet.GetGroups(),
+		}
+	}
+
+	return
+}
+
+func computeGroupUpdate(original *common.ConfigGroup, updated *common.ConfigGroup) (readSet, writeSet *common.ConfigGroup, updatedGroup bool) {
+	readSetPolicies, writeSetPolicies, sameSetPolicies, policiesMembersUpdated := computePoliciesMapUpdate(original.GetPolicies(), updated.GetPolicies())
+	readSetValues, writeSetValues, sameSetValues, valuesMembersUpdated := computeValuesMapUpdate(original.GetValues(), updated.GetValues())
+	readSetGroups, writeSetGroups, sameSetGroups, groupsMembersUpdated := computeGroupsMapUpdate(original.GetGroups(), updated.GetGroups())
+
+	membersUpdated := policiesMembersUpdated || valuesMembersUpdated || groupsMembersUpdated
+	if !membersUpdated && original.GetModPolicy() == updated.GetModPolicy() {
+		// No members were added or removed, and the group mod policy is unchanged
+		if len(readSetPolicies) == 0 &&
+			len(writeSetPolicies) == 0 &&
+			len(readSetValues) == 0 &&
+			len(writeSetValues) == 0 &&
+			len(readSetGroups) == 0 &&
+			len(writeSetGroups) == 0 {
+			readSet = &common.ConfigGroup{
+				Version: original.GetVersion(),
+			}
+			writeSet = &common.ConfigGroup{
+				Version: original.GetVersion(),
+			}
+			return readSet, writeSet, false
+		}
+
+		readSet = &common.ConfigGroup{
+			Version:  original.GetVersion(),
+			Policies: readSetPolicies,
+			Values:   readSetValues,
+			Groups:   readSetGroups,
+		}
+		writeSet = &common.ConfigGroup{
+			Version:  original.GetVersion(),
+			Policies: writeSetPolicies,
+			Values:   writeSetValues,
+			Groups:   writeSetGroups,
+		}
+		return readSet, writeSet, true
+	}
+
+	for name, samePolicy := range sameSetPolicies {
+		readSetPolicies[name] = samePolicy
+		writeSetPolicies[name] = samePolicy
+	}
+
+	for name, sameValue := range sameSetValues {
+		readSetValues[name] = sameValue
+		writeSetValues[name] = sameValue
+	}
+
+	for name, sameGroup := range sameSetGroups {
+		readSetGroups[name] = sameGroup
+		writeSetGroups[name] = sameGroup
+	}
+
+	readSet = &common.ConfigGroup{
+		Version:  original.GetVersion(),
+		Policies: readSetPolicies,
+		Values:   readSetValues,
+		Groups:   readSetGroups,
+	}
+	writeSet = &common.ConfigGroup{
+		Version:   original.GetVersion() + 1,
+		Policies:  writeSetPolicies,
+		Values:    writeSetValues,
+		Groups:    writeSetGroups,
+		ModPolicy: updated.GetModPolicy(),
+	}
+	return readSet, writeSet, true
+}
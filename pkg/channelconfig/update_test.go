@@ -0,0 +1,120 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channelconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestComputeUpdate(t *testing.T) {
+	t.Run("Missing original channel group gives error", func(t *testing.T) {
+		_, err := ComputeUpdate("CHANNEL", &common.Config{}, NewChannelConfig(t, "Org1MSP"))
+		require.ErrorContains(t, err, "original")
+	})
+
+	t.Run("Missing updated channel group gives error", func(t *testing.T) {
+		_, err := ComputeUpdate("CHANNEL", NewChannelConfig(t, "Org1MSP"), &common.Config{})
+		require.ErrorContains(t, err, "updated")
+	})
+
+	t.Run("Identical configs give error", func(t *testing.T) {
+		_, err := ComputeUpdate("CHANNEL", NewChannelConfig(t, "Org1MSP"), NewChannelConfig(t, "Org1MSP"))
+		require.ErrorContains(t, err, "no differences")
+	})
+
+	t.Run("Modified value increments version in write set", func(t *testing.T) {
+		original := NewChannelConfig(t, "Org1MSP", "Org2MSP")
+		original.ChannelGroup.Groups[ApplicationGroupKey].Groups["Org1MSP"].Values["VALUE"] = &common.ConfigValue{
+			Version:   3,
+			Value:     []byte("ORIGINAL"),
+			ModPolicy: AdminsPolicyKey,
+		}
+		updated := proto.Clone(original).(*common.Config)
+		updated.ChannelGroup.Groups[ApplicationGroupKey].Groups["Org1MSP"].Values["VALUE"].Value = []byte("UPDATED")
+
+		actual, err := ComputeUpdate("CHANNEL", original, updated)
+		require.NoError(t, err)
+
+		require.Equal(t, "CHANNEL", actual.GetChannelId())
+
+		writeValue := actual.GetWriteSet().GetGroups()[ApplicationGroupKey].GetGroups()["Org1MSP"].GetValues()["VALUE"]
+		AssertProtoEqual(t, &common.ConfigValue{
+			Version:   4,
+			Value:     []byte("UPDATED"),
+			ModPolicy: AdminsPolicyKey,
+		}, writeValue)
+
+		require.NotContains(t, actual.GetWriteSet().GetGroups()[ApplicationGroupKey].GetGroups(), "Org2MSP")
+		require.NotContains(t, actual.GetReadSet().GetGroups()[ApplicationGroupKey].GetGroups()["Org1MSP"].GetValues(), "VALUE")
+	})
+
+	t.Run("Added group increments parent version and includes existing members in read set", func(t *testing.T) {
+		original := NewChannelConfig(t, "Org1MSP")
+		updated := NewChannelConfig(t, "Org1MSP", "Org2MSP")
+
+		actual, err := ComputeUpdate("CHANNEL", original, updated)
+		require.NoError(t, err)
+
+		readApplication := actual.GetReadSet().GetGroups()[ApplicationGroupKey]
+		require.Contains(t, readApplication.GetGroups(), "Org1MSP")
+		require.NotContains(t, readApplication.GetGroups(), "Org2MSP")
+
+		writeApplication := actual.GetWriteSet().GetGroups()[ApplicationGroupKey]
+		require.EqualValues(t, 1, writeApplication.GetVersion(), "application group version")
+		require.Contains(t, writeApplication.GetGroups(), "Org2MSP")
+		require.EqualValues(t, 0, writeApplication.GetGroups()["Org2MSP"].GetVersion(), "new group version")
+	})
+}
+
+func TestSignUpdateEnvelope(t *testing.T) {
+	t.Run("Adds signature to existing signatures", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		original := NewChannelConfig(t, "Org1MSP")
+		updated := NewChannelConfig(t, "Org1MSP", "Org2MSP")
+
+		envelope, err := NewUpdateEnvelope(ctx, NewSigningIdentity(controller, []byte("FIRST")), "CHANNEL", original, updated)
+		require.NoError(t, err)
+
+		actual, err := SignUpdateEnvelope(ctx, NewSigningIdentity(controller, []byte("SECOND")), envelope)
+		require.NoError(t, err)
+
+		require.EqualValues(t, []byte("SECOND"), actual.GetSignature(), "envelope signature")
+
+		_, configUpdateEnvelope, _ := AssertUnmarshalConfigUpdate(t, actual)
+		signatures := configUpdateEnvelope.GetSignatures()
+		require.Len(t, signatures, 2)
+		require.EqualValues(t, []byte("FIRST"), signatures[0].GetSignature())
+		require.EqualValues(t, []byte("SECOND"), signatures[1].GetSignature())
+	})
+
+	t.Run("Non-config update envelope gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		channelHeader := &common.ChannelHeader{
+			Type: int32(common.HeaderType_ENDORSER_TRANSACTION),
+		}
+		payload := &common.Payload{
+			Header: &common.Header{
+				ChannelHeader: AssertMarshal(t, channelHeader),
+			},
+		}
+		envelope := &common.Envelope{
+			Payload: AssertMarshal(t, payload),
+		}
+
+		_, err := SignUpdateEnvelope(ctx, NewSigningIdentity(controller, nil), envelope)
+		require.ErrorContains(t, err, "config update")
+	})
+}
@@ -0,0 +1,212 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package commitstatus provides support for waiting until a transaction is committed by a peer, and for obtaining the
+// validation code assigned to the transaction by the peer.
+package commitstatus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	admincommon "github.com/bestbeforetoday/fabric-admin/internal/common"
+	"github.com/bestbeforetoday/fabric-admin/internal/envelope"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+)
+
+// Status of a committed transaction.
+type Status struct {
+	TransactionID string
+	BlockNumber   uint64
+	Code          peer.TxValidationCode
+}
+
+// Successful returns true if the transaction was committed as valid, and its updates were applied to the ledger.
+func (s *Status) Successful() bool {
+	return s.Code == peer.TxValidationCode_VALID
+}
+
+// Wait until a transaction is committed by a peer, and return its commit status. Filtered blocks are read from the
+// peer, starting at the block specified using WithStartBlock, which is required. Wait continues until the transaction
+// is found or the context is done.
+func Wait(ctx context.Context, signingID identity.SigningIdentity, transactionID string, options ...Option) (*Status, error) {
+	waitCommand := &command{
+		transactionID: transactionID,
+	}
+
+	if err := admincommon.ApplyOptions(waitCommand, options...); err != nil {
+		return nil, err
+	}
+
+	return waitCommand.run(ctx, signingID)
+}
+
+type command struct {
+	grpcClient    peer.DeliverClient
+	grpcOptions   []grpc.CallOption
+	channelName   string
+	transactionID string
+	startBlock    *uint64
+	tlsCertHash   []byte
+}
+
+func (c *command) run(ctx context.Context, signingID identity.SigningIdentity) (*Status, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	seekEnvelope, err := c.newSeekEnvelope(ctx, signingID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.grpcClient.DeliverFiltered(ctx, c.grpcOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = stream.Send(seekEnvelope); err != nil {
+		return nil, err
+	}
+
+	if err = stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	return c.receiveStatus(stream)
+}
+
+func (c *command) validate() error {
+	if c.grpcClient == nil {
+		return errors.New("no gRPC client supplied")
+	}
+	if c.channelName == "" {
+		return errors.New("no channel name supplied")
+	}
+	if c.transactionID == "" {
+		return errors.New("no transaction ID supplied")
+	}
+	if c.startBlock == nil {
+		return errors.New("no start block supplied")
+	}
+
+	return nil
+}
+
+func (c *command) newSeekEnvelope(ctx context.Context, signingID identity.SigningIdentity) (*common.Envelope, error) {
+	seekInfo := &orderer.SeekInfo{
+		Start: c.startPosition(),
+		Stop: &orderer.SeekPosition{
+			Type: &orderer.SeekPosition_Specified{
+				Specified: &orderer.SeekSpecified{
+					Number: math.MaxUint64,
+				},
+			},
+		},
+		Behavior: orderer.SeekInfo_BLOCK_UNTIL_READY,
+	}
+
+	channelHeader := envelope.NewChannelHeader(common.HeaderType_DELIVER_SEEK_INFO, c.channelName)
+	channelHeader.TlsCertHash = c.tlsCertHash
+	return envelope.NewSigned(ctx, signingID, channelHeader, seekInfo)
+}
+
+func (c *command) startPosition() *orderer.SeekPosition {
+	return &orderer.SeekPosition{
+		Type: &orderer.SeekPosition_Specified{
+			Specified: &orderer.SeekSpecified{
+				Number: *c.startBlock,
+			},
+		},
+	}
+}
+
+func (c *command) receiveStatus(stream peer.Deliver_DeliverFilteredClient) (*Status, error) {
+	for {
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive filtered block: %w", err)
+		}
+
+		switch content := response.GetType().(type) {
+		case *peer.DeliverResponse_FilteredBlock:
+			if status := c.findTransaction(content.FilteredBlock); status != nil {
+				return status, nil
+			}
+		case *peer.DeliverResponse_Status:
+			return nil, fmt.Errorf("block delivery ended with status %d (%s) before transaction %s was committed",
+				content.Status, content.Status.String(), c.transactionID)
+		}
+	}
+}
+
+func (c *command) findTransaction(block *peer.FilteredBlock) *Status {
+	for _, transaction := range block.GetFilteredTransactions() {
+		if transaction.GetTxid() == c.transactionID {
+			return &Status{
+				TransactionID: c.transactionID,
+				BlockNumber:   block.GetNumber(),
+				Code:          transaction.GetTxValidationCode(),
+			}
+		}
+	}
+
+	return nil
+}
+
+type Option = func(*command) error
+
+// WithClientConnection uses the supplied gRPC client connection to a peer. This should be shared by all commands
+// connecting to the same network node.
+func WithClientConnection(clientConnection grpc.ClientConnInterface) Option {
+	return func(c *command) error {
+		c.grpcClient = peer.NewDeliverClient(clientConnection)
+		return nil
+	}
+}
+
+// WithChannel specifies the name of the channel to which the transaction was submitted.
+func WithChannel(channelName string) Option {
+	return func(c *command) error {
+		c.channelName = channelName
+		return nil
+	}
+}
+
+// WithStartBlock specifies the block number from which to start looking for the transaction. This option is required,
+// and should be a block number no later than the ledger height at the time the transaction was submitted, to avoid
+// missing the transaction commit.
+func WithStartBlock(blockNumber uint64) Option {
+	return func(c *command) error {
+		c.startBlock = &blockNumber
+		return nil
+	}
+}
+
+// WithCallOptions specifies the gRPC call options to be used.
+func WithCallOptions(options ...grpc.CallOption) Option {
+	return func(c *command) error {
+		c.grpcOptions = append(c.grpcOptions, options...)
+		return nil
+	}
+}
+
+// WithTLSCertHash specifies the hash of the client TLS certificate used to connect to the peer. This is required if the
+// peer checks that block delivery requests are bound to the mutual TLS connection on which they are received.
+func WithTLSCertHash(hash []byte) Option {
+	return func(c *command) error {
+		c.tlsCertHash = hash
+		return nil
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package commitstatus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:generate mockgen -destination ./deliver_mock_test.go -package ${GOPACKAGE} github.com/hyperledger/fabric-protos-go-apiv2/peer DeliverClient,Deliver_DeliverFilteredClient
+//go:generate mockgen -destination ./signingidentity_mock_test.go -package ${GOPACKAGE} github.com/bestbeforetoday/fabric-admin/pkg/identity SigningIdentity
+
+func WithDeliverClient(grpcClient peer.DeliverClient) Option {
+	return func(c *command) error {
+		c.grpcClient = grpcClient
+		return nil
+	}
+}
+
+func NewSigningIdentity(controller *gomock.Controller, signature []byte) *MockSigningIdentity {
+	mockIdentity := NewMockSigningIdentity(controller)
+	mockIdentity.EXPECT().MspID().AnyTimes()
+	mockIdentity.EXPECT().Credentials().AnyTimes()
+	mockIdentity.EXPECT().Sign(gomock.Any()).Return(signature, nil).AnyTimes()
+
+	return mockIdentity
+}
+
+func NewFilteredBlockResponse(blockNumber uint64, transactions map[string]peer.TxValidationCode) *peer.DeliverResponse {
+	block := &peer.FilteredBlock{
+		ChannelId: "CHANNEL",
+		Number:    blockNumber,
+	}
+	for transactionID, code := range transactions {
+		block.FilteredTransactions = append(block.FilteredTransactions, &peer.FilteredTransaction{
+			Txid:             transactionID,
+			Type:             common.HeaderType_ENDORSER_TRANSACTION,
+			TxValidationCode: code,
+		})
+	}
+
+	return &peer.DeliverResponse{
+		Type: &peer.DeliverResponse_FilteredBlock{
+			FilteredBlock: block,
+		},
+	}
+}
+
+// NewDeliverClient returns a mock deliver client whose stream returns the supplied responses, and captures the
+// envelope sent to the stream.
+func NewDeliverClient(controller *gomock.Controller, sent *common.Envelope, responses ...*peer.DeliverResponse) *MockDeliverClient {
+	mockStream := NewMockDeliver_DeliverFilteredClient(controller)
+	mockStream.EXPECT().Send(gomock.Any()).
+		Do(func(in *common.Envelope) {
+			proto.Merge(sent, in)
+		}).
+		Return(nil)
+	mockStream.EXPECT().CloseSend().Return(nil).AnyTimes()
+
+	calls := make([]*gomock.Call, 0, len(responses)+1)
+	for _, response := range responses {
+		calls = append(calls, mockStream.EXPECT().Recv().Return(response, nil))
+	}
+	calls = append(calls, mockStream.EXPECT().Recv().Return(nil, errors.New("STREAM_END")).AnyTimes())
+	gomock.InOrder(calls...)
+
+	mockDeliver := NewMockDeliverClient(controller)
+	mockDeliver.EXPECT().DeliverFiltered(gomock.Any(), gomock.Any()).Return(mockStream, nil)
+
+	return mockDeliver
+}
+
+// AssertUnmarshal ensures that a protobuf is umarshaled without error
+func AssertUnmarshal(t *testing.T, b []byte, m protoreflect.ProtoMessage) {
+	err := proto.Unmarshal(b, m)
+	require.NoError(t, err)
+}
+
+// AssertProtoEqual ensures an expected protobuf message matches an actual message
+func AssertProtoEqual(t *testing.T, expected protoreflect.ProtoMessage, actual protoreflect.ProtoMessage) {
+	require.True(t, proto.Equal(expected, actual), "Expected %v, got %v", expected, actual)
+}
+
+// AssertUnmarshalSeekInfo ensures that seek info is unmarshaled from an envelope without error
+func AssertUnmarshalSeekInfo(t *testing.T, envelope *common.Envelope) (*common.ChannelHeader, *orderer.SeekInfo) {
+	payload := &common.Payload{}
+	AssertUnmarshal(t, envelope.GetPayload(), payload)
+
+	channelHeader := &common.ChannelHeader{}
+	AssertUnmarshal(t, payload.GetHeader().GetChannelHeader(), channelHeader)
+
+	seekInfo := &orderer.SeekInfo{}
+	AssertUnmarshal(t, payload.GetData(), seekInfo)
+
+	return channelHeader, seekInfo
+}
+
+func TestWait(t *testing.T) {
+	t.Run("Missing gRPC connection gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Wait(ctx, NewSigningIdentity(controller, nil), "TX_ID", WithChannel("CHANNEL"))
+		require.ErrorContains(t, err, "gRPC")
+	})
+
+	t.Run("Missing channel name gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Wait(ctx, NewSigningIdentity(controller, nil), "TX_ID", WithDeliverClient(NewMockDeliverClient(controller)))
+		require.ErrorContains(t, err, "channel")
+	})
+
+	t.Run("Missing transaction ID gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Wait(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			"",
+			WithDeliverClient(NewMockDeliverClient(controller)),
+			WithChannel("CHANNEL"),
+			WithStartBlock(1),
+		)
+		require.ErrorContains(t, err, "transaction ID")
+	})
+
+	t.Run("Missing start block gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := Wait(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			"TX_ID",
+			WithDeliverClient(NewMockDeliverClient(controller)),
+			WithChannel("CHANNEL"),
+		)
+		require.ErrorContains(t, err, "start block")
+	})
+
+	t.Run("Sends signed seek info", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		sent := &common.Envelope{}
+		mockDeliver := NewDeliverClient(controller, sent, NewFilteredBlockResponse(1, map[string]peer.TxValidationCode{
+			"TX_ID": peer.TxValidationCode_VALID,
+		}))
+
+		_, err := Wait(
+			ctx,
+			NewSigningIdentity(controller, []byte("SIGNATURE")),
+			"TX_ID",
+			WithDeliverClient(mockDeliver),
+			WithChannel("CHANNEL"),
+			WithStartBlock(1),
+		)
+		require.NoError(t, err)
+
+		require.Equal(t, []byte("SIGNATURE"), sent.GetSignature())
+
+		channelHeader, seekInfo := AssertUnmarshalSeekInfo(t, sent)
+		require.EqualValues(t, common.HeaderType_DELIVER_SEEK_INFO, channelHeader.GetType(), "header type")
+		require.Equal(t, "CHANNEL", channelHeader.GetChannelId(), "channel")
+		require.EqualValues(t, 1, seekInfo.GetStart().GetSpecified().GetNumber(), "start position")
+		require.Equal(t, orderer.SeekInfo_BLOCK_UNTIL_READY, seekInfo.GetBehavior(), "behavior")
+	})
+
+	t.Run("Seek info starts from specified block", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		sent := &common.Envelope{}
+		mockDeliver := NewDeliverClient(controller, sent, NewFilteredBlockResponse(42, map[string]peer.TxValidationCode{
+			"TX_ID": peer.TxValidationCode_VALID,
+		}))
+
+		_, err := Wait(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			"TX_ID",
+			WithDeliverClient(mockDeliver),
+			WithChannel("CHANNEL"),
+			WithStartBlock(42),
+		)
+		require.NoError(t, err)
+
+		_, seekInfo := AssertUnmarshalSeekInfo(t, sent)
+		expected := &orderer.SeekPosition{
+			Type: &orderer.SeekPosition_Specified{
+				Specified: &orderer.SeekSpecified{Number: 42},
+			},
+		}
+		AssertProtoEqual(t, expected, seekInfo.GetStart())
+	})
+
+	t.Run("Seek info channel header includes TLS certificate hash", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		sent := &common.Envelope{}
+		mockDeliver := NewDeliverClient(controller, sent, NewFilteredBlockResponse(1, map[string]peer.TxValidationCode{
+			"TX_ID": peer.TxValidationCode_VALID,
+		}))
+
+		_, err := Wait(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			"TX_ID",
+			WithDeliverClient(mockDeliver),
+			WithChannel("CHANNEL"),
+			WithStartBlock(1),
+			WithTLSCertHash([]byte("TLS_CERT_HASH")),
+		)
+		require.NoError(t, err)
+
+		channelHeader, _ := AssertUnmarshalSeekInfo(t, sent)
+		require.Equal(t, []byte("TLS_CERT_HASH"), channelHeader.GetTlsCertHash())
+	})
+
+	for _, code := range []peer.TxValidationCode{
+		peer.TxValidationCode_VALID,
+		peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE,
+		peer.TxValidationCode_MVCC_READ_CONFLICT,
+	} {
+		code := code
+		t.Run("Returns validation code: "+code.String(), func(t *testing.T) {
+			controller, ctx := gomock.WithContext(context.Background(), t)
+			defer controller.Finish()
+
+			mockDeliver := NewDeliverClient(
+				controller,
+				&common.Envelope{},
+				NewFilteredBlockResponse(1, map[string]peer.TxValidationCode{
+					"OTHER_TX_ID": peer.TxValidationCode_VALID,
+				}),
+				NewFilteredBlockResponse(2, map[string]peer.TxValidationCode{
+					"TX_ID": code,
+				}),
+			)
+
+			actual, err := Wait(
+				ctx,
+				NewSigningIdentity(controller, nil),
+				"TX_ID",
+				WithDeliverClient(mockDeliver),
+				WithChannel("CHANNEL"),
+				WithStartBlock(1),
+			)
+			require.NoError(t, err)
+
+			expected := &Status{
+				TransactionID: "TX_ID",
+				BlockNumber:   2,
+				Code:          code,
+			}
+			require.Equal(t, expected, actual)
+			require.Equal(t, code == peer.TxValidationCode_VALID, actual.Successful(), "successful")
+		})
+	}
+
+	t.Run("Status response gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockDeliver := NewDeliverClient(controller, &common.Envelope{}, &peer.DeliverResponse{
+			Type: &peer.DeliverResponse_Status{
+				Status: common.Status_FORBIDDEN,
+			},
+		})
+
+		_, err := Wait(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			"TX_ID",
+			WithDeliverClient(mockDeliver),
+			WithChannel("CHANNEL"),
+			WithStartBlock(1),
+		)
+		require.ErrorContains(t, err, common.Status_FORBIDDEN.String())
+	})
+
+	t.Run("Stream error gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockDeliver := NewDeliverClient(controller, &common.Envelope{})
+
+		_, err := Wait(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			"TX_ID",
+			WithDeliverClient(mockDeliver),
+			WithChannel("CHANNEL"),
+			WithStartBlock(1),
+		)
+		require.ErrorContains(t, err, "STREAM_END")
+	})
+}
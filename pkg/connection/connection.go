@@ -0,0 +1,307 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Connection options recognized in a node's grpcOptions. Both the Node and Go SDK naming conventions are accepted.
+var (
+	hostnameOverrideKeys    = []string{"ssl-target-name-override", "hostnameOverride"}
+	keepAliveTimeKeys       = []string{"grpc.keepalive_time_ms", "keep-alive-time"}
+	keepAliveTimeoutKeys    = []string{"grpc.keepalive_timeout_ms", "keep-alive-timeout"}
+	keepAlivePermitKeys     = []string{"grpc.keepalive_permit_without_calls", "keep-alive-permit"}
+	maxReceiveMessageKeys   = []string{"grpc.max_receive_message_length", "grpc-max-receive-message-length"}
+	maxSendMessageKeys      = []string{"grpc.max_send_message_length", "grpc-max-send-message-length"}
+	allowInsecureKeys       = []string{"allow-insecure"}
+	millisecondDurationKeys = map[string]bool{"grpc.keepalive_time_ms": true, "grpc.keepalive_timeout_ms": true}
+)
+
+// PeerConnection creates a gRPC client connection to the named peer. The returned connection can be used with any
+// command that accepts a client connection, and should be closed when no longer needed. Dial options supplied as
+// arguments are applied after those derived from the connection profile.
+func (p *Profile) PeerConnection(name string, options ...grpc.DialOption) (*grpc.ClientConn, error) {
+	node, err := p.peer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.dial(name, node, options)
+}
+
+// OrdererConnection creates a gRPC client connection to the named orderer. The returned connection should be closed
+// when no longer needed. Dial options supplied as arguments are applied after those derived from the connection
+// profile.
+func (p *Profile) OrdererConnection(name string, options ...grpc.DialOption) (*grpc.ClientConn, error) {
+	node, err := p.orderer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.dial(name, node, options)
+}
+
+func (p *Profile) dial(name string, node *Node, options []grpc.DialOption) (*grpc.ClientConn, error) {
+	dialOptions, err := p.dialOptions(node)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection options for %s: %w", name, err)
+	}
+
+	connection, err := grpc.Dial(nodeAddress(node.URL), append(dialOptions, options...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection to %s: %w", name, err)
+	}
+
+	return connection, nil
+}
+
+func (p *Profile) dialOptions(node *Node) ([]grpc.DialOption, error) {
+	transportCredentials, err := p.transportCredentials(node)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []grpc.DialOption{
+		grpc.WithTransportCredentials(transportCredentials),
+	}
+
+	keepAliveOption, err := keepAliveDialOption(node.GRPCOptions)
+	if err != nil {
+		return nil, err
+	}
+	if keepAliveOption != nil {
+		results = append(results, keepAliveOption)
+	}
+
+	callOptions, err := messageSizeCallOptions(node.GRPCOptions)
+	if err != nil {
+		return nil, err
+	}
+	if len(callOptions) > 0 {
+		results = append(results, grpc.WithDefaultCallOptions(callOptions...))
+	}
+
+	return results, nil
+}
+
+func (p *Profile) transportCredentials(node *Node) (credentials.TransportCredentials, error) {
+	caCertsPEM, err := p.readPEM(node.TLSCACerts)
+	if err != nil {
+		return nil, err
+	}
+
+	allowInsecure, err := boolOption(node.GRPCOptions, allowInsecureKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if !useTLS(node.URL, caCertsPEM) {
+		if !allowInsecure && nodeScheme(node.URL) != "grpc" {
+			return nil, errors.New("no TLS CA certificates supplied for connection without URL scheme")
+		}
+		return insecure.NewCredentials(), nil
+	}
+
+	serverName, err := stringOption(node.GRPCOptions, hostnameOverrideKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(caCertsPEM, serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig.Certificates, err = p.clientCertificates(); err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func useTLS(url string, caCertsPEM []byte) bool {
+	switch nodeScheme(url) {
+	case "grpcs":
+		return true
+	case "grpc":
+		return false
+	default:
+		return len(caCertsPEM) > 0
+	}
+}
+
+func nodeScheme(url string) string {
+	scheme, _, found := strings.Cut(url, "://")
+	if !found {
+		return ""
+	}
+
+	return strings.ToLower(scheme)
+}
+
+func nodeAddress(url string) string {
+	if _, address, found := strings.Cut(url, "://"); found {
+		return address
+	}
+
+	return url
+}
+
+func keepAliveDialOption(options map[string]interface{}) (grpc.DialOption, error) {
+	keepAliveTime, err := durationOption(options, keepAliveTimeKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	keepAliveTimeout, err := durationOption(options, keepAliveTimeoutKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	permitWithoutStream, err := boolOption(options, keepAlivePermitKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if keepAliveTime == 0 && keepAliveTimeout == 0 && !permitWithoutStream {
+		return nil, nil
+	}
+
+	return grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                keepAliveTime,
+		Timeout:             keepAliveTimeout,
+		PermitWithoutStream: permitWithoutStream,
+	}), nil
+}
+
+func messageSizeCallOptions(options map[string]interface{}) ([]grpc.CallOption, error) {
+	var results []grpc.CallOption
+
+	maxReceive, err := intOption(options, maxReceiveMessageKeys)
+	if err != nil {
+		return nil, err
+	}
+	if maxReceive > 0 {
+		results = append(results, grpc.MaxCallRecvMsgSize(maxReceive))
+	}
+
+	maxSend, err := intOption(options, maxSendMessageKeys)
+	if err != nil {
+		return nil, err
+	}
+	if maxSend > 0 {
+		results = append(results, grpc.MaxCallSendMsgSize(maxSend))
+	}
+
+	return results, nil
+}
+
+func findOption(options map[string]interface{}, keys []string) (string, interface{}, bool) {
+	for _, key := range keys {
+		if value, ok := options[key]; ok {
+			return key, value, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func stringOption(options map[string]interface{}, keys []string) (string, error) {
+	key, value, found := findOption(options, keys)
+	if !found {
+		return "", nil
+	}
+
+	result, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string: %v", key, value)
+	}
+
+	return result, nil
+}
+
+func boolOption(options map[string]interface{}, keys []string) (bool, error) {
+	key, value, found := findOption(options, keys)
+	if !found {
+		return false, nil
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case int:
+		return v != 0, nil
+	case string:
+		result, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("%s must be a boolean: %w", key, err)
+		}
+		return result, nil
+	default:
+		return false, fmt.Errorf("%s must be a boolean: %v", key, value)
+	}
+}
+
+func intOption(options map[string]interface{}, keys []string) (int, error) {
+	key, value, found := findOption(options, keys)
+	if !found {
+		return 0, nil
+	}
+
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	case string:
+		result, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be an integer: %w", key, err)
+		}
+		return result, nil
+	default:
+		return 0, fmt.Errorf("%s must be an integer: %v", key, value)
+	}
+}
+
+// durationOption returns a duration specified either as a number of milliseconds, for options named with a _ms
+// suffix, or as a duration string such as "30s".
+func durationOption(options map[string]interface{}, keys []string) (time.Duration, error) {
+	key, value, found := findOption(options, keys)
+	if !found {
+		return 0, nil
+	}
+
+	if millisecondDurationKeys[key] {
+		milliseconds, err := intOption(options, []string{key})
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(milliseconds) * time.Millisecond, nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("%s must be a duration: %v", key, value)
+	}
+
+	result, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration: %w", key, err)
+	}
+
+	return result, nil
+}
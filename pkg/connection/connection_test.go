@@ -0,0 +1,241 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package connection
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// NewServerCertificate creates a self-signed TLS server certificate for the supplied host name, returning the
+// certificate and its PEM encoding.
+func NewServerCertificate(t *testing.T, hostname string) (tls.Certificate, []byte) {
+	certificate, certPEM, _ := NewCertificate(t, hostname, x509.ExtKeyUsageServerAuth)
+	return certificate, certPEM
+}
+
+// NewCertificate creates a self-signed TLS certificate, returning the certificate and the PEM encoding of both the
+// certificate and its private key.
+func NewCertificate(t *testing.T, hostname string, usage x509.ExtKeyUsage) (tls.Certificate, []byte, []byte) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: hostname},
+		DNSNames:              []string{hostname},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	certificate := tls.Certificate{
+		Certificate: [][]byte{certDER},
+		PrivateKey:  privateKey,
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certificate, certPEM, keyPEM
+}
+
+// StartServer starts a gRPC server providing the health service, returning its listening address.
+func StartServer(t *testing.T, options ...grpc.ServerOption) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(options...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func HealthCheck(connection grpc.ClientConnInterface) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := healthpb.NewHealthClient(connection).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(false))
+	return err
+}
+
+func TestPeerConnection(t *testing.T) {
+	t.Run("Unknown peer gives error", func(t *testing.T) {
+		_, err := (&Profile{}).PeerConnection("UNKNOWN")
+		require.ErrorContains(t, err, "UNKNOWN")
+	})
+
+	t.Run("Connects using TLS with hostname override", func(t *testing.T) {
+		certificate, certPEM := NewServerCertificate(t, "peer0.org1.example.com")
+		address := StartServer(t, grpc.Creds(credentials.NewServerTLSFromCert(&certificate)))
+
+		profile := &Profile{
+			Peers: map[string]*Node{
+				"peer0": {
+					URL:         "grpcs://" + address,
+					TLSCACerts:  PEMSource{PEM: string(certPEM)},
+					GRPCOptions: map[string]interface{}{"ssl-target-name-override": "peer0.org1.example.com"},
+				},
+			},
+		}
+
+		connection, err := profile.PeerConnection("peer0")
+		require.NoError(t, err)
+		defer connection.Close()
+
+		require.NoError(t, HealthCheck(connection))
+	})
+
+	t.Run("TLS connection without hostname override fails verification", func(t *testing.T) {
+		certificate, certPEM := NewServerCertificate(t, "peer0.org1.example.com")
+		address := StartServer(t, grpc.Creds(credentials.NewServerTLSFromCert(&certificate)))
+
+		profile := &Profile{
+			Peers: map[string]*Node{
+				"peer0": {
+					URL:        "grpcs://" + address,
+					TLSCACerts: PEMSource{PEM: string(certPEM)},
+				},
+			},
+		}
+
+		connection, err := profile.PeerConnection("peer0")
+		require.NoError(t, err)
+		defer connection.Close()
+
+		require.Error(t, HealthCheck(connection))
+	})
+
+	t.Run("Connects without TLS using grpc scheme", func(t *testing.T) {
+		address := StartServer(t)
+
+		profile := &Profile{
+			Peers: map[string]*Node{
+				"peer0": {URL: "grpc://" + address},
+			},
+		}
+
+		connection, err := profile.PeerConnection("peer0")
+		require.NoError(t, err)
+		defer connection.Close()
+
+		require.NoError(t, HealthCheck(connection))
+	})
+
+	t.Run("Missing scheme and TLS CA certificates gives error", func(t *testing.T) {
+		profile := &Profile{
+			Peers: map[string]*Node{
+				"peer0": {URL: "localhost:7051"},
+			},
+		}
+
+		_, err := profile.PeerConnection("peer0")
+		require.ErrorContains(t, err, "TLS")
+	})
+
+	t.Run("Invalid TLS CA certificates gives error", func(t *testing.T) {
+		profile := &Profile{
+			Peers: map[string]*Node{
+				"peer0": {URL: "grpcs://localhost:7051", TLSCACerts: PEMSource{PEM: "INVALID"}},
+			},
+		}
+
+		_, err := profile.PeerConnection("peer0")
+		require.ErrorContains(t, err, "certificates")
+	})
+
+	for name, options := range map[string]map[string]interface{}{
+		"hostname override":   {"ssl-target-name-override": 42},
+		"keep-alive time":     {"grpc.keepalive_time_ms": "forever"},
+		"keep-alive duration": {"keep-alive-time": "forever"},
+		"keep-alive permit":   {"grpc.keepalive_permit_without_calls": "maybe"},
+		"message size":        {"grpc.max_receive_message_length": "large"},
+	} {
+		options := options
+		t.Run("Invalid "+name+" gives error", func(t *testing.T) {
+			profile := &Profile{
+				Peers: map[string]*Node{
+					"peer0": {URL: "grpcs://localhost:7051", GRPCOptions: options},
+				},
+			}
+
+			_, err := profile.PeerConnection("peer0")
+			require.ErrorContains(t, err, "peer0")
+		})
+	}
+
+	t.Run("Valid gRPC options accepted", func(t *testing.T) {
+		profile := &Profile{
+			Peers: map[string]*Node{
+				"peer0": {
+					URL: "grpc://localhost:7051",
+					GRPCOptions: map[string]interface{}{
+						"grpc.keepalive_time_ms":              120000,
+						"keep-alive-timeout":                  "20s",
+						"grpc.keepalive_permit_without_calls": 1,
+						"grpc.max_receive_message_length":     -1,
+						"grpc-max-send-message-length":        "1048576",
+					},
+				},
+			},
+		}
+
+		connection, err := profile.PeerConnection("peer0")
+		require.NoError(t, err)
+		require.NoError(t, connection.Close())
+	})
+}
+
+func TestOrdererConnection(t *testing.T) {
+	t.Run("Unknown orderer gives error", func(t *testing.T) {
+		_, err := (&Profile{}).OrdererConnection("UNKNOWN")
+		require.ErrorContains(t, err, "UNKNOWN")
+	})
+
+	t.Run("Connects to orderer", func(t *testing.T) {
+		address := StartServer(t)
+
+		profile := &Profile{
+			Orderers: map[string]*Node{
+				"orderer": {URL: "grpc://" + address},
+			},
+		}
+
+		connection, err := profile.OrdererConnection("orderer")
+		require.NoError(t, err)
+		defer connection.Close()
+
+		require.NoError(t, HealthCheck(connection))
+	})
+}
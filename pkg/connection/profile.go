@@ -0,0 +1,207 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package connection provides support for creating gRPC connections to peers and orderers described by a Hyperledger
+// Fabric common connection profile.
+package connection
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is a common connection profile, describing the organizations and network nodes with which a client
+// interacts.
+type Profile struct {
+	Name          string                   `yaml:"name"`
+	Version       string                   `yaml:"version"`
+	Client        Client                   `yaml:"client"`
+	Organizations map[string]*Organization `yaml:"organizations"`
+	Peers         map[string]*Node         `yaml:"peers"`
+	Orderers      map[string]*Node         `yaml:"orderers"`
+
+	// baseDir is the directory against which relative file paths are resolved.
+	baseDir string
+}
+
+// Client describes the client application using the connection profile.
+type Client struct {
+	// Organization name, which is a key in the profile's organizations.
+	Organization string `yaml:"organization"`
+	// TLSCerts used for mutual TLS authentication with network nodes.
+	TLSCerts ClientTLSCerts `yaml:"tlsCerts"`
+}
+
+// ClientTLSCerts specifies the client certificate and private key used for mutual TLS authentication.
+type ClientTLSCerts struct {
+	Client struct {
+		Key  PEMSource `yaml:"key"`
+		Cert PEMSource `yaml:"cert"`
+	} `yaml:"client"`
+}
+
+// Organization describes a network organization.
+type Organization struct {
+	MspID string `yaml:"mspid"`
+	// Peers owned by the organization, which are keys in the profile's peers.
+	Peers []string `yaml:"peers"`
+	// Orderers owned by the organization, which are keys in the profile's orderers.
+	Orderers []string `yaml:"orderers"`
+}
+
+// Node describes the connection details for a peer or orderer.
+type Node struct {
+	// URL of the node, such as grpcs://peer0.org1.example.com:7051. The grpcs scheme uses TLS and the grpc scheme does
+	// not. If no scheme is specified, TLS is used if TLS CA certificates are supplied.
+	URL string `yaml:"url"`
+	// TLSCACerts used to verify the node's TLS certificate.
+	TLSCACerts PEMSource `yaml:"tlsCACerts"`
+	// GRPCOptions are per-node connection options, such as ssl-target-name-override or grpc.keepalive_time_ms.
+	GRPCOptions map[string]interface{} `yaml:"grpcOptions"`
+}
+
+// PEMSource specifies PEM encoded content, either inline or as the path of a PEM file.
+type PEMSource struct {
+	PEM  string `yaml:"pem"`
+	Path string `yaml:"path"`
+}
+
+// LoadProfile reads a connection profile, in either JSON or YAML format, from a file. Relative file paths in the
+// profile are resolved against the directory containing the profile.
+func LoadProfile(filename string) (*Profile, error) {
+	profileBytes, err := os.ReadFile(filename) //#nosec G304 -- caller supplied profile location
+	if err != nil {
+		return nil, fmt.Errorf("failed to read connection profile: %w", err)
+	}
+
+	profile, err := ParseProfile(profileBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.baseDir = filepath.Dir(filename)
+	return profile, nil
+}
+
+// ParseProfile parses a connection profile in either JSON or YAML format. Relative file paths in the profile are
+// resolved against the current working directory.
+func ParseProfile(profileBytes []byte) (*Profile, error) {
+	profile := &Profile{}
+	if err := yaml.Unmarshal(profileBytes, profile); err != nil {
+		return nil, fmt.Errorf("failed to parse connection profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// ClientOrganization returns the organization of the client application.
+func (p *Profile) ClientOrganization() (*Organization, error) {
+	if p.Client.Organization == "" {
+		return nil, errors.New("no client organization specified")
+	}
+
+	organization, ok := p.Organizations[p.Client.Organization]
+	if !ok {
+		return nil, fmt.Errorf("client organization not found: %s", p.Client.Organization)
+	}
+
+	return organization, nil
+}
+
+// PeerNames returns the names of peers owned by the organization with the specified MSP ID.
+func (p *Profile) PeerNames(mspID string) []string {
+	var results []string
+
+	for _, organization := range p.Organizations {
+		if organization.MspID == mspID {
+			results = append(results, organization.Peers...)
+		}
+	}
+
+	sort.Strings(results)
+	return results
+}
+
+func (p *Profile) peer(name string) (*Node, error) {
+	node, ok := p.Peers[name]
+	if !ok {
+		return nil, fmt.Errorf("peer not found: %s", name)
+	}
+
+	return node, nil
+}
+
+func (p *Profile) orderer(name string) (*Node, error) {
+	node, ok := p.Orderers[name]
+	if !ok {
+		return nil, fmt.Errorf("orderer not found: %s", name)
+	}
+
+	return node, nil
+}
+
+func (p *Profile) resolvePath(path string) string {
+	if filepath.IsAbs(path) || p.baseDir == "" {
+		return path
+	}
+
+	return filepath.Join(p.baseDir, path)
+}
+
+// ClientTLSCertHash returns the hash of the client TLS certificate, or nil if no client certificate is specified. This
+// is the value that binds messages to a mutual TLS connection, such as the TlsCertHash of a channel header.
+func (p *Profile) ClientTLSCertHash() ([]byte, error) {
+	certPEM, err := p.readPEM(p.Client.TLSCerts.Client.Cert)
+	if err != nil || certPEM == nil {
+		return nil, err
+	}
+
+	return CertHash(certPEM)
+}
+
+func (p *Profile) clientCertificates() ([]tls.Certificate, error) {
+	certPEM, err := p.readPEM(p.Client.TLSCerts.Client.Cert)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM, err := p.readPEM(p.Client.TLSCerts.Client.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	if certPEM == nil && keyPEM == nil {
+		return nil, nil
+	}
+
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client TLS certificate: %w", err)
+	}
+
+	return []tls.Certificate{certificate}, nil
+}
+
+func (p *Profile) readPEM(source PEMSource) ([]byte, error) {
+	if source.PEM != "" {
+		return []byte(source.PEM), nil
+	}
+	if source.Path == "" {
+		return nil, nil
+	}
+
+	result, err := os.ReadFile(p.resolvePath(source.Path)) //#nosec G304 -- file location from connection profile
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PEM file: %w", err)
+	}
+
+	return result, nil
+}
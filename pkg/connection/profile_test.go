@@ -0,0 +1,145 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package connection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const yamlProfile = `
+name: test-network
+version: 1.0.0
+client:
+  organization: Org1
+organizations:
+  Org1:
+    mspid: Org1MSP
+    peers:
+      - peer1.org1.example.com
+      - peer0.org1.example.com
+  Org2:
+    mspid: Org2MSP
+    peers:
+      - peer0.org2.example.com
+peers:
+  peer0.org1.example.com:
+    url: grpcs://localhost:7051
+    tlsCACerts:
+      path: tls/ca.pem
+    grpcOptions:
+      ssl-target-name-override: peer0.org1.example.com
+      grpc.keepalive_time_ms: 120000
+orderers:
+  orderer.example.com:
+    url: grpcs://localhost:7050
+`
+
+const jsonProfile = `{
+	"name": "test-network",
+	"client": {"organization": "Org1"},
+	"organizations": {
+		"Org1": {"mspid": "Org1MSP", "peers": ["peer0.org1.example.com"]}
+	},
+	"peers": {
+		"peer0.org1.example.com": {
+			"url": "grpcs://localhost:7051",
+			"tlsCACerts": {"pem": "PEM"},
+			"grpcOptions": {"hostnameOverride": "peer0.org1.example.com"}
+		}
+	}
+}`
+
+func AssertWriteFile(t *testing.T, filename string, content []byte) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o750))
+	require.NoError(t, os.WriteFile(filename, content, 0o600))
+}
+
+func TestParseProfile(t *testing.T) {
+	t.Run("Parses YAML", func(t *testing.T) {
+		profile, err := ParseProfile([]byte(yamlProfile))
+		require.NoError(t, err)
+
+		require.Equal(t, "test-network", profile.Name)
+		require.Equal(t, "Org1MSP", profile.Organizations["Org1"].MspID)
+
+		peer := profile.Peers["peer0.org1.example.com"]
+		require.Equal(t, "grpcs://localhost:7051", peer.URL)
+		require.Equal(t, "tls/ca.pem", peer.TLSCACerts.Path)
+		require.Equal(t, "peer0.org1.example.com", peer.GRPCOptions["ssl-target-name-override"])
+		require.Equal(t, "grpcs://localhost:7050", profile.Orderers["orderer.example.com"].URL)
+	})
+
+	t.Run("Parses JSON", func(t *testing.T) {
+		profile, err := ParseProfile([]byte(jsonProfile))
+		require.NoError(t, err)
+
+		peer := profile.Peers["peer0.org1.example.com"]
+		require.Equal(t, "grpcs://localhost:7051", peer.URL)
+		require.Equal(t, "PEM", peer.TLSCACerts.PEM)
+		require.Equal(t, "peer0.org1.example.com", peer.GRPCOptions["hostnameOverride"])
+	})
+
+	t.Run("Invalid content gives error", func(t *testing.T) {
+		_, err := ParseProfile([]byte("peers: [unterminated"))
+		require.Error(t, err)
+	})
+}
+
+func TestLoadProfile(t *testing.T) {
+	t.Run("Missing file gives error", func(t *testing.T) {
+		_, err := LoadProfile(filepath.Join(t.TempDir(), "missing.yaml"))
+		require.Error(t, err)
+	})
+
+	t.Run("Relative paths resolved against profile directory", func(t *testing.T) {
+		dir := t.TempDir()
+		profileFile := filepath.Join(dir, "profile.yaml")
+		AssertWriteFile(t, profileFile, []byte(yamlProfile))
+		AssertWriteFile(t, filepath.Join(dir, "tls", "ca.pem"), []byte("CA_PEM"))
+
+		profile, err := LoadProfile(profileFile)
+		require.NoError(t, err)
+
+		actual, err := profile.readPEM(profile.Peers["peer0.org1.example.com"].TLSCACerts)
+		require.NoError(t, err)
+		require.Equal(t, []byte("CA_PEM"), actual)
+	})
+}
+
+func TestClientOrganization(t *testing.T) {
+	t.Run("Returns client organization", func(t *testing.T) {
+		profile, err := ParseProfile([]byte(yamlProfile))
+		require.NoError(t, err)
+
+		actual, err := profile.ClientOrganization()
+		require.NoError(t, err)
+		require.Equal(t, "Org1MSP", actual.MspID)
+	})
+
+	t.Run("Unknown client organization gives error", func(t *testing.T) {
+		profile := &Profile{Client: Client{Organization: "Unknown"}}
+
+		_, err := profile.ClientOrganization()
+		require.ErrorContains(t, err, "Unknown")
+	})
+
+	t.Run("Missing client organization gives error", func(t *testing.T) {
+		_, err := (&Profile{}).ClientOrganization()
+		require.Error(t, err)
+	})
+}
+
+func TestPeerNames(t *testing.T) {
+	profile, err := ParseProfile([]byte(yamlProfile))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"peer0.org1.example.com", "peer1.org1.example.com"}, profile.PeerNames("Org1MSP"))
+	require.Empty(t, profile.PeerNames("UnknownMSP"))
+}
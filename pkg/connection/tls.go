@@ -0,0 +1,110 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package connection
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSFiles specifies the PEM files used to establish a TLS connection. If a client certificate and key are supplied,
+// they are used for mutual TLS authentication.
+type TLSFiles struct {
+	// CACert file containing the CA certificates used to verify the server's TLS certificate.
+	CACert string
+	// ClientCert file containing the client TLS certificate.
+	ClientCert string
+	// ClientKey file containing the client TLS private key.
+	ClientKey string
+	// ServerNameOverride is the host name used to verify the server's TLS certificate, if it differs from the host name
+	// used to connect.
+	ServerNameOverride string
+}
+
+// NewTLSConnection creates a gRPC client connection to the target address using TLS. The returned connection should
+// be closed when no longer needed. Dial options supplied as arguments are applied after the TLS credentials.
+func NewTLSConnection(target string, files TLSFiles, options ...grpc.DialOption) (*grpc.ClientConn, error) {
+	caCertPEM, err := os.ReadFile(files.CACert) //#nosec G304 -- caller supplied file location
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS CA certificates: %w", err)
+	}
+
+	tlsConfig, err := newTLSConfig(caCertPEM, files.ServerNameOverride)
+	if err != nil {
+		return nil, err
+	}
+
+	if files.ClientCert != "" || files.ClientKey != "" {
+		clientCertificate, err := tls.LoadX509KeyPair(files.ClientCert, files.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client TLS certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCertificate}
+	}
+
+	dialOptions := append([]grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}, options...)
+	connection, err := grpc.Dial(target, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection: %w", err)
+	}
+
+	return connection, nil
+}
+
+// ClientCertHash returns the hash of the client TLS certificate, or nil if no client certificate is specified. This is
+// the value that binds messages to a mutual TLS connection, such as the TlsCertHash of a channel header.
+func (f *TLSFiles) ClientCertHash() ([]byte, error) {
+	if f.ClientCert == "" {
+		return nil, nil
+	}
+
+	certPEM, err := os.ReadFile(f.ClientCert) //#nosec G304 -- caller supplied file location
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client TLS certificate: %w", err)
+	}
+
+	return CertHash(certPEM)
+}
+
+// CertHash returns the SHA-256 hash of a PEM encoded TLS certificate, as used by Fabric to bind messages to the client
+// certificate of a mutual TLS connection.
+func CertHash(certPEM []byte) ([]byte, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("no PEM data found in TLS certificate")
+	}
+
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		return nil, fmt.Errorf("failed to parse TLS certificate: %w", err)
+	}
+
+	hash := sha256.Sum256(block.Bytes)
+	return hash[:], nil
+}
+
+func newTLSConfig(caCertsPEM []byte, serverName string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if len(caCertsPEM) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCertsPEM) {
+			return nil, errors.New("no valid TLS CA certificates found")
+		}
+	}
+
+	return tlsConfig, nil
+}
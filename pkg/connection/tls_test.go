@@ -0,0 +1,179 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package connection
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// StartMutualTLSServer starts a gRPC server that requires a client certificate signed by the supplied CA, returning
+// its listening address and the PEM encoded server certificate.
+func StartMutualTLSServer(t *testing.T, hostname string, clientCACertPEM []byte) (string, []byte) {
+	serverCertificate, serverCertPEM := NewServerCertificate(t, hostname)
+
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(clientCACertPEM))
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{serverCertificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	address := StartServer(t, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	return address, serverCertPEM
+}
+
+func TestNewTLSConnection(t *testing.T) {
+	t.Run("Connects using mutual TLS", func(t *testing.T) {
+		_, clientCertPEM, clientKeyPEM := NewCertificate(t, "client", x509.ExtKeyUsageClientAuth)
+		address, serverCertPEM := StartMutualTLSServer(t, "peer0.org1.example.com", clientCertPEM)
+
+		dir := t.TempDir()
+		files := TLSFiles{
+			CACert:             filepath.Join(dir, "ca.pem"),
+			ClientCert:         filepath.Join(dir, "client.pem"),
+			ClientKey:          filepath.Join(dir, "client.key"),
+			ServerNameOverride: "peer0.org1.example.com",
+		}
+		AssertWriteFile(t, files.CACert, serverCertPEM)
+		AssertWriteFile(t, files.ClientCert, clientCertPEM)
+		AssertWriteFile(t, files.ClientKey, clientKeyPEM)
+
+		connection, err := NewTLSConnection(address, files)
+		require.NoError(t, err)
+		defer connection.Close()
+
+		require.NoError(t, HealthCheck(connection))
+	})
+
+	t.Run("Server requiring client certificate rejects connection without one", func(t *testing.T) {
+		_, clientCertPEM, _ := NewCertificate(t, "client", x509.ExtKeyUsageClientAuth)
+		address, serverCertPEM := StartMutualTLSServer(t, "peer0.org1.example.com", clientCertPEM)
+
+		files := TLSFiles{
+			CACert:             filepath.Join(t.TempDir(), "ca.pem"),
+			ServerNameOverride: "peer0.org1.example.com",
+		}
+		AssertWriteFile(t, files.CACert, serverCertPEM)
+
+		connection, err := NewTLSConnection(address, files)
+		require.NoError(t, err)
+		defer connection.Close()
+
+		require.Error(t, HealthCheck(connection))
+	})
+
+	t.Run("Missing CA certificate file gives error", func(t *testing.T) {
+		_, err := NewTLSConnection("localhost:7051", TLSFiles{CACert: filepath.Join(t.TempDir(), "missing.pem")})
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid client key gives error", func(t *testing.T) {
+		_, serverCertPEM := NewServerCertificate(t, "peer0")
+		_, clientCertPEM, _ := NewCertificate(t, "client", x509.ExtKeyUsageClientAuth)
+
+		dir := t.TempDir()
+		files := TLSFiles{
+			CACert:     filepath.Join(dir, "ca.pem"),
+			ClientCert: filepath.Join(dir, "client.pem"),
+			ClientKey:  filepath.Join(dir, "client.key"),
+		}
+		AssertWriteFile(t, files.CACert, serverCertPEM)
+		AssertWriteFile(t, files.ClientCert, clientCertPEM)
+		AssertWriteFile(t, files.ClientKey, []byte("INVALID"))
+
+		_, err := NewTLSConnection("localhost:7051", files)
+		require.ErrorContains(t, err, "client TLS certificate")
+	})
+}
+
+func TestCertHash(t *testing.T) {
+	t.Run("Hash of certificate DER", func(t *testing.T) {
+		certificate, certPEM := NewServerCertificate(t, "client")
+
+		actual, err := CertHash(certPEM)
+		require.NoError(t, err)
+
+		expected := sha256.Sum256(certificate.Certificate[0])
+		require.Equal(t, expected[:], actual)
+	})
+
+	t.Run("Non-PEM content gives error", func(t *testing.T) {
+		_, err := CertHash([]byte("INVALID"))
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid certificate gives error", func(t *testing.T) {
+		_, err := CertHash(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("INVALID")}))
+		require.Error(t, err)
+	})
+}
+
+func TestClientCertHash(t *testing.T) {
+	t.Run("No client certificate gives nil hash", func(t *testing.T) {
+		actual, err := (&TLSFiles{}).ClientCertHash()
+		require.NoError(t, err)
+		require.Nil(t, actual)
+	})
+
+	t.Run("Hash of client certificate file", func(t *testing.T) {
+		_, certPEM := NewServerCertificate(t, "client")
+		files := &TLSFiles{ClientCert: filepath.Join(t.TempDir(), "client.pem")}
+		AssertWriteFile(t, files.ClientCert, certPEM)
+
+		actual, err := files.ClientCertHash()
+		require.NoError(t, err)
+
+		expected, err := CertHash(certPEM)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+}
+
+func TestProfileMutualTLS(t *testing.T) {
+	_, clientCertPEM, clientKeyPEM := NewCertificate(t, "client", x509.ExtKeyUsageClientAuth)
+	address, serverCertPEM := StartMutualTLSServer(t, "peer0.org1.example.com", clientCertPEM)
+
+	profile := &Profile{
+		Peers: map[string]*Node{
+			"peer0": {
+				URL:         "grpcs://" + address,
+				TLSCACerts:  PEMSource{PEM: string(serverCertPEM)},
+				GRPCOptions: map[string]interface{}{"ssl-target-name-override": "peer0.org1.example.com"},
+			},
+		},
+	}
+	profile.Client.TLSCerts.Client.Cert = PEMSource{PEM: string(clientCertPEM)}
+	profile.Client.TLSCerts.Client.Key = PEMSource{PEM: string(clientKeyPEM)}
+
+	t.Run("Connects using client TLS certificate", func(t *testing.T) {
+		connection, err := profile.PeerConnection("peer0")
+		require.NoError(t, err)
+		defer connection.Close()
+
+		require.NoError(t, HealthCheck(connection))
+	})
+
+	t.Run("Client TLS certificate hash", func(t *testing.T) {
+		actual, err := profile.ClientTLSCertHash()
+		require.NoError(t, err)
+
+		expected, err := CertHash(clientCertPEM)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+}
@@ -0,0 +1,306 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package discovery provides support for querying the service discovery service of a peer to find the peers and
+// orderers of a channel, and the peers required to endorse chaincode transactions.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	admincommon "github.com/bestbeforetoday/fabric-admin/internal/common"
+	"github.com/bestbeforetoday/fabric-admin/internal/envelope"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	discoveryprotos "github.com/hyperledger/fabric-protos-go-apiv2/discovery"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Config of a channel, as reported by service discovery.
+type Config struct {
+	// MSPs of the channel members, keyed by MSP ID.
+	MSPs map[string]*msp.FabricMSPConfig
+	// Orderers endpoints, as host:port, keyed by MSP ID.
+	Orderers map[string][]string
+}
+
+// EndorsementPlan describes combinations of peers that can satisfy the endorsement requirements of a chaincode
+// transaction.
+type EndorsementPlan struct {
+	Chaincode string
+	// Groups of peers, keyed by group name.
+	Groups map[string][]*Peer
+	// Layouts each specify the number of peers required from each group. Satisfying any one layout satisfies the
+	// endorsement requirements.
+	Layouts []map[string]uint32
+}
+
+// QueryPeers returns the peers of the channel specified using WithChannel. If chaincodes are specified using
+// WithChaincode, only peers with those chaincodes installed are returned. If no channel is specified, the peers known
+// to the connected peer are returned, which requires the signing identity to be an administrator of the peer.
+func QueryPeers(ctx context.Context, signingID identity.SigningIdentity, options ...Option) ([]*Peer, error) {
+	queryCommand := &command{}
+
+	if err := admincommon.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
+	}
+
+	result, err := queryCommand.run(ctx, signingID, queryCommand.peerQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	return peersFromMembers(result.GetMembers())
+}
+
+// QueryConfig returns the MSPs and orderers of the channel specified using WithChannel.
+func QueryConfig(ctx context.Context, signingID identity.SigningIdentity, options ...Option) (*Config, error) {
+	queryCommand := &command{}
+
+	if err := admincommon.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := queryCommand.validateChannel(); err != nil {
+		return nil, err
+	}
+
+	result, err := queryCommand.run(ctx, signingID, &discoveryprotos.Query{
+		Channel: queryCommand.channelName,
+		Query: &discoveryprotos.Query_ConfigQuery{
+			ConfigQuery: &discoveryprotos.ConfigQuery{},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return configFromResult(result.GetConfigResult()), nil
+}
+
+// QueryEndorsers returns an endorsement plan for invoking the chaincodes specified using WithChaincode on the channel
+// specified using WithChannel.
+func QueryEndorsers(ctx context.Context, signingID identity.SigningIdentity, options ...Option) (*EndorsementPlan, error) {
+	queryCommand := &command{}
+
+	if err := admincommon.ApplyOptions(queryCommand, options...); err != nil {
+		return nil, err
+	}
+
+	if err := queryCommand.validateChannel(); err != nil {
+		return nil, err
+	}
+	if len(queryCommand.chaincodeCalls) == 0 {
+		return nil, errors.New("no chaincode supplied")
+	}
+
+	result, err := queryCommand.run(ctx, signingID, &discoveryprotos.Query{
+		Channel: queryCommand.channelName,
+		Query: &discoveryprotos.Query_CcQuery{
+			CcQuery: &discoveryprotos.ChaincodeQuery{
+				Interests: []*peer.ChaincodeInterest{queryCommand.chaincodeInterest()},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	descriptors := result.GetCcQueryRes().GetContent()
+	if len(descriptors) != 1 {
+		return nil, fmt.Errorf("expected 1 endorsement descriptor, got %d", len(descriptors))
+	}
+
+	return endorsementPlanFromDescriptor(descriptors[0])
+}
+
+type command struct {
+	grpcClient     discoveryprotos.DiscoveryClient
+	grpcOptions    []grpc.CallOption
+	channelName    string
+	chaincodeCalls []*peer.ChaincodeCall
+	tlsCertHash    []byte
+}
+
+func (c *command) run(ctx context.Context, signingID identity.SigningIdentity, query *discoveryprotos.Query) (*discoveryprotos.QueryResult, error) {
+	if c.grpcClient == nil {
+		return nil, errors.New("no gRPC client supplied")
+	}
+
+	signedRequest, err := c.newSignedRequest(ctx, signingID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.grpcClient.Discover(ctx, signedRequest, c.grpcOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := response.GetResults()
+	if len(results) != 1 {
+		return nil, fmt.Errorf("expected 1 discovery result, got %d", len(results))
+	}
+
+	if queryError := results[0].GetError(); queryError != nil {
+		return nil, fmt.Errorf("discovery query failed: %s", queryError.GetContent())
+	}
+
+	return results[0], nil
+}
+
+func (c *command) validateChannel() error {
+	if c.channelName == "" {
+		return errors.New("no channel name supplied")
+	}
+
+	return nil
+}
+
+func (c *command) peerQuery() *discoveryprotos.Query {
+	if c.channelName == "" {
+		return &discoveryprotos.Query{
+			Query: &discoveryprotos.Query_LocalPeers{
+				LocalPeers: &discoveryprotos.LocalPeerQuery{},
+			},
+		}
+	}
+
+	peerQuery := &discoveryprotos.PeerMembershipQuery{}
+	if len(c.chaincodeCalls) > 0 {
+		peerQuery.Filter = c.chaincodeInterest()
+	}
+
+	return &discoveryprotos.Query{
+		Channel: c.channelName,
+		Query: &discoveryprotos.Query_PeerQuery{
+			PeerQuery: peerQuery,
+		},
+	}
+}
+
+func (c *command) chaincodeInterest() *peer.ChaincodeInterest {
+	return &peer.ChaincodeInterest{
+		Chaincodes: c.chaincodeCalls,
+	}
+}
+
+func (c *command) newSignedRequest(ctx context.Context, signingID identity.SigningIdentity, query *discoveryprotos.Query) (*discoveryprotos.SignedRequest, error) {
+	creator, err := envelope.Creator(signingID)
+	if err != nil {
+		return nil, err
+	}
+
+	requestBytes, err := proto.Marshal(&discoveryprotos.Request{
+		Authentication: &discoveryprotos.AuthInfo{
+			ClientIdentity:    creator,
+			ClientTlsCertHash: c.tlsCertHash,
+		},
+		Queries: []*discoveryprotos.Query{query},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := identity.SignContext(ctx, signingID, requestBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signedRequest := &discoveryprotos.SignedRequest{
+		Payload:   requestBytes,
+		Signature: signature,
+	}
+	return signedRequest, nil
+}
+
+func configFromResult(result *discoveryprotos.ConfigResult) *Config {
+	orderers := make(map[string][]string, len(result.GetOrderers()))
+	for mspID, endpoints := range result.GetOrderers() {
+		for _, endpoint := range endpoints.GetEndpoint() {
+			orderers[mspID] = append(orderers[mspID], fmt.Sprintf("%s:%d", endpoint.GetHost(), endpoint.GetPort()))
+		}
+	}
+
+	return &Config{
+		MSPs:     result.GetMsps(),
+		Orderers: orderers,
+	}
+}
+
+func endorsementPlanFromDescriptor(descriptor *discoveryprotos.EndorsementDescriptor) (*EndorsementPlan, error) {
+	groups := make(map[string][]*Peer, len(descriptor.GetEndorsersByGroups()))
+	for name, peers := range descriptor.GetEndorsersByGroups() {
+		groupPeers, err := peersFromProtos(peers.GetPeers())
+		if err != nil {
+			return nil, err
+		}
+		groups[name] = groupPeers
+	}
+
+	layouts := make([]map[string]uint32, 0, len(descriptor.GetLayouts()))
+	for _, layout := range descriptor.GetLayouts() {
+		layouts = append(layouts, layout.GetQuantitiesByGroup())
+	}
+
+	plan := &EndorsementPlan{
+		Chaincode: descriptor.GetChaincode(),
+		Groups:    groups,
+		Layouts:   layouts,
+	}
+	return plan, nil
+}
+
+type Option = func(*command) error
+
+// WithClientConnection uses the supplied gRPC client connection to a peer. This should be shared by all commands
+// connecting to the same network node.
+func WithClientConnection(clientConnection grpc.ClientConnInterface) Option {
+	return func(c *command) error {
+		c.grpcClient = discoveryprotos.NewDiscoveryClient(clientConnection)
+		return nil
+	}
+}
+
+// WithCallOptions specifies the gRPC call options to be used.
+func WithCallOptions(options ...grpc.CallOption) Option {
+	return func(c *command) error {
+		c.grpcOptions = append(c.grpcOptions, options...)
+		return nil
+	}
+}
+
+// WithChannel specifies the name of the channel to query.
+func WithChannel(channelName string) Option {
+	return func(c *command) error {
+		c.channelName = channelName
+		return nil
+	}
+}
+
+// WithChaincode specifies a chaincode, and optionally private data collections, invoked by a transaction. This option
+// can be supplied multiple times for transactions that invoke other chaincodes.
+func WithChaincode(chaincodeName string, collectionNames ...string) Option {
+	return func(c *command) error {
+		c.chaincodeCalls = append(c.chaincodeCalls, &peer.ChaincodeCall{
+			Name:            chaincodeName,
+			CollectionNames: collectionNames,
+		})
+		return nil
+	}
+}
+
+// WithTLSCertHash specifies the hash of the client TLS certificate used to connect to the peer. The discovery service
+// requires this if the peer is configured to require client TLS authentication.
+func WithTLSCertHash(hash []byte) Option {
+	return func(c *command) error {
+		c.tlsCertHash = hash
+		return nil
+	}
+}
@@ -0,0 +1,345 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	discoveryprotos "github.com/hyperledger/fabric-protos-go-apiv2/discovery"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gossip"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:generate mockgen -destination ./discovery_mock_test.go -package ${GOPACKAGE} github.com/hyperledger/fabric-protos-go-apiv2/discovery DiscoveryClient
+//go:generate mockgen -destination ./signingidentity_mock_test.go -package ${GOPACKAGE} github.com/bestbeforetoday/fabric-admin/pkg/identity SigningIdentity
+
+func WithDiscoveryClient(grpcClient discoveryprotos.DiscoveryClient) Option {
+	return func(c *command) error {
+		c.grpcClient = grpcClient
+		return nil
+	}
+}
+
+func NewSigningIdentity(controller *gomock.Controller, signature []byte) *MockSigningIdentity {
+	mockIdentity := NewMockSigningIdentity(controller)
+	mockIdentity.EXPECT().MspID().Return("SIGNER_MSP").AnyTimes()
+	mockIdentity.EXPECT().Credentials().Return([]byte("SIGNER_CREDENTIALS")).AnyTimes()
+	mockIdentity.EXPECT().Sign(gomock.Any()).Return(signature, nil).AnyTimes()
+
+	return mockIdentity
+}
+
+// NewDiscoveryClient returns a mock discovery client that responds with the supplied result, and records the request.
+func NewDiscoveryClient(controller *gomock.Controller, result *discoveryprotos.QueryResult, request *discoveryprotos.SignedRequest) *MockDiscoveryClient {
+	mockDiscovery := NewMockDiscoveryClient(controller)
+	mockDiscovery.EXPECT().Discover(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, in *discoveryprotos.SignedRequest, _ ...interface{}) {
+			proto.Merge(request, in)
+		}).
+		Return(&discoveryprotos.Response{Results: []*discoveryprotos.QueryResult{result}}, nil)
+
+	return mockDiscovery
+}
+
+func NewPeer(t *testing.T, mspID string, endpoint string, ledgerHeight uint64, chaincodes ...string) *discoveryprotos.Peer {
+	identity := AssertMarshal(t, &msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: []byte(endpoint),
+	})
+
+	membership := AssertMarshal(t, &gossip.GossipMessage{
+		Content: &gossip.GossipMessage_AliveMsg{
+			AliveMsg: &gossip.AliveMessage{
+				Membership: &gossip.Member{Endpoint: endpoint},
+			},
+		},
+	})
+
+	properties := &gossip.Properties{LedgerHeight: ledgerHeight}
+	for _, name := range chaincodes {
+		properties.Chaincodes = append(properties.Chaincodes, &gossip.Chaincode{Name: name, Version: "1.0"})
+	}
+	stateInfo := AssertMarshal(t, &gossip.GossipMessage{
+		Content: &gossip.GossipMessage_StateInfo{
+			StateInfo: &gossip.StateInfo{Properties: properties},
+		},
+	})
+
+	return &discoveryprotos.Peer{
+		Identity:       identity,
+		MembershipInfo: &gossip.Envelope{Payload: membership},
+		StateInfo:      &gossip.Envelope{Payload: stateInfo},
+	}
+}
+
+func AssertMarshal(t *testing.T, m protoreflect.ProtoMessage) []byte {
+	result, err := proto.Marshal(m)
+	require.NoError(t, err)
+	return result
+}
+
+// AssertUnmarshal ensures that a protobuf is umarshaled without error
+func AssertUnmarshal(t *testing.T, b []byte, m protoreflect.ProtoMessage) {
+	err := proto.Unmarshal(b, m)
+	require.NoError(t, err)
+}
+
+// AssertProtoEqual ensures an expected protobuf message matches an actual message
+func AssertProtoEqual(t *testing.T, expected protoreflect.ProtoMessage, actual protoreflect.ProtoMessage) {
+	require.True(t, proto.Equal(expected, actual), "Expected %v, got %v", expected, actual)
+}
+
+// AssertUnmarshalQuery ensures that the single query in a signed request is unmarshaled without error
+func AssertUnmarshalQuery(t *testing.T, signedRequest *discoveryprotos.SignedRequest) (*discoveryprotos.Request, *discoveryprotos.Query) {
+	request := &discoveryprotos.Request{}
+	AssertUnmarshal(t, signedRequest.GetPayload(), request)
+	require.Len(t, request.GetQueries(), 1)
+
+	return request, request.GetQueries()[0]
+}
+
+func TestQueryPeers(t *testing.T) {
+	t.Run("Missing gRPC connection gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := QueryPeers(ctx, NewSigningIdentity(controller, nil), WithChannel("CHANNEL"))
+		require.ErrorContains(t, err, "gRPC")
+	})
+
+	t.Run("Sends signed channel peer query", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		signedRequest := &discoveryprotos.SignedRequest{}
+		mockDiscovery := NewDiscoveryClient(controller, &discoveryprotos.QueryResult{
+			Result: &discoveryprotos.QueryResult_Members{Members: &discoveryprotos.PeerMembershipResult{}},
+		}, signedRequest)
+
+		_, err := QueryPeers(
+			ctx,
+			NewSigningIdentity(controller, []byte("SIGNATURE")),
+			WithDiscoveryClient(mockDiscovery),
+			WithChannel("CHANNEL"),
+			WithChaincode("CHAINCODE", "COLLECTION"),
+		)
+		require.NoError(t, err)
+
+		require.Equal(t, []byte("SIGNATURE"), signedRequest.GetSignature())
+
+		request, query := AssertUnmarshalQuery(t, signedRequest)
+		require.NotEmpty(t, request.GetAuthentication().GetClientIdentity())
+		require.Equal(t, "CHANNEL", query.GetChannel())
+
+		expected := &peer.ChaincodeInterest{
+			Chaincodes: []*peer.ChaincodeCall{
+				{Name: "CHAINCODE", CollectionNames: []string{"COLLECTION"}},
+			},
+		}
+		AssertProtoEqual(t, expected, query.GetPeerQuery().GetFilter())
+	})
+
+	t.Run("Sends local peer query without channel", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		signedRequest := &discoveryprotos.SignedRequest{}
+		mockDiscovery := NewDiscoveryClient(controller, &discoveryprotos.QueryResult{
+			Result: &discoveryprotos.QueryResult_Members{Members: &discoveryprotos.PeerMembershipResult{}},
+		}, signedRequest)
+
+		_, err := QueryPeers(ctx, NewSigningIdentity(controller, nil), WithDiscoveryClient(mockDiscovery))
+		require.NoError(t, err)
+
+		_, query := AssertUnmarshalQuery(t, signedRequest)
+		require.NotNil(t, query.GetLocalPeers())
+	})
+
+	t.Run("Request authentication includes TLS certificate hash", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		signedRequest := &discoveryprotos.SignedRequest{}
+		mockDiscovery := NewDiscoveryClient(controller, &discoveryprotos.QueryResult{
+			Result: &discoveryprotos.QueryResult_Members{Members: &discoveryprotos.PeerMembershipResult{}},
+		}, signedRequest)
+
+		_, err := QueryPeers(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithDiscoveryClient(mockDiscovery),
+			WithChannel("CHANNEL"),
+			WithTLSCertHash([]byte("TLS_CERT_HASH")),
+		)
+		require.NoError(t, err)
+
+		request, _ := AssertUnmarshalQuery(t, signedRequest)
+		require.Equal(t, []byte("TLS_CERT_HASH"), request.GetAuthentication().GetClientTlsCertHash())
+	})
+
+	t.Run("Returns peers sorted by MSP ID and endpoint", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockDiscovery := NewDiscoveryClient(controller, &discoveryprotos.QueryResult{
+			Result: &discoveryprotos.QueryResult_Members{
+				Members: &discoveryprotos.PeerMembershipResult{
+					PeersByOrg: map[string]*discoveryprotos.Peers{
+						"Org2MSP": {Peers: []*discoveryprotos.Peer{NewPeer(t, "Org2MSP", "peer0.org2:7051", 5)}},
+						"Org1MSP": {Peers: []*discoveryprotos.Peer{
+							NewPeer(t, "Org1MSP", "peer1.org1:7051", 4),
+							NewPeer(t, "Org1MSP", "peer0.org1:7051", 5, "CHAINCODE"),
+						}},
+					},
+				},
+			},
+		}, &discoveryprotos.SignedRequest{})
+
+		actual, err := QueryPeers(ctx, NewSigningIdentity(controller, nil), WithDiscoveryClient(mockDiscovery), WithChannel("CHANNEL"))
+		require.NoError(t, err)
+
+		require.Len(t, actual, 3)
+		require.Equal(t, "Org1MSP", actual[0].MspID)
+		require.Equal(t, "peer0.org1:7051", actual[0].Endpoint)
+		require.EqualValues(t, 5, actual[0].LedgerHeight)
+		require.Equal(t, []*Chaincode{{Name: "CHAINCODE", Version: "1.0"}}, actual[0].Chaincodes)
+		require.Equal(t, "peer1.org1:7051", actual[1].Endpoint)
+		require.Equal(t, "Org2MSP", actual[2].MspID)
+	})
+
+	t.Run("Query error result gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockDiscovery := NewDiscoveryClient(controller, &discoveryprotos.QueryResult{
+			Result: &discoveryprotos.QueryResult_Error{Error: &discoveryprotos.Error{Content: "ACCESS_DENIED"}},
+		}, &discoveryprotos.SignedRequest{})
+
+		_, err := QueryPeers(ctx, NewSigningIdentity(controller, nil), WithDiscoveryClient(mockDiscovery), WithChannel("CHANNEL"))
+		require.ErrorContains(t, err, "ACCESS_DENIED")
+	})
+
+	t.Run("gRPC error is returned", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		mockDiscovery := NewMockDiscoveryClient(controller)
+		mockDiscovery.EXPECT().Discover(ctx, gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("DISCOVER_ERROR"))
+
+		_, err := QueryPeers(ctx, NewSigningIdentity(controller, nil), WithDiscoveryClient(mockDiscovery), WithChannel("CHANNEL"))
+		require.ErrorContains(t, err, "DISCOVER_ERROR")
+	})
+}
+
+func TestQueryConfig(t *testing.T) {
+	t.Run("Missing channel gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := QueryConfig(ctx, NewSigningIdentity(controller, nil), WithDiscoveryClient(NewMockDiscoveryClient(controller)))
+		require.ErrorContains(t, err, "channel")
+	})
+
+	t.Run("Returns MSPs and orderer endpoints", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		signedRequest := &discoveryprotos.SignedRequest{}
+		org1MSP := &msp.FabricMSPConfig{Name: "Org1MSP", RootCerts: [][]byte{[]byte("ROOT")}}
+		mockDiscovery := NewDiscoveryClient(controller, &discoveryprotos.QueryResult{
+			Result: &discoveryprotos.QueryResult_ConfigResult{
+				ConfigResult: &discoveryprotos.ConfigResult{
+					Msps: map[string]*msp.FabricMSPConfig{"Org1MSP": org1MSP},
+					Orderers: map[string]*discoveryprotos.Endpoints{
+						"OrdererMSP": {Endpoint: []*discoveryprotos.Endpoint{
+							{Host: "orderer0", Port: 7050},
+							{Host: "orderer1", Port: 8050},
+						}},
+					},
+				},
+			},
+		}, signedRequest)
+
+		actual, err := QueryConfig(ctx, NewSigningIdentity(controller, nil), WithDiscoveryClient(mockDiscovery), WithChannel("CHANNEL"))
+		require.NoError(t, err)
+
+		_, query := AssertUnmarshalQuery(t, signedRequest)
+		require.Equal(t, "CHANNEL", query.GetChannel())
+		require.NotNil(t, query.GetConfigQuery())
+
+		require.Len(t, actual.MSPs, 1)
+		AssertProtoEqual(t, org1MSP, actual.MSPs["Org1MSP"])
+		require.Equal(t, map[string][]string{"OrdererMSP": {"orderer0:7050", "orderer1:8050"}}, actual.Orderers)
+	})
+}
+
+func TestQueryEndorsers(t *testing.T) {
+	t.Run("Missing chaincode gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := QueryEndorsers(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithDiscoveryClient(NewMockDiscoveryClient(controller)),
+			WithChannel("CHANNEL"),
+		)
+		require.ErrorContains(t, err, "chaincode")
+	})
+
+	t.Run("Returns endorsement plan", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		signedRequest := &discoveryprotos.SignedRequest{}
+		mockDiscovery := NewDiscoveryClient(controller, &discoveryprotos.QueryResult{
+			Result: &discoveryprotos.QueryResult_CcQueryRes{
+				CcQueryRes: &discoveryprotos.ChaincodeQueryResult{
+					Content: []*discoveryprotos.EndorsementDescriptor{
+						{
+							Chaincode: "CHAINCODE",
+							EndorsersByGroups: map[string]*discoveryprotos.Peers{
+								"G0": {Peers: []*discoveryprotos.Peer{NewPeer(t, "Org1MSP", "peer0.org1:7051", 5)}},
+								"G1": {Peers: []*discoveryprotos.Peer{NewPeer(t, "Org2MSP", "peer0.org2:7051", 5)}},
+							},
+							Layouts: []*discoveryprotos.Layout{
+								{QuantitiesByGroup: map[string]uint32{"G0": 1, "G1": 1}},
+							},
+						},
+					},
+				},
+			},
+		}, signedRequest)
+
+		actual, err := QueryEndorsers(
+			ctx,
+			NewSigningIdentity(controller, nil),
+			WithDiscoveryClient(mockDiscovery),
+			WithChannel("CHANNEL"),
+			WithChaincode("CHAINCODE"),
+			WithChaincode("OTHER_CHAINCODE"),
+		)
+		require.NoError(t, err)
+
+		_, query := AssertUnmarshalQuery(t, signedRequest)
+		require.Len(t, query.GetCcQuery().GetInterests(), 1)
+		require.Len(t, query.GetCcQuery().GetInterests()[0].GetChaincodes(), 2)
+
+		require.Equal(t, "CHAINCODE", actual.Chaincode)
+		require.Len(t, actual.Groups["G0"], 1)
+		require.Equal(t, "peer0.org1:7051", actual.Groups["G0"][0].Endpoint)
+		require.Equal(t, "Org2MSP", actual.Groups["G1"][0].MspID)
+		require.Equal(t, []map[string]uint32{{"G0": 1, "G1": 1}}, actual.Layouts)
+	})
+}
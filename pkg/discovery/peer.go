@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	"fmt"
+	"sort"
+
+	discoveryprotos "github.com/hyperledger/fabric-protos-go-apiv2/discovery"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gossip"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"google.golang.org/protobuf/proto"
+)
+
+// Peer found by service discovery.
+type Peer struct {
+	MspID string
+	// Endpoint of the peer, as host:port.
+	Endpoint string
+	// Identity of the peer, as a serialized identity.
+	Identity []byte
+	// LedgerHeight of the peer for the queried channel. This is zero for peers that are not channel members.
+	LedgerHeight uint64
+	// Chaincodes installed on the peer for the queried channel.
+	Chaincodes []*Chaincode
+}
+
+// Chaincode installed on a peer.
+type Chaincode struct {
+	Name    string
+	Version string
+}
+
+func peersFromMembers(members *discoveryprotos.PeerMembershipResult) ([]*Peer, error) {
+	var results []*Peer
+
+	for _, peers := range members.GetPeersByOrg() {
+		orgPeers, err := peersFromProtos(peers.GetPeers())
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, orgPeers...)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].MspID != results[j].MspID {
+			return results[i].MspID < results[j].MspID
+		}
+		return results[i].Endpoint < results[j].Endpoint
+	})
+
+	return results, nil
+}
+
+func peersFromProtos(peers []*discoveryprotos.Peer) ([]*Peer, error) {
+	results := make([]*Peer, 0, len(peers))
+
+	for _, p := range peers {
+		result, err := peerFromProto(p)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func peerFromProto(p *discoveryprotos.Peer) (*Peer, error) {
+	serializedIdentity := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(p.GetIdentity(), serializedIdentity); err != nil {
+		return nil, fmt.Errorf("failed to deserialize peer identity: %w", err)
+	}
+
+	result := &Peer{
+		MspID:    serializedIdentity.GetMspid(),
+		Identity: p.GetIdentity(),
+	}
+
+	if p.GetMembershipInfo() != nil {
+		message, err := gossipMessage(p.GetMembershipInfo())
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize peer membership info: %w", err)
+		}
+		result.Endpoint = message.GetAliveMsg().GetMembership().GetEndpoint()
+	}
+
+	if p.GetStateInfo() != nil {
+		message, err := gossipMessage(p.GetStateInfo())
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize peer state info: %w", err)
+		}
+
+		properties := message.GetStateInfo().GetProperties()
+		result.LedgerHeight = properties.GetLedgerHeight()
+		for _, chaincode := range properties.GetChaincodes() {
+			result.Chaincodes = append(result.Chaincodes, &Chaincode{
+				Name:    chaincode.GetName(),
+				Version: chaincode.GetVersion(),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func gossipMessage(envelope *gossip.Envelope) (*gossip.GossipMessage, error) {
+	message := &gossip.GossipMessage{}
+	if err := proto.Unmarshal(envelope.GetPayload(), message); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
@@ -0,0 +1,651 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package genesis creates channel genesis blocks from a declarative channel profile, equivalent to the blocks
+// produced by the configtxgen tool. A genesis block is required to create a channel using the channel participation
+// API of ordering service nodes.
+package genesis
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/envelope"
+	"github.com/bestbeforetoday/fabric-admin/pkg/channelconfig"
+	mspdir "github.com/bestbeforetoday/fabric-admin/pkg/msp"
+	"github.com/bestbeforetoday/fabric-admin/pkg/policydsl"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer/etcdraft"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	implicitMetaPolicyType = "ImplicitMeta"
+	signaturePolicyType    = "Signature"
+
+	ordererAdminsPolicy = "/Channel/Orderer/Admins"
+	defaultCapability   = "V2_0"
+)
+
+// NewBlock creates a genesis block for the named channel from the supplied channel profile.
+func NewBlock(channelName string, profile *Profile) (*common.Block, error) {
+	channelConfig, err := NewConfig(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGenesisBlock(channelName, channelConfig)
+}
+
+// NewConfig creates the initial channel config described by the supplied channel profile.
+func NewConfig(profile *Profile) (*common.Config, error) {
+	if profile.Orderer == nil {
+		return nil, errors.New("no orderer section in profile")
+	}
+
+	channelGroup, err := profile.channelGroup()
+	if err != nil {
+		return nil, err
+	}
+
+	channelConfig := &common.Config{
+		ChannelGroup: channelGroup,
+	}
+
+	if channelConfig, err = profile.applyOrdererConsenters(channelConfig); err != nil {
+		return nil, err
+	}
+
+	if channelConfig, err = profile.applyEndpoints(channelConfig); err != nil {
+		return nil, err
+	}
+
+	return channelConfig, nil
+}
+
+func (p *Profile) channelGroup() (*common.ConfigGroup, error) {
+	group := newConfigGroup()
+
+	if err := setPolicies(group, p.Policies, defaultPolicies()); err != nil {
+		return nil, err
+	}
+
+	if err := setValue(group, channelconfig.HashingAlgorithmKey, &common.HashingAlgorithm{Name: "SHA256"}, channelconfig.AdminsPolicyKey); err != nil {
+		return nil, err
+	}
+
+	blockDataHashingStructure := &common.BlockDataHashingStructure{
+		Width: math.MaxUint32,
+	}
+	if err := setValue(group, channelconfig.BlockDataHashingStructureKey, blockDataHashingStructure, channelconfig.AdminsPolicyKey); err != nil {
+		return nil, err
+	}
+
+	if len(p.Orderer.Addresses) > 0 {
+		ordererAddresses := &common.OrdererAddresses{
+			Addresses: p.Orderer.Addresses,
+		}
+		if err := setValue(group, channelconfig.OrdererAddressesKey, ordererAddresses, ordererAdminsPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := setCapabilities(group, p.Capabilities); err != nil {
+		return nil, err
+	}
+
+	ordererGroup, err := p.ordererGroup()
+	if err != nil {
+		return nil, err
+	}
+	group.Groups[channelconfig.OrdererGroupKey] = ordererGroup
+
+	if p.Application != nil {
+		applicationGroup, err := p.applicationGroup()
+		if err != nil {
+			return nil, err
+		}
+		group.Groups[channelconfig.ApplicationGroupKey] = applicationGroup
+	}
+
+	return group, nil
+}
+
+func (p *Profile) ordererGroup() (*common.ConfigGroup, error) {
+	ordererProfile := p.Orderer
+	group := newConfigGroup()
+
+	defaults := defaultPolicies()
+	defaults[channelconfig.BlockValidationPolicyKey] = Policy{Type: implicitMetaPolicyType, Rule: "ANY Writers"}
+	if err := setPolicies(group, ordererProfile.Policies, defaults); err != nil {
+		return nil, err
+	}
+
+	consensusType, err := p.consensusType()
+	if err != nil {
+		return nil, err
+	}
+	if err := setValue(group, channelconfig.ConsensusTypeKey, consensusType, channelconfig.AdminsPolicyKey); err != nil {
+		return nil, err
+	}
+
+	batchSize := &orderer.BatchSize{
+		MaxMessageCount:   valueOrDefault(ordererProfile.BatchSize.MaxMessageCount, 500),
+		AbsoluteMaxBytes:  uint32(valueOrDefault(ordererProfile.BatchSize.AbsoluteMaxBytes, 10<<20)),
+		PreferredMaxBytes: uint32(valueOrDefault(ordererProfile.BatchSize.PreferredMaxBytes, 2<<20)),
+	}
+	if err := setValue(group, channelconfig.BatchSizeKey, batchSize, channelconfig.AdminsPolicyKey); err != nil {
+		return nil, err
+	}
+
+	batchTimeout := &orderer.BatchTimeout{
+		Timeout: valueOrDefault(ordererProfile.BatchTimeout, "2s"),
+	}
+	if err := setValue(group, channelconfig.BatchTimeoutKey, batchTimeout, channelconfig.AdminsPolicyKey); err != nil {
+		return nil, err
+	}
+
+	if err := setCapabilities(group, ordererProfile.Capabilities); err != nil {
+		return nil, err
+	}
+
+	for _, org := range ordererProfile.Organizations {
+		orgGroup, err := p.orgGroup(org, channelconfig.NewOrdererOrgGroup)
+		if err != nil {
+			return nil, err
+		}
+		group.Groups[org.groupName()] = orgGroup
+	}
+
+	return group, nil
+}
+
+func (p *Profile) consensusType() (*orderer.ConsensusType, error) {
+	result := &orderer.ConsensusType{
+		Type:  p.Orderer.OrdererType,
+		State: orderer.ConsensusType_STATE_NORMAL,
+	}
+
+	switch p.Orderer.OrdererType {
+	case channelconfig.RaftConsensusType:
+		metadata, err := p.raftMetadata()
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Metadata, err = proto.Marshal(metadata); err != nil {
+			return nil, err
+		}
+	case "":
+		return nil, errors.New("no orderer type specified")
+	}
+
+	return result, nil
+}
+
+func (p *Profile) raftMetadata() (*etcdraft.ConfigMetadata, error) {
+	raftProfile := p.Orderer.EtcdRaft
+	if raftProfile == nil || len(raftProfile.Consenters) == 0 {
+		return nil, errors.New("no etcdraft consenters specified")
+	}
+
+	result := &etcdraft.ConfigMetadata{
+		Options: &etcdraft.Options{
+			TickInterval:         "500ms",
+			ElectionTick:         10,
+			HeartbeatTick:        1,
+			MaxInflightBlocks:    5,
+			SnapshotIntervalSize: 16 << 20,
+		},
+	}
+
+	if options := raftProfile.Options; options != nil {
+		result.Options = &etcdraft.Options{
+			TickInterval:         options.TickInterval,
+			ElectionTick:         options.ElectionTick,
+			HeartbeatTick:        options.HeartbeatTick,
+			MaxInflightBlocks:    options.MaxInflightBlocks,
+			SnapshotIntervalSize: uint32(options.SnapshotIntervalSize),
+		}
+	}
+
+	for _, consenter := range raftProfile.Consenters {
+		clientTLSCert, err := p.readFile(consenter.ClientTLSCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client TLS certificate for consenter %s:%d: %w", consenter.Host, consenter.Port, err)
+		}
+
+		serverTLSCert, err := p.readFile(consenter.ServerTLSCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read server TLS certificate for consenter %s:%d: %w", consenter.Host, consenter.Port, err)
+		}
+
+		result.Consenters = append(result.Consenters, &etcdraft.Consenter{
+			Host:          consenter.Host,
+			Port:          consenter.Port,
+			ClientTlsCert: clientTLSCert,
+			ServerTlsCert: serverTLSCert,
+		})
+	}
+
+	return result, nil
+}
+
+func (p *Profile) applyOrdererConsenters(channelConfig *common.Config) (*common.Config, error) {
+	if p.Orderer.OrdererType != channelconfig.BFTConsensusType {
+		return channelConfig, nil
+	}
+
+	if len(p.Orderer.ConsenterMapping) == 0 {
+		return nil, errors.New("no BFT consenters specified")
+	}
+
+	consenters := make([]*channelconfig.BFTConsenter, 0, len(p.Orderer.ConsenterMapping))
+	for _, consenter := range p.Orderer.ConsenterMapping {
+		bftConsenter, err := p.bftConsenter(consenter)
+		if err != nil {
+			return nil, err
+		}
+		consenters = append(consenters, bftConsenter)
+	}
+
+	channelConfig, err := channelconfig.SetBFTConsenters(channelConfig, consenters)
+	if err != nil {
+		return nil, err
+	}
+
+	return channelconfig.SetBFTOptions(channelConfig, p.Orderer.SmartBFT.bftOptions())
+}
+
+func (p *Profile) bftConsenter(consenter BFTConsenter) (*channelconfig.BFTConsenter, error) {
+	identity, err := p.readFile(consenter.Identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity for consenter %d: %w", consenter.ID, err)
+	}
+
+	clientTLSCert, err := p.readFile(consenter.ClientTLSCert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client TLS certificate for consenter %d: %w", consenter.ID, err)
+	}
+
+	serverTLSCert, err := p.readFile(consenter.ServerTLSCert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server TLS certificate for consenter %d: %w", consenter.ID, err)
+	}
+
+	result := &channelconfig.BFTConsenter{
+		ID:            consenter.ID,
+		Host:          consenter.Host,
+		Port:          consenter.Port,
+		MspID:         consenter.MSPID,
+		Identity:      identity,
+		ClientTLSCert: clientTLSCert,
+		ServerTLSCert: serverTLSCert,
+	}
+	return result, nil
+}
+
+func (options *BFTOptions) bftOptions() *channelconfig.BFTOptions {
+	if options == nil {
+		return &channelconfig.BFTOptions{
+			RequestBatchMaxCount:      100,
+			RequestBatchMaxBytes:      10 << 20,
+			RequestBatchMaxInterval:   "50ms",
+			IncomingMessageBufferSize: 200,
+			RequestPoolSize:           100000,
+			RequestForwardTimeout:     "2s",
+			RequestComplainTimeout:    "20s",
+			RequestAutoRemoveTimeout:  "3m",
+			ViewChangeResendInterval:  "5s",
+			ViewChangeTimeout:         "20s",
+			LeaderHeartbeatTimeout:    "1m",
+			LeaderHeartbeatCount:      10,
+			CollectTimeout:            "1s",
+			LeaderRotation:            channelconfig.BFTLeaderRotationOn,
+			DecisionsPerLeader:        3,
+		}
+	}
+
+	leaderRotation := channelconfig.BFTLeaderRotationOn
+	if options.LeaderRotation != nil && !*options.LeaderRotation {
+		leaderRotation = channelconfig.BFTLeaderRotationOff
+	}
+
+	return &channelconfig.BFTOptions{
+		RequestBatchMaxCount:      options.RequestBatchMaxCount,
+		RequestBatchMaxBytes:      uint64(options.RequestBatchMaxBytes),
+		RequestBatchMaxInterval:   options.RequestBatchMaxInterval,
+		IncomingMessageBufferSize: options.IncomingMessageBufferSize,
+		RequestPoolSize:           options.RequestPoolSize,
+		RequestForwardTimeout:     options.RequestForwardTimeout,
+		RequestComplainTimeout:    options.RequestComplainTimeout,
+		RequestAutoRemoveTimeout:  options.RequestAutoRemoveTimeout,
+		ViewChangeResendInterval:  options.ViewChangeResendInterval,
+		ViewChangeTimeout:         options.ViewChangeTimeout,
+		LeaderHeartbeatTimeout:    options.LeaderHeartbeatTimeout,
+		LeaderHeartbeatCount:      options.LeaderHeartbeatCount,
+		CollectTimeout:            options.CollectTimeout,
+		SyncOnStart:               options.SyncOnStart,
+		SpeedUpViewChange:         options.SpeedUpViewChange,
+		LeaderRotation:            leaderRotation,
+		DecisionsPerLeader:        options.DecisionsPerLeader,
+		RequestMaxBytes:           uint64(options.RequestMaxBytes),
+		RequestPoolSubmitTimeout:  options.RequestPoolSubmitTimeout,
+	}
+}
+
+func (p *Profile) applicationGroup() (*common.ConfigGroup, error) {
+	applicationProfile := p.Application
+	group := newConfigGroup()
+
+	defaults := defaultPolicies()
+	defaults[channelconfig.LifecycleEndorsementPolicyKey] = Policy{Type: implicitMetaPolicyType, Rule: "MAJORITY Endorsement"}
+	defaults[channelconfig.EndorsementPolicyKey] = Policy{Type: implicitMetaPolicyType, Rule: "MAJORITY Endorsement"}
+	if err := setPolicies(group, applicationProfile.Policies, defaults); err != nil {
+		return nil, err
+	}
+
+	if err := setCapabilities(group, applicationProfile.Capabilities); err != nil {
+		return nil, err
+	}
+
+	if len(applicationProfile.ACLs) > 0 {
+		acls := &peer.ACLs{
+			Acls: make(map[string]*peer.APIResource, len(applicationProfile.ACLs)),
+		}
+		for resource, policyRef := range applicationProfile.ACLs {
+			acls.Acls[resource] = &peer.APIResource{
+				PolicyRef: policyRef,
+			}
+		}
+
+		if err := setValue(group, channelconfig.ACLsKey, acls, channelconfig.AdminsPolicyKey); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, org := range applicationProfile.Organizations {
+		orgGroup, err := p.orgGroup(org, channelconfig.NewApplicationOrgGroup)
+		if err != nil {
+			return nil, err
+		}
+		group.Groups[org.groupName()] = orgGroup
+	}
+
+	return group, nil
+}
+
+func (p *Profile) orgGroup(
+	org *Organization,
+	newGroup func(*msp.FabricMSPConfig) (*common.ConfigGroup, error),
+) (*common.ConfigGroup, error) {
+	fabricMSPConfig, err := p.mspConfig(org)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := newGroup(fabricMSPConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(org.Policies) > 0 {
+		group.Policies = make(map[string]*common.ConfigPolicy)
+		if err := setPolicies(group, org.Policies, nil); err != nil {
+			return nil, fmt.Errorf("invalid policy for organization %s: %w", org.groupName(), err)
+		}
+	}
+
+	return group, nil
+}
+
+func (p *Profile) mspConfig(org *Organization) (*msp.FabricMSPConfig, error) {
+	if org.ID == "" {
+		return nil, fmt.Errorf("no MSP ID specified for organization %s", org.Name)
+	}
+
+	if org.MSP != nil {
+		result := proto.Clone(org.MSP).(*msp.FabricMSPConfig)
+		result.Name = org.ID
+		return result, nil
+	}
+
+	if org.MSPDir == "" {
+		return nil, fmt.Errorf("no MSP specified for organization %s", org.ID)
+	}
+
+	return mspdir.LoadDir(p.resolvePath(org.MSPDir), org.ID)
+}
+
+func (p *Profile) applyEndpoints(channelConfig *common.Config) (*common.Config, error) {
+	var err error
+
+	for _, org := range p.Orderer.Organizations {
+		if len(org.OrdererEndpoints) == 0 {
+			continue
+		}
+
+		if channelConfig, err = channelconfig.SetOrdererEndpoints(channelConfig, org.ID, org.OrdererEndpoints); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Application == nil {
+		return channelConfig, nil
+	}
+
+	for _, org := range p.Application.Organizations {
+		if len(org.AnchorPeers) == 0 {
+			continue
+		}
+
+		anchorPeers := make([]string, 0, len(org.AnchorPeers))
+		for _, anchorPeer := range org.AnchorPeers {
+			anchorPeers = append(anchorPeers, net.JoinHostPort(anchorPeer.Host, strconv.Itoa(int(anchorPeer.Port))))
+		}
+
+		if channelConfig, err = channelconfig.SetAnchorPeers(channelConfig, org.ID, anchorPeers); err != nil {
+			return nil, err
+		}
+	}
+
+	return channelConfig, nil
+}
+
+func (org *Organization) groupName() string {
+	if org.Name != "" {
+		return org.Name
+	}
+
+	return org.ID
+}
+
+func defaultPolicies() map[string]Policy {
+	return map[string]Policy{
+		channelconfig.ReadersPolicyKey: {Type: implicitMetaPolicyType, Rule: "ANY Readers"},
+		channelconfig.WritersPolicyKey: {Type: implicitMetaPolicyType, Rule: "ANY Writers"},
+		channelconfig.AdminsPolicyKey:  {Type: implicitMetaPolicyType, Rule: "MAJORITY Admins"},
+	}
+}
+
+// setPolicies adds the specified policies to a config group, or the default policies if none are specified.
+func setPolicies(group *common.ConfigGroup, policies map[string]Policy, defaults map[string]Policy) error {
+	if len(policies) == 0 {
+		policies = defaults
+	}
+
+	for name, policy := range policies {
+		configPolicy, err := policy.configPolicy()
+		if err != nil {
+			return fmt.Errorf("invalid %s policy: %w", name, err)
+		}
+
+		group.Policies[name] = &common.ConfigPolicy{
+			Policy:    configPolicy,
+			ModPolicy: channelconfig.AdminsPolicyKey,
+		}
+	}
+
+	return nil
+}
+
+func (policy Policy) configPolicy() (*common.Policy, error) {
+	switch policy.Type {
+	case implicitMetaPolicyType:
+		fields := strings.Fields(policy.Rule)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid implicit meta policy rule: %s", policy.Rule)
+		}
+
+		rule, ok := common.ImplicitMetaPolicy_Rule_value[strings.ToUpper(fields[0])]
+		if !ok {
+			return nil, fmt.Errorf("unknown implicit meta policy rule: %s", fields[0])
+		}
+
+		return channelconfig.ImplicitMetaPolicy(fields[1], common.ImplicitMetaPolicy_Rule(rule))
+	case signaturePolicyType:
+		return policydsl.PolicyFromString(policy.Rule)
+	default:
+		return nil, fmt.Errorf("unknown policy type: %s", policy.Type)
+	}
+}
+
+// setCapabilities adds the enabled capabilities to a config group. If no capabilities are specified, the default
+// capability is used.
+func setCapabilities(group *common.ConfigGroup, capabilities map[string]bool) error {
+	if len(capabilities) == 0 {
+		capabilities = map[string]bool{defaultCapability: true}
+	}
+
+	value := &common.Capabilities{
+		Capabilities: make(map[string]*common.Capability),
+	}
+	for name, enabled := range capabilities {
+		if enabled {
+			value.Capabilities[name] = &common.Capability{}
+		}
+	}
+
+	return setValue(group, channelconfig.CapabilitiesKey, value, channelconfig.AdminsPolicyKey)
+}
+
+func newConfigGroup() *common.ConfigGroup {
+	return &common.ConfigGroup{
+		Groups:    make(map[string]*common.ConfigGroup),
+		Values:    make(map[string]*common.ConfigValue),
+		Policies:  make(map[string]*common.ConfigPolicy),
+		ModPolicy: channelconfig.AdminsPolicyKey,
+	}
+}
+
+func setValue(group *common.ConfigGroup, key string, value proto.Message, modPolicy string) error {
+	valueBytes, err := proto.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	group.Values[key] = &common.ConfigValue{
+		Value:     valueBytes,
+		ModPolicy: modPolicy,
+	}
+	return nil
+}
+
+func valueOrDefault[T comparable](value T, defaultValue T) T {
+	var zero T
+	if value == zero {
+		return defaultValue
+	}
+
+	return value
+}
+
+func newGenesisBlock(channelName string, channelConfig *common.Config) (*common.Block, error) {
+	signatureHeader, err := newSignatureHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	channelHeader := envelope.NewChannelHeader(common.HeaderType_CONFIG, channelName)
+	channelHeader.TxId = envelope.TransactionID(signatureHeader)
+
+	configEnvelope := &common.ConfigEnvelope{
+		Config: channelConfig,
+	}
+
+	configTxEnvelope, err := envelope.NewUnsigned(channelHeader, signatureHeader, configEnvelope)
+	if err != nil {
+		return nil, err
+	}
+
+	envelopeBytes, err := proto.Marshal(configTxEnvelope)
+	if err != nil {
+		return nil, err
+	}
+
+	blockData := &common.BlockData{
+		Data: [][]byte{envelopeBytes},
+	}
+	dataHash := sha256.Sum256(bytes.Join(blockData.Data, nil))
+
+	lastConfigBytes, err := proto.Marshal(&common.LastConfig{Index: 0})
+	if err != nil {
+		return nil, err
+	}
+
+	lastConfigMetadata, err := proto.Marshal(&common.Metadata{Value: lastConfigBytes})
+	if err != nil {
+		return nil, err
+	}
+
+	ordererBlockMetadataBytes, err := proto.Marshal(&common.OrdererBlockMetadata{
+		LastConfig: &common.LastConfig{Index: 0},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signaturesMetadata, err := proto.Marshal(&common.Metadata{Value: ordererBlockMetadataBytes})
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make([][]byte, len(common.BlockMetadataIndex_name))
+	metadata[common.BlockMetadataIndex_SIGNATURES] = signaturesMetadata
+	metadata[common.BlockMetadataIndex_LAST_CONFIG] = lastConfigMetadata
+
+	block := &common.Block{
+		Header: &common.BlockHeader{
+			Number:   0,
+			DataHash: dataHash[:],
+		},
+		Data: blockData,
+		Metadata: &common.BlockMetadata{
+			Metadata: metadata,
+		},
+	}
+	return block, nil
+}
+
+func newSignatureHeader() (*common.SignatureHeader, error) {
+	nonce, err := envelope.NewNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	signatureHeader := &common.SignatureHeader{
+		Nonce: nonce,
+	}
+	return signatureHeader, nil
+}
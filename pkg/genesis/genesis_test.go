@@ -0,0 +1,366 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package genesis
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bestbeforetoday/fabric-admin/pkg/channelconfig"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// AssertUnmarshal ensures that a protobuf is umarshaled without error
+func AssertUnmarshal(t *testing.T, b []byte, m protoreflect.ProtoMessage) {
+	err := proto.Unmarshal(b, m)
+	require.NoError(t, err)
+}
+
+// AssertWriteFile ensures that a file is written, creating parent directories as required
+func AssertWriteFile(t *testing.T, filename string, content []byte) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o750))
+	require.NoError(t, os.WriteFile(filename, content, 0o600))
+}
+
+// AssertUnmarshalBlockConfig ensures that the channel header and config are unmarshaled from a genesis block
+func AssertUnmarshalBlockConfig(t *testing.T, block *common.Block) (*common.ChannelHeader, *common.Config) {
+	require.Len(t, block.GetData().GetData(), 1)
+
+	envelope := &common.Envelope{}
+	AssertUnmarshal(t, block.GetData().GetData()[0], envelope)
+
+	payload := &common.Payload{}
+	AssertUnmarshal(t, envelope.GetPayload(), payload)
+
+	channelHeader := &common.ChannelHeader{}
+	AssertUnmarshal(t, payload.GetHeader().GetChannelHeader(), channelHeader)
+
+	configEnvelope := &common.ConfigEnvelope{}
+	AssertUnmarshal(t, payload.GetData(), configEnvelope)
+
+	return channelHeader, configEnvelope.GetConfig()
+}
+
+func NewRaftProfile(certFile string) *Profile {
+	return &Profile{
+		Orderer: &Orderer{
+			OrdererType: channelconfig.RaftConsensusType,
+			Addresses:   []string{"orderer.example.com:7050"},
+			EtcdRaft: &EtcdRaft{
+				Consenters: []RaftConsenter{
+					{
+						Host:          "orderer.example.com",
+						Port:          7050,
+						ClientTLSCert: certFile,
+						ServerTLSCert: certFile,
+					},
+				},
+			},
+			Organizations: []*Organization{
+				{
+					Name:             "OrdererOrg",
+					ID:               "OrdererMSP",
+					MSP:              &msp.FabricMSPConfig{RootCerts: [][]byte{[]byte("ORDERER_CA")}},
+					OrdererEndpoints: []string{"orderer.example.com:7050"},
+				},
+			},
+		},
+		Application: &Application{
+			Organizations: []*Organization{
+				{
+					Name:        "Org1",
+					ID:          "Org1MSP",
+					MSP:         &msp.FabricMSPConfig{RootCerts: [][]byte{[]byte("ORG1_CA")}},
+					AnchorPeers: []AnchorPeer{{Host: "peer0.org1.example.com", Port: 7051}},
+				},
+			},
+		},
+	}
+}
+
+func TestGenesis(t *testing.T) {
+	t.Run("Block structure", func(t *testing.T) {
+		certFile := filepath.Join(t.TempDir(), "tls.pem")
+		AssertWriteFile(t, certFile, []byte("TLS_CERT"))
+
+		block, err := NewBlock("CHANNEL", NewRaftProfile(certFile))
+		require.NoError(t, err)
+
+		require.EqualValues(t, 0, block.GetHeader().GetNumber())
+		dataHash := sha256.Sum256(bytes.Join(block.GetData().GetData(), nil))
+		require.Equal(t, dataHash[:], block.GetHeader().GetDataHash())
+
+		channelHeader, _ := AssertUnmarshalBlockConfig(t, block)
+		require.Equal(t, "CHANNEL", channelHeader.GetChannelId())
+		require.EqualValues(t, common.HeaderType_CONFIG, channelHeader.GetType())
+		require.NotEmpty(t, channelHeader.GetTxId())
+
+		lastConfigMetadata := &common.Metadata{}
+		AssertUnmarshal(t, block.GetMetadata().GetMetadata()[common.BlockMetadataIndex_LAST_CONFIG], lastConfigMetadata)
+		lastConfig := &common.LastConfig{}
+		AssertUnmarshal(t, lastConfigMetadata.GetValue(), lastConfig)
+		require.EqualValues(t, 0, lastConfig.GetIndex())
+	})
+
+	t.Run("Raft orderer config", func(t *testing.T) {
+		certFile := filepath.Join(t.TempDir(), "tls.pem")
+		AssertWriteFile(t, certFile, []byte("TLS_CERT"))
+
+		block, err := NewBlock("CHANNEL", NewRaftProfile(certFile))
+		require.NoError(t, err)
+
+		_, config := AssertUnmarshalBlockConfig(t, block)
+
+		consenters, err := channelconfig.RaftConsenters(config)
+		require.NoError(t, err)
+		require.Len(t, consenters, 1)
+		require.Equal(t, "orderer.example.com", consenters[0].GetHost())
+		require.Equal(t, []byte("TLS_CERT"), consenters[0].GetClientTlsCert())
+
+		ordererGroup := config.GetChannelGroup().GetGroups()[channelconfig.OrdererGroupKey]
+		batchSize := &orderer.BatchSize{}
+		AssertUnmarshal(t, ordererGroup.GetValues()[channelconfig.BatchSizeKey].GetValue(), batchSize)
+		require.EqualValues(t, 500, batchSize.GetMaxMessageCount())
+		require.Contains(t, ordererGroup.GetPolicies(), channelconfig.BlockValidationPolicyKey)
+
+		ordererOrg := ordererGroup.GetGroups()["OrdererOrg"]
+		require.Contains(t, ordererOrg.GetValues(), channelconfig.EndpointsKey)
+	})
+
+	t.Run("Application config", func(t *testing.T) {
+		certFile := filepath.Join(t.TempDir(), "tls.pem")
+		AssertWriteFile(t, certFile, []byte("TLS_CERT"))
+		profile := NewRaftProfile(certFile)
+		profile.Application.ACLs = map[string]string{"_lifecycle/CommitChaincodeDefinition": "/Channel/Application/Writers"}
+
+		block, err := NewBlock("CHANNEL", profile)
+		require.NoError(t, err)
+
+		_, config := AssertUnmarshalBlockConfig(t, block)
+		applicationGroup := config.GetChannelGroup().GetGroups()[channelconfig.ApplicationGroupKey]
+		require.Contains(t, applicationGroup.GetPolicies(), channelconfig.LifecycleEndorsementPolicyKey)
+		require.Contains(t, applicationGroup.GetPolicies(), channelconfig.EndorsementPolicyKey)
+
+		acls := &peer.ACLs{}
+		AssertUnmarshal(t, applicationGroup.GetValues()[channelconfig.ACLsKey].GetValue(), acls)
+		require.Equal(t, "/Channel/Application/Writers", acls.GetAcls()["_lifecycle/CommitChaincodeDefinition"].GetPolicyRef())
+
+		org := applicationGroup.GetGroups()["Org1"]
+		mspValue := &msp.MSPConfig{}
+		AssertUnmarshal(t, org.GetValues()[channelconfig.MSPKey].GetValue(), mspValue)
+		fabricMSPConfig := &msp.FabricMSPConfig{}
+		AssertUnmarshal(t, mspValue.GetConfig(), fabricMSPConfig)
+		require.Equal(t, "Org1MSP", fabricMSPConfig.GetName())
+
+		anchorPeers := &peer.AnchorPeers{}
+		AssertUnmarshal(t, org.GetValues()[channelconfig.AnchorPeersKey].GetValue(), anchorPeers)
+		require.Equal(t, "peer0.org1.example.com", anchorPeers.GetAnchorPeers()[0].GetHost())
+	})
+
+	t.Run("Organization policy override", func(t *testing.T) {
+		certFile := filepath.Join(t.TempDir(), "tls.pem")
+		AssertWriteFile(t, certFile, []byte("TLS_CERT"))
+		profile := NewRaftProfile(certFile)
+		profile.Application.Organizations[0].Policies = map[string]Policy{
+			channelconfig.AdminsPolicyKey: {Type: "Signature", Rule: "OR('Org1MSP.admin')"},
+		}
+
+		block, err := NewBlock("CHANNEL", profile)
+		require.NoError(t, err)
+
+		_, config := AssertUnmarshalBlockConfig(t, block)
+		org := config.GetChannelGroup().GetGroups()[channelconfig.ApplicationGroupKey].GetGroups()["Org1"]
+		require.Len(t, org.GetPolicies(), 1)
+		require.EqualValues(t, common.Policy_SIGNATURE, org.GetPolicies()[channelconfig.AdminsPolicyKey].GetPolicy().GetType())
+	})
+
+	t.Run("BFT orderer config", func(t *testing.T) {
+		certFile := filepath.Join(t.TempDir(), "cert.pem")
+		AssertWriteFile(t, certFile, []byte("CERT"))
+		profile := NewRaftProfile(certFile)
+		profile.Orderer.OrdererType = channelconfig.BFTConsensusType
+		profile.Orderer.ConsenterMapping = []BFTConsenter{
+			{ID: 1, Host: "orderer.example.com", Port: 7050, MSPID: "OrdererMSP", Identity: certFile, ClientTLSCert: certFile, ServerTLSCert: certFile},
+		}
+
+		block, err := NewBlock("CHANNEL", profile)
+		require.NoError(t, err)
+
+		_, config := AssertUnmarshalBlockConfig(t, block)
+		consenters, err := channelconfig.BFTConsenters(config)
+		require.NoError(t, err)
+		require.Len(t, consenters, 1)
+		require.Equal(t, []byte("CERT"), consenters[0].Identity)
+
+		options, err := channelconfig.BFTOptionsFromConfig(config)
+		require.NoError(t, err)
+		require.Equal(t, channelconfig.BFTLeaderRotationOn, options.LeaderRotation)
+	})
+
+	for name, testCase := range map[string]struct {
+		yaml     string
+		expected channelconfig.BFTLeaderRotation
+	}{
+		"unspecified": {yaml: "RequestBatchMaxCount: 10", expected: channelconfig.BFTLeaderRotationOn},
+		"enabled":     {yaml: "LeaderRotation: true", expected: channelconfig.BFTLeaderRotationOn},
+		"disabled":    {yaml: "LeaderRotation: false", expected: channelconfig.BFTLeaderRotationOff},
+	} {
+		t.Run("BFT leader rotation "+name, func(t *testing.T) {
+			certFile := filepath.Join(t.TempDir(), "cert.pem")
+			AssertWriteFile(t, certFile, []byte("CERT"))
+			profile := NewRaftProfile(certFile)
+			profile.Orderer.OrdererType = channelconfig.BFTConsensusType
+			profile.Orderer.ConsenterMapping = []BFTConsenter{
+				{ID: 1, Host: "orderer.example.com", Port: 7050, MSPID: "OrdererMSP", Identity: certFile, ClientTLSCert: certFile, ServerTLSCert: certFile},
+			}
+			profile.Orderer.SmartBFT = &BFTOptions{}
+			require.NoError(t, yaml.Unmarshal([]byte(testCase.yaml), profile.Orderer.SmartBFT))
+
+			block, err := NewBlock("CHANNEL", profile)
+			require.NoError(t, err)
+
+			_, config := AssertUnmarshalBlockConfig(t, block)
+			options, err := channelconfig.BFTOptionsFromConfig(config)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, options.LeaderRotation)
+		})
+	}
+
+	t.Run("MSP directory", func(t *testing.T) {
+		caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("CA_CERT")})
+		tlsCACert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("TLS_CA_CERT")})
+		dir := t.TempDir()
+		AssertWriteFile(t, filepath.Join(dir, "msp", "cacerts", "ca.pem"), caCert)
+		AssertWriteFile(t, filepath.Join(dir, "msp", "tlscacerts", "tlsca.pem"), tlsCACert)
+		AssertWriteFile(t, filepath.Join(dir, "tls.pem"), []byte("TLS_CERT"))
+
+		profile := NewRaftProfile("tls.pem")
+		profile.baseDir = dir
+		profile.Application.Organizations[0].MSP = nil
+		profile.Application.Organizations[0].MSPDir = "msp"
+
+		block, err := NewBlock("CHANNEL", profile)
+		require.NoError(t, err)
+
+		_, config := AssertUnmarshalBlockConfig(t, block)
+		org := config.GetChannelGroup().GetGroups()[channelconfig.ApplicationGroupKey].GetGroups()["Org1"]
+		mspValue := &msp.MSPConfig{}
+		AssertUnmarshal(t, org.GetValues()[channelconfig.MSPKey].GetValue(), mspValue)
+		fabricMSPConfig := &msp.FabricMSPConfig{}
+		AssertUnmarshal(t, mspValue.GetConfig(), fabricMSPConfig)
+		require.Equal(t, [][]byte{caCert}, fabricMSPConfig.GetRootCerts())
+		require.Equal(t, [][]byte{tlsCACert}, fabricMSPConfig.GetTlsRootCerts())
+	})
+
+	t.Run("Missing orderer section returns error", func(t *testing.T) {
+		_, err := NewBlock("CHANNEL", &Profile{})
+		require.Error(t, err)
+	})
+
+	t.Run("Missing MSP returns error", func(t *testing.T) {
+		profile := NewRaftProfile("")
+		profile.Application.Organizations[0].MSP = nil
+
+		_, err := NewBlock("CHANNEL", profile)
+		require.ErrorContains(t, err, "Org1MSP")
+	})
+
+	t.Run("Missing consenter certificate returns error", func(t *testing.T) {
+		profile := NewRaftProfile(filepath.Join(t.TempDir(), "missing.pem"))
+
+		_, err := NewBlock("CHANNEL", profile)
+		require.ErrorContains(t, err, "orderer.example.com:7050")
+	})
+
+	t.Run("Invalid policy returns error", func(t *testing.T) {
+		profile := NewRaftProfile("")
+		profile.Policies = map[string]Policy{channelconfig.AdminsPolicyKey: {Type: "ImplicitMeta", Rule: "SOME Admins"}}
+
+		_, err := NewBlock("CHANNEL", profile)
+		require.ErrorContains(t, err, "SOME")
+	})
+}
+
+func TestProfile(t *testing.T) {
+	t.Run("Parse YAML", func(t *testing.T) {
+		profileYAML := []byte(`
+Orderer:
+  OrdererType: etcdraft
+  BatchSize:
+    MaxMessageCount: 10
+    AbsoluteMaxBytes: 99 MB
+    PreferredMaxBytes: 512 KB
+  Organizations:
+    - Name: OrdererOrg
+      ID: OrdererMSP
+      MSPDir: msp
+Application:
+  Capabilities:
+    V2_5: true
+`)
+
+		profile, err := ParseProfile(profileYAML)
+		require.NoError(t, err)
+
+		require.Equal(t, "etcdraft", profile.Orderer.OrdererType)
+		require.EqualValues(t, 99<<20, profile.Orderer.BatchSize.AbsoluteMaxBytes)
+		require.EqualValues(t, 512<<10, profile.Orderer.BatchSize.PreferredMaxBytes)
+		require.Equal(t, "OrdererMSP", profile.Orderer.Organizations[0].ID)
+		require.True(t, profile.Application.Capabilities["V2_5"])
+	})
+
+	t.Run("Parse BFT orderer YAML", func(t *testing.T) {
+		profileYAML := []byte(`
+Orderer:
+  OrdererType: BFT
+  ConsenterMapping:
+    - ID: 1
+      Host: orderer1.example.com
+      Port: 7050
+      MSPID: OrdererMSP
+      Identity: orderer1/cert.pem
+      ClientTLSCert: orderer1/tls.pem
+      ServerTLSCert: orderer1/tls.pem
+  SmartBFT:
+    RequestBatchMaxCount: 100
+    RequestBatchMaxInterval: 50ms
+`)
+
+		profile, err := ParseProfile(profileYAML)
+		require.NoError(t, err)
+
+		require.Len(t, profile.Orderer.ConsenterMapping, 1)
+		require.Equal(t, "orderer1.example.com", profile.Orderer.ConsenterMapping[0].Host)
+		require.EqualValues(t, 100, profile.Orderer.SmartBFT.RequestBatchMaxCount)
+		require.Equal(t, "50ms", profile.Orderer.SmartBFT.RequestBatchMaxInterval)
+	})
+
+	t.Run("Invalid byte size returns error", func(t *testing.T) {
+		_, err := ParseProfile([]byte("Orderer:\n  BatchSize:\n    AbsoluteMaxBytes: lots\n"))
+		require.ErrorContains(t, err, "lots")
+	})
+
+	t.Run("Load resolves paths relative to profile file", func(t *testing.T) {
+		dir := t.TempDir()
+		profileFile := filepath.Join(dir, "configtx.yaml")
+		AssertWriteFile(t, profileFile, []byte("Orderer:\n  OrdererType: etcdraft\n"))
+
+		profile, err := LoadProfile(profileFile)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "msp"), profile.resolvePath("msp"))
+	})
+}
@@ -0,0 +1,213 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package genesis
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"gopkg.in/yaml.v3"
+)
+
+// Profile describes the configuration of a channel, using a structure and naming that follows the profiles defined in
+// a configtx.yaml file used by the configtxgen tool.
+type Profile struct {
+	Policies     map[string]Policy `yaml:"Policies"`
+	Capabilities map[string]bool   `yaml:"Capabilities"`
+	Orderer      *Orderer          `yaml:"Orderer"`
+	Application  *Application      `yaml:"Application"`
+
+	// baseDir is the directory against which relative file paths are resolved.
+	baseDir string
+}
+
+// Policy is a channel config policy. Type is either ImplicitMeta, with a rule such as "MAJORITY Admins", or
+// Signature, with a rule such as "OR('Org1MSP.admin', 'Org2MSP.admin')".
+type Policy struct {
+	Type string `yaml:"Type"`
+	Rule string `yaml:"Rule"`
+}
+
+// Organization is a channel member organization. The MSP definition is either supplied directly as MSP, or loaded from
+// the MSP directory at MSPDir.
+type Organization struct {
+	Name             string               `yaml:"Name"`
+	ID               string               `yaml:"ID"`
+	MSPDir           string               `yaml:"MSPDir"`
+	MSP              *msp.FabricMSPConfig `yaml:"-"`
+	Policies         map[string]Policy    `yaml:"Policies"`
+	AnchorPeers      []AnchorPeer         `yaml:"AnchorPeers"`
+	OrdererEndpoints []string             `yaml:"OrdererEndpoints"`
+}
+
+// AnchorPeer is the endpoint of an organization's anchor peer.
+type AnchorPeer struct {
+	Host string `yaml:"Host"`
+	Port int32  `yaml:"Port"`
+}
+
+// Orderer is the orderer section of a channel profile.
+type Orderer struct {
+	OrdererType  string    `yaml:"OrdererType"`
+	Addresses    []string  `yaml:"Addresses"`
+	BatchTimeout string    `yaml:"BatchTimeout"`
+	BatchSize    BatchSize `yaml:"BatchSize"`
+	// ConsenterMapping is the BFT consenter set, used when the orderer type is BFT.
+	ConsenterMapping []BFTConsenter    `yaml:"ConsenterMapping"`
+	EtcdRaft         *EtcdRaft         `yaml:"EtcdRaft"`
+	SmartBFT         *BFTOptions       `yaml:"SmartBFT"`
+	Organizations    []*Organization   `yaml:"Organizations"`
+	Policies         map[string]Policy `yaml:"Policies"`
+	Capabilities     map[string]bool   `yaml:"Capabilities"`
+}
+
+// BatchSize controls the number of messages batched into a block.
+type BatchSize struct {
+	MaxMessageCount   uint32   `yaml:"MaxMessageCount"`
+	AbsoluteMaxBytes  ByteSize `yaml:"AbsoluteMaxBytes"`
+	PreferredMaxBytes ByteSize `yaml:"PreferredMaxBytes"`
+}
+
+// EtcdRaft is the configuration of an etcdraft ordering service.
+type EtcdRaft struct {
+	Consenters []RaftConsenter `yaml:"Consenters"`
+	Options    *RaftOptions    `yaml:"Options"`
+}
+
+// RaftConsenter is a member of the etcdraft consenter set. Certificates are paths to PEM encoded certificate files.
+type RaftConsenter struct {
+	Host          string `yaml:"Host"`
+	Port          uint32 `yaml:"Port"`
+	ClientTLSCert string `yaml:"ClientTLSCert"`
+	ServerTLSCert string `yaml:"ServerTLSCert"`
+}
+
+// RaftOptions are the etcdraft consensus options.
+type RaftOptions struct {
+	TickInterval         string   `yaml:"TickInterval"`
+	ElectionTick         uint32   `yaml:"ElectionTick"`
+	HeartbeatTick        uint32   `yaml:"HeartbeatTick"`
+	MaxInflightBlocks    uint32   `yaml:"MaxInflightBlocks"`
+	SnapshotIntervalSize ByteSize `yaml:"SnapshotIntervalSize"`
+}
+
+// BFTConsenter is a member of the BFT consenter set. Identity and certificates are paths to PEM encoded certificate
+// files.
+type BFTConsenter struct {
+	ID            uint32 `yaml:"ID"`
+	Host          string `yaml:"Host"`
+	Port          uint32 `yaml:"Port"`
+	MSPID         string `yaml:"MSPID"`
+	Identity      string `yaml:"Identity"`
+	ClientTLSCert string `yaml:"ClientTLSCert"`
+	ServerTLSCert string `yaml:"ServerTLSCert"`
+}
+
+// BFTOptions are the BFT consensus options, specified in the SmartBFT section of the orderer profile.
+type BFTOptions struct {
+	RequestBatchMaxCount      uint64   `yaml:"RequestBatchMaxCount"`
+	RequestBatchMaxBytes      ByteSize `yaml:"RequestBatchMaxBytes"`
+	RequestBatchMaxInterval   string   `yaml:"RequestBatchMaxInterval"`
+	IncomingMessageBufferSize uint64   `yaml:"IncomingMessageBufferSize"`
+	RequestPoolSize           uint64   `yaml:"RequestPoolSize"`
+	RequestForwardTimeout     string   `yaml:"RequestForwardTimeout"`
+	RequestComplainTimeout    string   `yaml:"RequestComplainTimeout"`
+	RequestAutoRemoveTimeout  string   `yaml:"RequestAutoRemoveTimeout"`
+	ViewChangeResendInterval  string   `yaml:"ViewChangeResendInterval"`
+	ViewChangeTimeout         string   `yaml:"ViewChangeTimeout"`
+	LeaderHeartbeatTimeout    string   `yaml:"LeaderHeartbeatTimeout"`
+	LeaderHeartbeatCount      uint64   `yaml:"LeaderHeartbeatCount"`
+	CollectTimeout            string   `yaml:"CollectTimeout"`
+	SyncOnStart               bool     `yaml:"SyncOnStart"`
+	SpeedUpViewChange         bool     `yaml:"SpeedUpViewChange"`
+	// LeaderRotation is enabled if not specified.
+	LeaderRotation           *bool    `yaml:"LeaderRotation"`
+	DecisionsPerLeader       uint64   `yaml:"DecisionsPerLeader"`
+	RequestMaxBytes          ByteSize `yaml:"RequestMaxBytes"`
+	RequestPoolSubmitTimeout string   `yaml:"RequestPoolSubmitTimeout"`
+}
+
+// Application is the application section of a channel profile.
+type Application struct {
+	Organizations []*Organization   `yaml:"Organizations"`
+	Policies      map[string]Policy `yaml:"Policies"`
+	Capabilities  map[string]bool   `yaml:"Capabilities"`
+	ACLs          map[string]string `yaml:"ACLs"`
+}
+
+// ByteSize is a number of bytes. In YAML it can be specified either as a plain number or with a KB, MB or GB suffix,
+// such as "99 MB".
+type ByteSize uint32
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (b *ByteSize) UnmarshalYAML(node *yaml.Node) error {
+	value := strings.ToUpper(strings.TrimSpace(node.Value))
+
+	multiplier := uint64(1)
+	for suffix, size := range map[string]uint64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(value, suffix) {
+			multiplier = size
+			value = strings.TrimSpace(strings.TrimSuffix(value, suffix))
+			break
+		}
+	}
+
+	number, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || number*multiplier > 1<<32-1 {
+		return fmt.Errorf("invalid byte size: %s", node.Value)
+	}
+
+	*b = ByteSize(number * multiplier)
+	return nil
+}
+
+// LoadProfile reads a channel profile from a YAML file. Relative file paths within the profile, such as MSP
+// directories and certificate files, are resolved relative to the directory containing the profile file.
+func LoadProfile(filename string) (*Profile, error) {
+	profileBytes, err := os.ReadFile(filename) //#nosec G304 -- caller supplied profile location
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	profile, err := ParseProfile(profileBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.baseDir = filepath.Dir(filename)
+	return profile, nil
+}
+
+// ParseProfile parses a channel profile from YAML content. Relative file paths within the profile are resolved
+// relative to the current working directory.
+func ParseProfile(profileYAML []byte) (*Profile, error) {
+	profile := &Profile{}
+	if err := yaml.Unmarshal(profileYAML, profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+func (p *Profile) resolvePath(path string) string {
+	if filepath.IsAbs(path) || p.baseDir == "" {
+		return path
+	}
+
+	return filepath.Join(p.baseDir, path)
+}
+
+func (p *Profile) readFile(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	return os.ReadFile(p.resolvePath(path)) //#nosec G304 -- caller supplied profile content
+}
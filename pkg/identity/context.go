@@ -0,0 +1,46 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import "context"
+
+// ContextSigner is implemented by signing identities whose signing operations can be cancelled using a context, such
+// as those backed by an HSM or a remote signing service.
+type ContextSigner interface {
+	SignContext(ctx context.Context, message []byte) ([]byte, error)
+}
+
+// SignContext signs a message using the supplied signing identity, returning early with the context error if the
+// context is done before signing completes. Signing identities that implement ContextSigner are passed the context
+// directly. For other signing identities, Sign is invoked in a separate goroutine, which is abandoned if the context
+// is done first.
+func SignContext(ctx context.Context, signingID SigningIdentity, message []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if contextSigner, ok := signingID.(ContextSigner); ok {
+		return contextSigner.SignContext(ctx, message)
+	}
+
+	type result struct {
+		signature []byte
+		err       error
+	}
+	results := make(chan result, 1)
+
+	go func() {
+		signature, err := signingID.Sign(message)
+		results <- result{signature, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-results:
+		return r.signature, r.err
+	}
+}
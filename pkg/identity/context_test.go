@@ -0,0 +1,96 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/hash"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignContext(t *testing.T) {
+	newSigningID := func(t *testing.T, sign func([]byte) ([]byte, error)) SigningIdentity {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		return NewSigningIdentity(NewX509Identity(t, privateKey), sign, hash.SHA256)
+	}
+
+	t.Run("Returns signature", func(t *testing.T) {
+		signingID := newSigningID(t, func([]byte) ([]byte, error) {
+			return []byte("SIGNATURE"), nil
+		})
+
+		actual, err := SignContext(context.Background(), signingID, []byte("MESSAGE"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("SIGNATURE"), actual)
+	})
+
+	t.Run("Returns signing error", func(t *testing.T) {
+		expectedErr := errors.New("EXPECTED_ERROR")
+		signingID := newSigningID(t, func([]byte) ([]byte, error) {
+			return nil, expectedErr
+		})
+
+		_, err := SignContext(context.Background(), signingID, []byte("MESSAGE"))
+		require.ErrorIs(t, err, expectedErr)
+	})
+
+	t.Run("Returns when context done before signing completes", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		signingID := newSigningID(t, func([]byte) ([]byte, error) {
+			<-release
+			return nil, nil
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := SignContext(ctx, signingID, []byte("MESSAGE"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Does not sign with cancelled context", func(t *testing.T) {
+		signed := false
+		signingID := newSigningID(t, func([]byte) ([]byte, error) {
+			signed = true
+			return nil, nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := SignContext(ctx, signingID, []byte("MESSAGE"))
+		require.ErrorIs(t, err, context.Canceled)
+		require.False(t, signed)
+	})
+
+	t.Run("Passes context to context signer", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		type contextKey struct{}
+		ctx := context.WithValue(context.Background(), contextKey{}, "VALUE")
+
+		var actual context.Context
+		transport := SignTransportFunc(func(ctx context.Context, request *SignRequest) ([]byte, error) {
+			actual = ctx
+			return []byte("SIGNATURE"), nil
+		})
+		signingID := NewRemoteSigningIdentity(NewX509Identity(t, privateKey), transport, hash.SHA256, 0)
+
+		_, err = SignContext(ctx, signingID, []byte("MESSAGE"))
+		require.NoError(t, err)
+		require.Equal(t, "VALUE", actual.Value(contextKey{}))
+	})
+}
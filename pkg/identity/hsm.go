@@ -0,0 +1,103 @@
+//go:build pkcs11
+// +build pkcs11
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-gateway/pkg/hash"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// HSMOptions identifies the PKCS#11 token and private key used for signing.
+type HSMOptions struct {
+	// Library is the path of the PKCS#11 library.
+	Library string
+	// Label of the token (slot) containing the private key.
+	Label string
+	// Pin used to log in to the token.
+	Pin string
+	// Identifier is the CKA_ID of the private key. If not specified, the subject key identifier of the identity's
+	// certificate is used, which matches the key identifiers used by Fabric.
+	Identifier string
+	// UserType used to log in to the token. The default is CKU_USER.
+	UserType int
+}
+
+// HSMSigningIdentity is a signing identity whose private key is held in a PKCS#11 hardware security module. Close
+// must be called when the signing identity is no longer needed to release HSM resources.
+type HSMSigningIdentity struct {
+	SigningIdentity
+	closeSigner gatewayid.HSMSignClose
+	factory     *gatewayid.HSMSignerFactory
+}
+
+// NewHSMSigningIdentity creates a signing identity for the supplied X.509 identity, which signs using a private key
+// held in a PKCS#11 token.
+func NewHSMSigningIdentity(id *gatewayid.X509Identity, options HSMOptions) (*HSMSigningIdentity, error) {
+	identifier := options.Identifier
+	if identifier == "" {
+		var err error
+		if identifier, err = subjectKeyIdentifier(id); err != nil {
+			return nil, err
+		}
+	}
+
+	factory, err := gatewayid.NewHSMSignerFactory(options.Library)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 library: %w", err)
+	}
+
+	sign, closeSigner, err := factory.NewHSMSigner(gatewayid.HSMSignerOptions{
+		Label:      options.Label,
+		Pin:        options.Pin,
+		Identifier: identifier,
+		UserType:   options.UserType,
+	})
+	if err != nil {
+		factory.Dispose()
+		return nil, err
+	}
+
+	result := &HSMSigningIdentity{
+		SigningIdentity: NewSigningIdentity(id, sign, hash.SHA256),
+		closeSigner:     closeSigner,
+		factory:         factory,
+	}
+	return result, nil
+}
+
+// Close releases the HSM session and library resources held by the signing identity.
+func (signingID *HSMSigningIdentity) Close() error {
+	err := signingID.closeSigner()
+	signingID.factory.Dispose()
+	return err
+}
+
+// subjectKeyIdentifier returns the SHA-256 hash of the certificate's ECDSA public key point, as used by Fabric to
+// identify keys in an HSM.
+func subjectKeyIdentifier(id *gatewayid.X509Identity) (string, error) {
+	certificate, err := gatewayid.CertificateFromPEM(id.Credentials())
+	if err != nil {
+		return "", err
+	}
+
+	publicKey, ok := certificate.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return "", errors.New("HSM key identifier can only be derived from an ECDSA certificate")
+	}
+
+	//lint:ignore SA1019 Fabric derives the SKI from the uncompressed EC point, which crypto/ecdh cannot produce for an ecdsa key.
+	ski := sha256.Sum256(elliptic.Marshal(publicKey.Curve, publicKey.X, publicKey.Y))
+	return string(ski[:]), nil
+}
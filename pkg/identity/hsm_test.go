@@ -0,0 +1,210 @@
+//go:build pkcs11
+// +build pkcs11
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests run against a PKCS#11 token, such as SoftHSM, and are skipped unless the following environment variables are
+// set:
+//   - PKCS11_LIBRARY: path of the PKCS#11 library, such as /usr/lib/softhsm/libsofthsm2.so
+//   - PKCS11_LABEL: label of an initialized token
+//   - PKCS11_PIN: user PIN for the token
+const (
+	pkcs11LibraryEnv = "PKCS11_LIBRARY"
+	pkcs11LabelEnv   = "PKCS11_LABEL"
+	pkcs11PinEnv     = "PKCS11_PIN"
+)
+
+func NewHSMOptions(t *testing.T) HSMOptions {
+	options := HSMOptions{
+		Library: os.Getenv(pkcs11LibraryEnv),
+		Label:   os.Getenv(pkcs11LabelEnv),
+		Pin:     os.Getenv(pkcs11PinEnv),
+	}
+	if options.Library == "" || options.Label == "" || options.Pin == "" {
+		t.Skipf("%s, %s and %s must be set to run HSM tests", pkcs11LibraryEnv, pkcs11LabelEnv, pkcs11PinEnv)
+	}
+
+	return options
+}
+
+// WithHSMSession runs the supplied function with a logged in read/write session for the configured token.
+func WithHSMSession(t *testing.T, options HSMOptions, f func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle)) {
+	ctx := pkcs11.New(options.Library)
+	require.NotNil(t, ctx, "PKCS#11 library")
+	defer ctx.Destroy()
+	require.NoError(t, ctx.Initialize())
+	defer ctx.Finalize()
+
+	slots, err := ctx.GetSlotList(true)
+	require.NoError(t, err)
+
+	for _, slot := range slots {
+		tokenInfo, err := ctx.GetTokenInfo(slot)
+		require.NoError(t, err)
+		if tokenInfo.Label != options.Label {
+			continue
+		}
+
+		session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		require.NoError(t, err)
+		defer ctx.CloseSession(session)
+
+		require.NoError(t, ctx.Login(session, pkcs11.CKU_USER, options.Pin))
+		defer ctx.Logout(session)
+
+		f(ctx, session)
+		return
+	}
+
+	require.FailNow(t, "no token found with label "+options.Label)
+}
+
+// NewHSMKey generates an ECDSA P-256 private key in the token, with a CKA_ID matching the Fabric subject key
+// identifier, and returns the public key. The private key is removed from the token when the test completes.
+func NewHSMKey(t *testing.T, options HSMOptions) *ecdsa.PublicKey {
+	var result *ecdsa.PublicKey
+	var ski [sha256.Size]byte
+
+	WithHSMSession(t, options, func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) {
+		curveOID, err := asn1.Marshal(asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7})
+		require.NoError(t, err)
+
+		publicKeyHandle, privateKeyHandle, err := ctx.GenerateKeyPair(
+			session,
+			[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+			[]*pkcs11.Attribute{
+				pkcs11.NewAttribute(pkcs11.CKA_TOKEN, false),
+				pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+				pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, curveOID),
+			},
+			[]*pkcs11.Attribute{
+				pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+				pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+				pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+			},
+		)
+		require.NoError(t, err)
+
+		attributes, err := ctx.GetAttributeValue(session, publicKeyHandle, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+		})
+		require.NoError(t, err)
+
+		var point []byte
+		_, err = asn1.Unmarshal(attributes[0].Value, &point)
+		require.NoError(t, err)
+
+		//lint:ignore SA1019 PKCS#11 returns the public key as a raw uncompressed EC point.
+		x, y := elliptic.Unmarshal(elliptic.P256(), point)
+		require.NotNil(t, x, "EC point")
+
+		ski = sha256.Sum256(point)
+		require.NoError(t, ctx.SetAttributeValue(session, privateKeyHandle, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_ID, ski[:]),
+		}))
+
+		result = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	})
+
+	t.Cleanup(func() {
+		WithHSMSession(t, options, func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) {
+			require.NoError(t, ctx.FindObjectsInit(session, []*pkcs11.Attribute{
+				pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+				pkcs11.NewAttribute(pkcs11.CKA_ID, ski[:]),
+			}))
+			handles, _, err := ctx.FindObjects(session, 1)
+			require.NoError(t, ctx.FindObjectsFinal(session))
+			require.NoError(t, err)
+
+			for _, handle := range handles {
+				require.NoError(t, ctx.DestroyObject(session, handle))
+			}
+		})
+	})
+
+	return result
+}
+
+func NewX509IdentityForPublicKey(t *testing.T, publicKey *ecdsa.PublicKey) *gatewayid.X509Identity {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "hsm-user"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, publicKey, caKey)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(certificateDER)
+	require.NoError(t, err)
+
+	id, err := gatewayid.NewX509Identity("MSP_ID", certificate)
+	require.NoError(t, err)
+
+	return id
+}
+
+func TestHSMSigningIdentity(t *testing.T) {
+	t.Run("Signs using key identified by certificate", func(t *testing.T) {
+		options := NewHSMOptions(t)
+		publicKey := NewHSMKey(t, options)
+		id := NewX509IdentityForPublicKey(t, publicKey)
+
+		signingID, err := NewHSMSigningIdentity(id, options)
+		require.NoError(t, err)
+		defer signingID.Close()
+
+		require.Equal(t, "MSP_ID", signingID.MspID())
+		AssertSigns(t, signingID, publicKey)
+	})
+
+	t.Run("Unknown key identifier gives error", func(t *testing.T) {
+		options := NewHSMOptions(t)
+		options.Identifier = "UNKNOWN_KEY"
+		id := NewX509IdentityForPublicKey(t, &NewCredentialsKey(t).PublicKey)
+
+		_, err := NewHSMSigningIdentity(id, options)
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid library gives error", func(t *testing.T) {
+		options := NewHSMOptions(t)
+		options.Library = "/no/such/library.so"
+		id := NewX509IdentityForPublicKey(t, &NewCredentialsKey(t).PublicKey)
+
+		_, err := NewHSMSigningIdentity(id, options)
+		require.ErrorContains(t, err, "PKCS#11 library")
+	})
+}
+
+func NewCredentialsKey(t *testing.T) *ecdsa.PrivateKey {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return privateKey
+}
@@ -0,0 +1,169 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+const walletX509Type = "X.509"
+
+// WalletEntry is the JSON content of an identity stored in a Hyperledger Fabric SDK file system wallet.
+type WalletEntry struct {
+	Credentials struct {
+		Certificate string `json:"certificate"`
+		PrivateKey  string `json:"privateKey"`
+	} `json:"credentials"`
+	MspID   string `json:"mspId"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+// NewSigningIdentityFromPEM creates a signing identity from a PEM encoded X.509 certificate and private key.
+func NewSigningIdentityFromPEM(mspID string, certificatePEM []byte, privateKeyPEM []byte) (SigningIdentity, error) {
+	certificate, err := gatewayid.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	privateKey, err := gatewayid.PrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	return newX509SigningIdentity(mspID, certificate, privateKey)
+}
+
+// NewSigningIdentityFromFiles creates a signing identity from files containing a PEM encoded X.509 certificate and
+// private key.
+func NewSigningIdentityFromFiles(mspID string, certificateFile string, privateKeyFile string) (SigningIdentity, error) {
+	certificatePEM, err := os.ReadFile(certificateFile) //#nosec G304 -- caller supplied certificate location
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate file: %w", err)
+	}
+
+	privateKeyPEM, err := os.ReadFile(privateKeyFile) //#nosec G304 -- caller supplied private key location
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key file: %w", err)
+	}
+
+	return NewSigningIdentityFromPEM(mspID, certificatePEM, privateKeyPEM)
+}
+
+// NewSigningIdentityFromMSPDir creates a signing identity from the signcerts and keystore subdirectories of a local
+// MSP directory. Where the keystore contains several private keys, the key matching the signing certificate is used.
+func NewSigningIdentityFromMSPDir(mspID string, dir string) (SigningIdentity, error) {
+	certificatePEMs, err := readDirFiles(filepath.Join(dir, "signcerts"))
+	if err != nil {
+		return nil, err
+	}
+	if len(certificatePEMs) == 0 {
+		return nil, fmt.Errorf("no signing certificate found in MSP directory: %s", dir)
+	}
+
+	certificate, err := gatewayid.CertificateFromPEM(certificatePEMs[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing certificate: %w", err)
+	}
+
+	privateKeyPEMs, err := readDirFiles(filepath.Join(dir, "keystore"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, privateKeyPEM := range privateKeyPEMs {
+		privateKey, err := gatewayid.PrivateKeyFromPEM(privateKeyPEM)
+		if err != nil {
+			continue
+		}
+
+		if matchesCertificate(privateKey, certificate) {
+			return newX509SigningIdentity(mspID, certificate, privateKey)
+		}
+	}
+
+	return nil, fmt.Errorf("no private key matching the signing certificate found in MSP directory: %s", dir)
+}
+
+// NewSigningIdentityFromWalletEntry creates a signing identity from the JSON content of an X.509 identity stored in a
+// Hyperledger Fabric SDK file system wallet.
+func NewSigningIdentityFromWalletEntry(entryJSON []byte) (SigningIdentity, error) {
+	entry := &WalletEntry{}
+	if err := json.Unmarshal(entryJSON, entry); err != nil {
+		return nil, fmt.Errorf("failed to parse wallet entry: %w", err)
+	}
+
+	if entry.Type != walletX509Type {
+		return nil, fmt.Errorf("unsupported wallet identity type: %s", entry.Type)
+	}
+
+	return NewSigningIdentityFromPEM(entry.MspID, []byte(entry.Credentials.Certificate), []byte(entry.Credentials.PrivateKey))
+}
+
+// NewSigningIdentityFromWallet creates a signing identity from the identity with the given label in a Hyperledger
+// Fabric SDK file system wallet directory.
+func NewSigningIdentityFromWallet(walletDir string, label string) (SigningIdentity, error) {
+	entryJSON, err := os.ReadFile(filepath.Join(walletDir, label+".id")) //#nosec G304 -- caller supplied wallet location
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wallet entry: %w", err)
+	}
+
+	return NewSigningIdentityFromWalletEntry(entryJSON)
+}
+
+func newX509SigningIdentity(mspID string, certificate *x509.Certificate, privateKey crypto.PrivateKey) (SigningIdentity, error) {
+	if !matchesCertificate(privateKey, certificate) {
+		return nil, errors.New("private key does not match certificate")
+	}
+
+	id, err := gatewayid.NewX509Identity(mspID, certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrivateKeySigningIdentity(id, privateKey)
+}
+
+func matchesCertificate(privateKey crypto.PrivateKey, certificate *x509.Certificate) bool {
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return false
+	}
+
+	publicKey, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && publicKey.Equal(certificate.PublicKey)
+}
+
+// readDirFiles returns the content of all files in a directory, in file name order.
+func readDirFiles(dir string) ([][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var results [][]byte
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name())) //#nosec G304 -- caller supplied MSP directory
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, content)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type credentials struct {
+	certificatePEM []byte
+	privateKeyPEM  []byte
+	publicKey      *ecdsa.PublicKey
+}
+
+func NewCredentials(t *testing.T) *credentials {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	require.NoError(t, err)
+
+	privateKeyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	return &credentials{
+		certificatePEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER}),
+		privateKeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDER}),
+		publicKey:      &privateKey.PublicKey,
+	}
+}
+
+// AssertWriteFile ensures that a file is written, creating parent directories as required
+func AssertWriteFile(t *testing.T, filename string, content []byte) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o750))
+	require.NoError(t, os.WriteFile(filename, content, 0o600))
+}
+
+// AssertSigns ensures that a signing identity creates signatures that verify with the expected public key
+func AssertSigns(t *testing.T, signingID SigningIdentity, publicKey *ecdsa.PublicKey) {
+	message := []byte("MESSAGE")
+	signature, err := signingID.Sign(message)
+	require.NoError(t, err)
+
+	digest := sha256.Sum256(message)
+	require.True(t, ecdsa.VerifyASN1(publicKey, digest[:], signature), "signature verification")
+}
+
+func TestNewSigningIdentityFromPEM(t *testing.T) {
+	t.Run("Creates signing identity", func(t *testing.T) {
+		creds := NewCredentials(t)
+
+		signingID, err := NewSigningIdentityFromPEM("MSP_ID", creds.certificatePEM, creds.privateKeyPEM)
+		require.NoError(t, err)
+
+		require.Equal(t, "MSP_ID", signingID.MspID())
+		require.Equal(t, creds.certificatePEM, signingID.Credentials())
+		AssertSigns(t, signingID, creds.publicKey)
+	})
+
+	t.Run("Mismatched private key gives error", func(t *testing.T) {
+		_, err := NewSigningIdentityFromPEM("MSP_ID", NewCredentials(t).certificatePEM, NewCredentials(t).privateKeyPEM)
+		require.ErrorContains(t, err, "does not match")
+	})
+
+	t.Run("Invalid certificate gives error", func(t *testing.T) {
+		_, err := NewSigningIdentityFromPEM("MSP_ID", []byte("BAD"), NewCredentials(t).privateKeyPEM)
+		require.ErrorContains(t, err, "certificate")
+	})
+}
+
+func TestNewSigningIdentityFromFiles(t *testing.T) {
+	t.Run("Creates signing identity", func(t *testing.T) {
+		creds := NewCredentials(t)
+		dir := t.TempDir()
+		AssertWriteFile(t, filepath.Join(dir, "cert.pem"), creds.certificatePEM)
+		AssertWriteFile(t, filepath.Join(dir, "key.pem"), creds.privateKeyPEM)
+
+		signingID, err := NewSigningIdentityFromFiles("MSP_ID", filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+		require.NoError(t, err)
+
+		AssertSigns(t, signingID, creds.publicKey)
+	})
+
+	t.Run("Missing file gives error", func(t *testing.T) {
+		dir := t.TempDir()
+
+		_, err := NewSigningIdentityFromFiles("MSP_ID", filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+		require.ErrorContains(t, err, "certificate file")
+	})
+}
+
+func TestNewSigningIdentityFromMSPDir(t *testing.T) {
+	t.Run("Uses private key matching signing certificate", func(t *testing.T) {
+		creds := NewCredentials(t)
+		dir := t.TempDir()
+		AssertWriteFile(t, filepath.Join(dir, "signcerts", "cert.pem"), creds.certificatePEM)
+		AssertWriteFile(t, filepath.Join(dir, "keystore", "a_sk"), NewCredentials(t).privateKeyPEM)
+		AssertWriteFile(t, filepath.Join(dir, "keystore", "b_sk"), creds.privateKeyPEM)
+
+		signingID, err := NewSigningIdentityFromMSPDir("MSP_ID", dir)
+		require.NoError(t, err)
+
+		require.Equal(t, "MSP_ID", signingID.MspID())
+		AssertSigns(t, signingID, creds.publicKey)
+	})
+
+	t.Run("No matching private key gives error", func(t *testing.T) {
+		dir := t.TempDir()
+		AssertWriteFile(t, filepath.Join(dir, "signcerts", "cert.pem"), NewCredentials(t).certificatePEM)
+		AssertWriteFile(t, filepath.Join(dir, "keystore", "priv_sk"), NewCredentials(t).privateKeyPEM)
+
+		_, err := NewSigningIdentityFromMSPDir("MSP_ID", dir)
+		require.ErrorContains(t, err, dir)
+	})
+
+	t.Run("Empty signcerts gives error", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "signcerts"), 0o750))
+
+		_, err := NewSigningIdentityFromMSPDir("MSP_ID", dir)
+		require.ErrorContains(t, err, "signing certificate")
+	})
+}
+
+func TestNewSigningIdentityFromWallet(t *testing.T) {
+	newEntry := func(t *testing.T, creds *credentials, identityType string) []byte {
+		entry := &WalletEntry{
+			MspID:   "MSP_ID",
+			Type:    identityType,
+			Version: 1,
+		}
+		entry.Credentials.Certificate = string(creds.certificatePEM)
+		entry.Credentials.PrivateKey = string(creds.privateKeyPEM)
+
+		result, err := json.Marshal(entry)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("Creates signing identity from wallet directory", func(t *testing.T) {
+		creds := NewCredentials(t)
+		dir := t.TempDir()
+		AssertWriteFile(t, filepath.Join(dir, "appUser.id"), newEntry(t, creds, "X.509"))
+
+		signingID, err := NewSigningIdentityFromWallet(dir, "appUser")
+		require.NoError(t, err)
+
+		require.Equal(t, "MSP_ID", signingID.MspID())
+		AssertSigns(t, signingID, creds.publicKey)
+	})
+
+	t.Run("Unsupported identity type gives error", func(t *testing.T) {
+		_, err := NewSigningIdentityFromWalletEntry(newEntry(t, NewCredentials(t), "HSM-X.509"))
+		require.ErrorContains(t, err, "HSM-X.509")
+	})
+
+	t.Run("Invalid JSON gives error", func(t *testing.T) {
+		_, err := NewSigningIdentityFromWalletEntry([]byte("{"))
+		require.ErrorContains(t, err, "wallet entry")
+	})
+
+	t.Run("Missing label gives error", func(t *testing.T) {
+		_, err := NewSigningIdentityFromWallet(t.TempDir(), "missing")
+		require.ErrorContains(t, err, "wallet entry")
+	})
+}
@@ -0,0 +1,54 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/hyperledger/fabric-gateway/pkg/hash"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// NewPrivateKeySigningIdentity creates a signing identity that signs using the supplied private key. The appropriate
+// signing scheme is selected based on the key type:
+//   - ECDSA keys sign a SHA-256 digest of the message.
+//   - Ed25519 keys sign the whole message.
+//   - RSA keys sign a SHA-256 digest of the message using PKCS #1 v1.5.
+func NewPrivateKeySigningIdentity(id gatewayid.Identity, privateKey crypto.PrivateKey) (SigningIdentity, error) {
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		sign, err := gatewayid.NewPrivateKeySign(key)
+		if err != nil {
+			return nil, err
+		}
+		return NewSigningIdentity(id, sign, hash.SHA256), nil
+	case ed25519.PrivateKey:
+		return NewSigningIdentity(id, ed25519PrivateKeySign(key), nil), nil
+	case *ed25519.PrivateKey:
+		return NewSigningIdentity(id, ed25519PrivateKeySign(*key), nil), nil
+	case *rsa.PrivateKey:
+		return NewSigningIdentity(id, rsaPrivateKeySign(key), hash.SHA256), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %T", privateKey)
+	}
+}
+
+func ed25519PrivateKeySign(privateKey ed25519.PrivateKey) gatewayid.Sign {
+	return func(message []byte) ([]byte, error) {
+		return ed25519.Sign(privateKey, message), nil
+	}
+}
+
+func rsaPrivateKeySign(privateKey *rsa.PrivateKey) gatewayid.Sign {
+	return func(digest []byte) ([]byte, error) {
+		return rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest)
+	}
+}
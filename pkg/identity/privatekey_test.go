@@ -0,0 +1,129 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/stretchr/testify/require"
+)
+
+func NewX509Identity(t *testing.T, signer crypto.Signer) *gatewayid.X509Identity {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(certificateDER)
+	require.NoError(t, err)
+
+	id, err := gatewayid.NewX509Identity("MSP_ID", certificate)
+	require.NoError(t, err)
+
+	return id
+}
+
+func TestNewPrivateKeySigningIdentity(t *testing.T) {
+	message := []byte("MESSAGE")
+	digest := sha256.Sum256(message)
+
+	t.Run("ECDSA signs SHA-256 digest", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		signingID, err := NewPrivateKeySigningIdentity(NewX509Identity(t, privateKey), privateKey)
+		require.NoError(t, err)
+
+		signature, err := signingID.Sign(message)
+		require.NoError(t, err)
+		require.True(t, ecdsa.VerifyASN1(&privateKey.PublicKey, digest[:], signature))
+	})
+
+	t.Run("Ed25519 signs whole message", func(t *testing.T) {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		signingID, err := NewPrivateKeySigningIdentity(NewX509Identity(t, privateKey), privateKey)
+		require.NoError(t, err)
+
+		signature, err := signingID.Sign(message)
+		require.NoError(t, err)
+		require.True(t, ed25519.Verify(publicKey, message, signature))
+	})
+
+	t.Run("RSA signs SHA-256 digest with PKCS #1 v1.5", func(t *testing.T) {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		signingID, err := NewPrivateKeySigningIdentity(NewX509Identity(t, privateKey), privateKey)
+		require.NoError(t, err)
+
+		signature, err := signingID.Sign(message)
+		require.NoError(t, err)
+		require.NoError(t, rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, digest[:], signature))
+	})
+
+	t.Run("Unsupported key type gives error", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		_, err = NewPrivateKeySigningIdentity(NewX509Identity(t, privateKey), "NOT_A_KEY")
+		require.ErrorContains(t, err, "unsupported key type")
+	})
+
+	t.Run("Ed25519 PEM credentials", func(t *testing.T) {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		id := NewX509Identity(t, privateKey)
+		privateKeyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		require.NoError(t, err)
+		privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDER})
+
+		signingID, err := NewSigningIdentityFromPEM("MSP_ID", id.Credentials(), privateKeyPEM)
+		require.NoError(t, err)
+
+		signature, err := signingID.Sign(message)
+		require.NoError(t, err)
+		require.True(t, ed25519.Verify(publicKey, message, signature))
+	})
+}
+
+func TestNewSigningIdentity(t *testing.T) {
+	t.Run("Nil hash passes whole message to sign function", func(t *testing.T) {
+		var actual []byte
+		sign := func(message []byte) ([]byte, error) {
+			actual = message
+			return []byte("SIGNATURE"), nil
+		}
+
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		signingID := NewSigningIdentity(NewX509Identity(t, privateKey), sign, nil)
+		_, err = signingID.Sign([]byte("MESSAGE"))
+		require.NoError(t, err)
+
+		require.Equal(t, []byte("MESSAGE"), actual)
+	})
+}
@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/hash"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// maxErrorBodySize limits the amount of an HTTP error response included in error messages.
+const maxErrorBodySize = 1024
+
+// SignRequest is a request to a remote signing service to sign a message on behalf of an identity.
+type SignRequest struct {
+	MspID       string `json:"mspId"`
+	Credentials []byte `json:"credentials"`
+	// Digest is the message digest to be signed or, where no hash is used, the whole message.
+	Digest []byte `json:"digest"`
+}
+
+type signResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+// SignTransport delivers signing requests to a remote signing service.
+type SignTransport interface {
+	Sign(ctx context.Context, request *SignRequest) ([]byte, error)
+}
+
+// SignTransportFunc adapts a function to a SignTransport.
+type SignTransportFunc func(ctx context.Context, request *SignRequest) ([]byte, error)
+
+// Sign calls the function.
+func (f SignTransportFunc) Sign(ctx context.Context, request *SignRequest) ([]byte, error) {
+	return f(ctx, request)
+}
+
+// RemoteSigningIdentity is a signing identity that delegates signing to a remote signing service.
+type RemoteSigningIdentity struct {
+	gatewayid.Identity
+	transport SignTransport
+	hash      hash.Hash
+	timeout   time.Duration
+}
+
+// NewRemoteSigningIdentity creates a signing identity that sends the digest of messages, generated by the supplied
+// hash function, to a remote signing service using the supplied transport. If hash is nil, the whole message is sent.
+// A non-zero timeout limits the time spent waiting for each signature.
+func NewRemoteSigningIdentity(id gatewayid.Identity, transport SignTransport, hash hash.Hash, timeout time.Duration) *RemoteSigningIdentity {
+	return &RemoteSigningIdentity{
+		Identity:  id,
+		transport: transport,
+		hash:      hash,
+		timeout:   timeout,
+	}
+}
+
+// Sign a message using the remote signing service.
+func (signingID *RemoteSigningIdentity) Sign(message []byte) ([]byte, error) {
+	return signingID.SignContext(context.Background(), message)
+}
+
+// SignContext signs a message using the remote signing service. The context can be used to cancel the request.
+func (signingID *RemoteSigningIdentity) SignContext(ctx context.Context, message []byte) ([]byte, error) {
+	if signingID.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, signingID.timeout)
+		defer cancel()
+	}
+
+	digest := message
+	if signingID.hash != nil {
+		digest = signingID.hash(message)
+	}
+
+	request := &SignRequest{
+		MspID:       signingID.MspID(),
+		Credentials: signingID.Credentials(),
+		Digest:      digest,
+	}
+
+	signature, err := signingID.transport.Sign(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("remote signing failed: %w", err)
+	}
+
+	return signature, nil
+}
+
+// NewLocalSignTransport creates a transport that signs using the supplied sign function within the current process.
+// It is intended as a stand-in for a remote signing service during development and testing.
+func NewLocalSignTransport(sign gatewayid.Sign) SignTransport {
+	return SignTransportFunc(func(ctx context.Context, request *SignRequest) ([]byte, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return sign(request.Digest)
+	})
+}
+
+// NewHTTPSignTransport creates a transport that POSTs signing requests as JSON to the specified URL. The response
+// body must be a JSON object with a base64 encoded signature field. If client is nil, http.DefaultClient is used.
+func NewHTTPSignTransport(url string, client *http.Client) SignTransport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return SignTransportFunc(func(ctx context.Context, request *SignRequest) ([]byte, error) {
+		requestBody, err := json.Marshal(request)
+		if err != nil {
+			return nil, err
+		}
+
+		httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(requestBody))
+		if err != nil {
+			return nil, err
+		}
+		httpRequest.Header.Set("Content-Type", "application/json")
+
+		httpResponse, err := client.Do(httpRequest)
+		if err != nil {
+			return nil, err
+		}
+		defer httpResponse.Body.Close()
+
+		if httpResponse.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(io.LimitReader(httpResponse.Body, maxErrorBodySize))
+			return nil, fmt.Errorf("signing service returned %s: %s", httpResponse.Status, bytes.TrimSpace(body))
+		}
+
+		response := &signResponse{}
+		if err := json.NewDecoder(httpResponse.Body).Decode(response); err != nil {
+			return nil, fmt.Errorf("failed to parse signing service response: %w", err)
+		}
+
+		return response.Signature, nil
+	})
+}
@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package identity
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/hash"
+	gatewayid "github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteSigningIdentity(t *testing.T) {
+	message := []byte("MESSAGE")
+	digest := sha256.Sum256(message)
+
+	newIdentity := func(t *testing.T) (*gatewayid.X509Identity, *ecdsa.PrivateKey) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		return NewX509Identity(t, privateKey), privateKey
+	}
+
+	t.Run("Local transport signs digest", func(t *testing.T) {
+		id, privateKey := newIdentity(t)
+		sign, err := gatewayid.NewPrivateKeySign(privateKey)
+		require.NoError(t, err)
+
+		signingID := NewRemoteSigningIdentity(id, NewLocalSignTransport(sign), hash.SHA256, 0)
+		signature, err := signingID.Sign(message)
+		require.NoError(t, err)
+
+		require.True(t, ecdsa.VerifyASN1(&privateKey.PublicKey, digest[:], signature))
+	})
+
+	t.Run("Nil hash sends whole message", func(t *testing.T) {
+		id, _ := newIdentity(t)
+		var actual *SignRequest
+		transport := SignTransportFunc(func(ctx context.Context, request *SignRequest) ([]byte, error) {
+			actual = request
+			return []byte("SIGNATURE"), nil
+		})
+
+		signingID := NewRemoteSigningIdentity(id, transport, nil, 0)
+		_, err := signingID.Sign(message)
+		require.NoError(t, err)
+
+		require.Equal(t, message, actual.Digest)
+		require.Equal(t, "MSP_ID", actual.MspID)
+		require.Equal(t, id.Credentials(), actual.Credentials)
+	})
+
+	t.Run("Cancelled context gives error", func(t *testing.T) {
+		id, privateKey := newIdentity(t)
+		sign, err := gatewayid.NewPrivateKeySign(privateKey)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		signingID := NewRemoteSigningIdentity(id, NewLocalSignTransport(sign), hash.SHA256, 0)
+		_, err = signingID.SignContext(ctx, message)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Timeout applies to transport", func(t *testing.T) {
+		id, _ := newIdentity(t)
+		transport := SignTransportFunc(func(ctx context.Context, request *SignRequest) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+		signingID := NewRemoteSigningIdentity(id, transport, hash.SHA256, 10*time.Millisecond)
+		_, err := signingID.Sign(message)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("HTTP transport", func(t *testing.T) {
+		id, privateKey := newIdentity(t)
+		sign, err := gatewayid.NewPrivateKeySign(privateKey)
+		require.NoError(t, err)
+
+		var actual SignRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&actual))
+
+			signature, err := sign(actual.Digest)
+			require.NoError(t, err)
+			require.NoError(t, json.NewEncoder(w).Encode(&signResponse{Signature: signature}))
+		}))
+		defer server.Close()
+
+		signingID := NewRemoteSigningIdentity(id, NewHTTPSignTransport(server.URL, nil), hash.SHA256, time.Second)
+		signature, err := signingID.Sign(message)
+		require.NoError(t, err)
+
+		require.Equal(t, digest[:], actual.Digest)
+		require.True(t, ecdsa.VerifyASN1(&privateKey.PublicKey, digest[:], signature))
+	})
+
+	t.Run("HTTP error status gives error", func(t *testing.T) {
+		id, _ := newIdentity(t)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "approval denied", http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		signingID := NewRemoteSigningIdentity(id, NewHTTPSignTransport(server.URL, nil), hash.SHA256, time.Second)
+		_, err := signingID.Sign(message)
+		require.ErrorContains(t, err, "approval denied")
+		require.ErrorContains(t, err, "403")
+	})
+
+	t.Run("Invalid HTTP response gives error", func(t *testing.T) {
+		id, _ := newIdentity(t)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("not json"))
+		}))
+		defer server.Close()
+
+		signingID := NewRemoteSigningIdentity(id, NewHTTPSignTransport(server.URL, nil), hash.SHA256, time.Second)
+		_, err := signingID.Sign(message)
+		require.ErrorContains(t, err, "signing service response")
+	})
+}
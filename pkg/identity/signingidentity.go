@@ -16,6 +16,8 @@ type SigningIdentity interface {
 	Sign([]byte) ([]byte, error)
 }
 
+// NewSigningIdentity creates a signing identity that signs the digest of messages generated by the supplied hash
+// function. If hash is nil, the sign function is passed the whole message, as required for Ed25519 signatures.
 func NewSigningIdentity(id gatewayid.Identity, sign gatewayid.Sign, hash hash.Hash) SigningIdentity {
 	return &signingIdentity{
 		Identity: id,
@@ -31,6 +33,10 @@ type signingIdentity struct {
 }
 
 func (signingID *signingIdentity) Sign(messageBytes []byte) ([]byte, error) {
+	if signingID.hash == nil {
+		return signingID.sign(messageBytes)
+	}
+
 	digest := signingID.hash(messageBytes)
 	return signingID.sign(digest)
 }
@@ -0,0 +1,213 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package msp creates the MSP definitions used within channel configuration from local MSP directories.
+package msp
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"gopkg.in/yaml.v3"
+)
+
+const configFileName = "config.yaml"
+
+// Configuration is the content of the config.yaml file within an MSP directory.
+type Configuration struct {
+	OrganizationalUnitIdentifiers []*OrganizationalUnitIdentifier `yaml:"OrganizationalUnitIdentifiers,omitempty"`
+	NodeOUs                       *NodeOUs                        `yaml:"NodeOUs,omitempty"`
+}
+
+// OrganizationalUnitIdentifier associates an organizational unit with the CA certificate, identified by a file path
+// relative to the MSP directory, that issues identities for that organizational unit.
+type OrganizationalUnitIdentifier struct {
+	Certificate                  string `yaml:"Certificate,omitempty"`
+	OrganizationalUnitIdentifier string `yaml:"OrganizationalUnitIdentifier,omitempty"`
+}
+
+// NodeOUs identifies the organizational units used to classify identities as clients, peers, admins or orderers.
+type NodeOUs struct {
+	Enable              bool                          `yaml:"Enable,omitempty"`
+	ClientOUIdentifier  *OrganizationalUnitIdentifier `yaml:"ClientOUIdentifier,omitempty"`
+	PeerOUIdentifier    *OrganizationalUnitIdentifier `yaml:"PeerOUIdentifier,omitempty"`
+	AdminOUIdentifier   *OrganizationalUnitIdentifier `yaml:"AdminOUIdentifier,omitempty"`
+	OrdererOUIdentifier *OrganizationalUnitIdentifier `yaml:"OrdererOUIdentifier,omitempty"`
+}
+
+// LoadDir creates an MSP definition for the given MSP ID from the content of a local MSP directory. The directory
+// must contain at least one CA certificate in its cacerts subdirectory. The intermediatecerts, admincerts,
+// tlscacerts, tlsintermediatecerts and crls subdirectories, and the config.yaml file, are optional. As with Fabric's
+// own MSP directory loading, each PEM block within these subdirectories is a separate entry in the MSP definition,
+// and files without PEM content are ignored.
+func LoadDir(dir string, mspID string) (*msp.FabricMSPConfig, error) {
+	rootCerts, err := readDirPEM(filepath.Join(dir, "cacerts"))
+	if err != nil {
+		return nil, err
+	}
+	if len(rootCerts) == 0 {
+		return nil, fmt.Errorf("no CA certificates found in MSP directory: %s", dir)
+	}
+
+	intermediateCerts, err := readDirPEM(filepath.Join(dir, "intermediatecerts"))
+	if err != nil {
+		return nil, err
+	}
+
+	admins, err := readDirPEM(filepath.Join(dir, "admincerts"))
+	if err != nil {
+		return nil, err
+	}
+
+	tlsRootCerts, err := readDirPEM(filepath.Join(dir, "tlscacerts"))
+	if err != nil {
+		return nil, err
+	}
+
+	tlsIntermediateCerts, err := readDirPEM(filepath.Join(dir, "tlsintermediatecerts"))
+	if err != nil {
+		return nil, err
+	}
+
+	revocationList, err := readDirPEM(filepath.Join(dir, "crls"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &msp.FabricMSPConfig{
+		Name:                 mspID,
+		RootCerts:            rootCerts,
+		IntermediateCerts:    intermediateCerts,
+		Admins:               admins,
+		RevocationList:       revocationList,
+		TlsRootCerts:         tlsRootCerts,
+		TlsIntermediateCerts: tlsIntermediateCerts,
+		CryptoConfig: &msp.FabricCryptoConfig{
+			SignatureHashFamily:            "SHA2",
+			IdentityIdentifierHashFunction: "SHA256",
+		},
+	}
+
+	if err := applyConfigFile(dir, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func applyConfigFile(dir string, fabricMSPConfig *msp.FabricMSPConfig) error {
+	configBytes, err := os.ReadFile(filepath.Join(dir, configFileName)) //#nosec G304 -- caller supplied MSP directory
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	config := &Configuration{}
+	if err := yaml.Unmarshal(configBytes, config); err != nil {
+		return fmt.Errorf("failed to parse %s in MSP directory %s: %w", configFileName, dir, err)
+	}
+
+	for _, ouIdentifier := range config.OrganizationalUnitIdentifiers {
+		fabricOUIdentifier, err := ouIdentifier.fabricOUIdentifier(dir)
+		if err != nil {
+			return err
+		}
+		fabricMSPConfig.OrganizationalUnitIdentifiers = append(fabricMSPConfig.OrganizationalUnitIdentifiers, fabricOUIdentifier)
+	}
+
+	if config.NodeOUs == nil {
+		return nil
+	}
+
+	nodeOUs := &msp.FabricNodeOUs{
+		Enable: config.NodeOUs.Enable,
+	}
+
+	ouIdentifiers := []struct {
+		source *OrganizationalUnitIdentifier
+		target **msp.FabricOUIdentifier
+	}{
+		{config.NodeOUs.ClientOUIdentifier, &nodeOUs.ClientOuIdentifier},
+		{config.NodeOUs.PeerOUIdentifier, &nodeOUs.PeerOuIdentifier},
+		{config.NodeOUs.AdminOUIdentifier, &nodeOUs.AdminOuIdentifier},
+		{config.NodeOUs.OrdererOUIdentifier, &nodeOUs.OrdererOuIdentifier},
+	}
+	for _, ouIdentifier := range ouIdentifiers {
+		if ouIdentifier.source == nil {
+			continue
+		}
+
+		if *ouIdentifier.target, err = ouIdentifier.source.fabricOUIdentifier(dir); err != nil {
+			return err
+		}
+	}
+
+	fabricMSPConfig.FabricNodeOus = nodeOUs
+	return nil
+}
+
+func (ouIdentifier *OrganizationalUnitIdentifier) fabricOUIdentifier(dir string) (*msp.FabricOUIdentifier, error) {
+	result := &msp.FabricOUIdentifier{
+		OrganizationalUnitIdentifier: ouIdentifier.OrganizationalUnitIdentifier,
+	}
+
+	if ouIdentifier.Certificate == "" {
+		return result, nil
+	}
+
+	certificate, err := os.ReadFile(filepath.Join(dir, ouIdentifier.Certificate)) //#nosec G304 -- caller supplied MSP directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate for organizational unit %s: %w", ouIdentifier.OrganizationalUnitIdentifier, err)
+	}
+
+	result.Certificate = certificate
+	return result, nil
+}
+
+// readDirPEM returns each PEM block from the files in a directory, in file name order. Files without PEM content are
+// skipped. A missing directory gives no results.
+func readDirPEM(dir string) ([][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var results [][]byte
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name())) //#nosec G304 -- caller supplied MSP directory
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, pemBlocks(content)...)
+	}
+
+	return results, nil
+}
+
+// pemBlocks returns each PEM block in the content, PEM encoded.
+func pemBlocks(content []byte) [][]byte {
+	var results [][]byte
+	for {
+		var block *pem.Block
+		if block, content = pem.Decode(content); block == nil {
+			return results
+		}
+		results = append(results, pem.EncodeToMemory(block))
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// AssertWriteFile ensures that a file is written, creating parent directories as required
+func AssertWriteFile(t *testing.T, filename string, content []byte) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o750))
+	require.NoError(t, os.WriteFile(filename, content, 0o600))
+}
+
+// NewPEM returns a PEM block containing the supplied content.
+func NewPEM(blockType string, content string) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: []byte(content)})
+}
+
+func TestLoadDir(t *testing.T) {
+	t.Run("Reads certificates", func(t *testing.T) {
+		dir := t.TempDir()
+		AssertWriteFile(t, filepath.Join(dir, "cacerts", "b.pem"), NewPEM("CERTIFICATE", "CA_CERT_B"))
+		AssertWriteFile(t, filepath.Join(dir, "cacerts", "a.pem"), NewPEM("CERTIFICATE", "CA_CERT_A"))
+		AssertWriteFile(t, filepath.Join(dir, "intermediatecerts", "ica.pem"), NewPEM("CERTIFICATE", "INTERMEDIATE_CERT"))
+		AssertWriteFile(t, filepath.Join(dir, "admincerts", "admin.pem"), NewPEM("CERTIFICATE", "ADMIN_CERT"))
+		AssertWriteFile(t, filepath.Join(dir, "tlscacerts", "tlsca.pem"), NewPEM("CERTIFICATE", "TLS_CA_CERT"))
+		AssertWriteFile(t, filepath.Join(dir, "tlsintermediatecerts", "tlsica.pem"), NewPEM("CERTIFICATE", "TLS_INTERMEDIATE_CERT"))
+		AssertWriteFile(t, filepath.Join(dir, "crls", "crl.pem"), NewPEM("X509 CRL", "CRL"))
+
+		actual, err := LoadDir(dir, "MSP_ID")
+		require.NoError(t, err)
+
+		require.Equal(t, "MSP_ID", actual.GetName())
+		require.Equal(t, [][]byte{NewPEM("CERTIFICATE", "CA_CERT_A"), NewPEM("CERTIFICATE", "CA_CERT_B")}, actual.GetRootCerts())
+		require.Equal(t, [][]byte{NewPEM("CERTIFICATE", "INTERMEDIATE_CERT")}, actual.GetIntermediateCerts())
+		require.Equal(t, [][]byte{NewPEM("CERTIFICATE", "ADMIN_CERT")}, actual.GetAdmins())
+		require.Equal(t, [][]byte{NewPEM("CERTIFICATE", "TLS_CA_CERT")}, actual.GetTlsRootCerts())
+		require.Equal(t, [][]byte{NewPEM("CERTIFICATE", "TLS_INTERMEDIATE_CERT")}, actual.GetTlsIntermediateCerts())
+		require.Equal(t, [][]byte{NewPEM("X509 CRL", "CRL")}, actual.GetRevocationList())
+		require.Equal(t, "SHA2", actual.GetCryptoConfig().GetSignatureHashFamily())
+		require.Nil(t, actual.GetFabricNodeOus())
+	})
+
+	t.Run("Reads each certificate in a bundle file", func(t *testing.T) {
+		dir := t.TempDir()
+		bundle := bytes.Join([][]byte{NewPEM("CERTIFICATE", "CA_CERT_1"), NewPEM("CERTIFICATE", "CA_CERT_2")}, []byte("\n"))
+		AssertWriteFile(t, filepath.Join(dir, "cacerts", "bundle.pem"), bundle)
+
+		actual, err := LoadDir(dir, "MSP_ID")
+		require.NoError(t, err)
+
+		require.Equal(t, [][]byte{NewPEM("CERTIFICATE", "CA_CERT_1"), NewPEM("CERTIFICATE", "CA_CERT_2")}, actual.GetRootCerts())
+	})
+
+	t.Run("Ignores files without PEM content", func(t *testing.T) {
+		dir := t.TempDir()
+		AssertWriteFile(t, filepath.Join(dir, "cacerts", "ca.pem"), NewPEM("CERTIFICATE", "CA_CERT"))
+		AssertWriteFile(t, filepath.Join(dir, "cacerts", "README"), []byte("Not a certificate"))
+
+		actual, err := LoadDir(dir, "MSP_ID")
+		require.NoError(t, err)
+
+		require.Equal(t, [][]byte{NewPEM("CERTIFICATE", "CA_CERT")}, actual.GetRootCerts())
+	})
+
+	t.Run("CA certificates directory without PEM content returns error", func(t *testing.T) {
+		dir := t.TempDir()
+		AssertWriteFile(t, filepath.Join(dir, "cacerts", "README"), []byte("Not a certificate"))
+
+		_, err := LoadDir(dir, "MSP_ID")
+		require.ErrorContains(t, err, "no CA certificates")
+	})
+
+	t.Run("Reads NodeOUs from config file", func(t *testing.T) {
+		dir := t.TempDir()
+		AssertWriteFile(t, filepath.Join(dir, "cacerts", "ca.pem"), NewPEM("CERTIFICATE", "CA_CERT"))
+		AssertWriteFile(t, filepath.Join(dir, "config.yaml"), []byte(`
+NodeOUs:
+  Enable: true
+  ClientOUIdentifier:
+    Certificate: cacerts/ca.pem
+    OrganizationalUnitIdentifier: client
+  PeerOUIdentifier:
+    Certificate: cacerts/ca.pem
+    OrganizationalUnitIdentifier: peer
+  AdminOUIdentifier:
+    OrganizationalUnitIdentifier: admin
+  OrdererOUIdentifier:
+    OrganizationalUnitIdentifier: orderer
+OrganizationalUnitIdentifiers:
+  - Certificate: cacerts/ca.pem
+    OrganizationalUnitIdentifier: department1
+`))
+
+		actual, err := LoadDir(dir, "MSP_ID")
+		require.NoError(t, err)
+
+		nodeOUs := actual.GetFabricNodeOus()
+		require.True(t, nodeOUs.GetEnable())
+		require.Equal(t, "client", nodeOUs.GetClientOuIdentifier().GetOrganizationalUnitIdentifier())
+		require.Equal(t, NewPEM("CERTIFICATE", "CA_CERT"), nodeOUs.GetClientOuIdentifier().GetCertificate())
+		require.Equal(t, "peer", nodeOUs.GetPeerOuIdentifier().GetOrganizationalUnitIdentifier())
+		require.Equal(t, "admin", nodeOUs.GetAdminOuIdentifier().GetOrganizationalUnitIdentifier())
+		require.Empty(t, nodeOUs.GetAdminOuIdentifier().GetCertificate())
+		require.Equal(t, "orderer", nodeOUs.GetOrdererOuIdentifier().GetOrganizationalUnitIdentifier())
+
+		require.Len(t, actual.GetOrganizationalUnitIdentifiers(), 1)
+		require.Equal(t, "department1", actual.GetOrganizationalUnitIdentifiers()[0].GetOrganizationalUnitIdentifier())
+		require.Equal(t, NewPEM("CERTIFICATE", "CA_CERT"), actual.GetOrganizationalUnitIdentifiers()[0].GetCertificate())
+	})
+
+	t.Run("Missing CA certificates returns error", func(t *testing.T) {
+		dir := t.TempDir()
+
+		_, err := LoadDir(dir, "MSP_ID")
+		require.ErrorContains(t, err, dir)
+	})
+
+	t.Run("Missing OU certificate returns error", func(t *testing.T) {
+		dir := t.TempDir()
+		AssertWriteFile(t, filepath.Join(dir, "cacerts", "ca.pem"), NewPEM("CERTIFICATE", "CA_CERT"))
+		AssertWriteFile(t, filepath.Join(dir, "config.yaml"), []byte(`
+NodeOUs:
+  Enable: true
+  ClientOUIdentifier:
+    Certificate: cacerts/missing.pem
+    OrganizationalUnitIdentifier: client
+`))
+
+		_, err := LoadDir(dir, "MSP_ID")
+		require.ErrorContains(t, err, "client")
+	})
+
+	t.Run("Invalid config file returns error", func(t *testing.T) {
+		dir := t.TempDir()
+		AssertWriteFile(t, filepath.Join(dir, "cacerts", "ca.pem"), NewPEM("CERTIFICATE", "CA_CERT"))
+		AssertWriteFile(t, filepath.Join(dir, "config.yaml"), []byte("NodeOUs: [invalid"))
+
+		_, err := LoadDir(dir, "MSP_ID")
+		require.ErrorContains(t, err, "config.yaml")
+	})
+}
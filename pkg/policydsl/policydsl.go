@@ -0,0 +1,275 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package policydsl parses signature policies expressed in the policy language used by Fabric configuration and
+// tooling, such as:
+//
+//	OR('Org1MSP.peer', AND('Org2MSP.admin', 'Org3MSP.member'), OutOf(2, 'Org4MSP.peer', 'Org5MSP.peer', 'Org6MSP.peer'))
+//
+// Principals take the form 'MSPID.role', where role is one of member, admin, client, peer or orderer.
+package policydsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"google.golang.org/protobuf/proto"
+)
+
+var roles = map[string]msp.MSPRole_MSPRoleType{
+	"member":  msp.MSPRole_MEMBER,
+	"admin":   msp.MSPRole_ADMIN,
+	"client":  msp.MSPRole_CLIENT,
+	"peer":    msp.MSPRole_PEER,
+	"orderer": msp.MSPRole_ORDERER,
+}
+
+// FromString parses a signature policy expression.
+func FromString(policy string) (*common.SignaturePolicyEnvelope, error) {
+	p := &parser{
+		input:      policy,
+		principals: make(map[string]int32),
+	}
+
+	rule, err := p.parseExpression()
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %q: %w", policy, err)
+	}
+
+	p.skipSpace()
+	if p.position < len(p.input) {
+		return nil, fmt.Errorf("invalid policy %q: unexpected input at position %d", policy, p.position)
+	}
+
+	envelope := &common.SignaturePolicyEnvelope{
+		Version:    0,
+		Rule:       rule,
+		Identities: p.identities,
+	}
+	return envelope, nil
+}
+
+// PolicyFromString parses a signature policy expression and returns it as a signature type policy.
+func PolicyFromString(policy string) (*common.Policy, error) {
+	envelope, err := FromString(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	envelopeBytes, err := proto.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &common.Policy{
+		Type:  int32(common.Policy_SIGNATURE),
+		Value: envelopeBytes,
+	}
+	return result, nil
+}
+
+type parser struct {
+	input      string
+	position   int
+	identities []*msp.MSPPrincipal
+	principals map[string]int32
+}
+
+func (p *parser) parseExpression() (*common.SignaturePolicy, error) {
+	p.skipSpace()
+	if p.position >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of input")
+	}
+
+	if quote := p.input[p.position]; quote == '\'' || quote == '"' {
+		return p.parsePrincipal(quote)
+	}
+
+	name := p.readWord()
+	switch strings.ToLower(name) {
+	case "and":
+		return p.parseGate(true)
+	case "or":
+		return p.parseGate(false)
+	case "outof":
+		return p.parseOutOf()
+	case "":
+		return nil, fmt.Errorf("unexpected character %q at position %d", p.input[p.position], p.position)
+	default:
+		return nil, fmt.Errorf("unknown function %s at position %d", name, p.position-len(name))
+	}
+}
+
+func (p *parser) parseGate(requireAll bool) (*common.SignaturePolicy, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+
+	rules, err := p.parseArguments()
+	if err != nil {
+		return nil, err
+	}
+
+	n := int32(1)
+	if requireAll {
+		n = int32(len(rules))
+	}
+
+	return nOutOf(n, rules), nil
+}
+
+func (p *parser) parseOutOf() (*common.SignaturePolicy, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	start := p.position
+	for p.position < len(p.input) && unicode.IsDigit(rune(p.input[p.position])) {
+		p.position++
+	}
+	n, err := strconv.ParseInt(p.input[start:p.position], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OutOf count at position %d", start)
+	}
+
+	if err := p.expect(','); err != nil {
+		return nil, err
+	}
+
+	rules, err := p.parseArguments()
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 1 || int(n) > len(rules) {
+		return nil, fmt.Errorf("OutOf count %d must be between 1 and the number of arguments (%d)", n, len(rules))
+	}
+
+	return nOutOf(int32(n), rules), nil
+}
+
+func (p *parser) parseArguments() ([]*common.SignaturePolicy, error) {
+	var rules []*common.SignaturePolicy
+
+	for {
+		rule, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+
+		p.skipSpace()
+		if p.position >= len(p.input) {
+			return nil, fmt.Errorf("unexpected end of input")
+		}
+
+		switch p.input[p.position] {
+		case ',':
+			p.position++
+		case ')':
+			p.position++
+			return rules, nil
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", p.input[p.position], p.position)
+		}
+	}
+}
+
+func (p *parser) parsePrincipal(quote byte) (*common.SignaturePolicy, error) {
+	start := p.position
+	end := strings.IndexByte(p.input[start+1:], quote)
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated principal at position %d", start)
+	}
+
+	principal := p.input[start+1 : start+1+end]
+	p.position = start + end + 2
+
+	index, err := p.principalIndex(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &common.SignaturePolicy{
+		Type: &common.SignaturePolicy_SignedBy{
+			SignedBy: index,
+		},
+	}
+	return rule, nil
+}
+
+func (p *parser) principalIndex(principal string) (int32, error) {
+	if index, exists := p.principals[principal]; exists {
+		return index, nil
+	}
+
+	separator := strings.LastIndexByte(principal, '.')
+	if separator <= 0 {
+		return 0, fmt.Errorf("invalid principal %q: expected 'MSPID.role'", principal)
+	}
+
+	mspID := principal[:separator]
+	role, ok := roles[strings.ToLower(principal[separator+1:])]
+	if !ok {
+		return 0, fmt.Errorf("invalid principal %q: unknown role %s", principal, principal[separator+1:])
+	}
+
+	principalBytes, err := proto.Marshal(&msp.MSPRole{
+		MspIdentifier: mspID,
+		Role:          role,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	index := int32(len(p.identities))
+	p.identities = append(p.identities, &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ROLE,
+		Principal:               principalBytes,
+	})
+	p.principals[principal] = index
+
+	return index, nil
+}
+
+func (p *parser) readWord() string {
+	start := p.position
+	for p.position < len(p.input) && unicode.IsLetter(rune(p.input[p.position])) {
+		p.position++
+	}
+	return p.input[start:p.position]
+}
+
+func (p *parser) expect(c byte) error {
+	p.skipSpace()
+	if p.position >= len(p.input) || p.input[p.position] != c {
+		return fmt.Errorf("expected %q at position %d", c, p.position)
+	}
+
+	p.position++
+	return nil
+}
+
+func (p *parser) skipSpace() {
+	for p.position < len(p.input) && unicode.IsSpace(rune(p.input[p.position])) {
+		p.position++
+	}
+}
+
+func nOutOf(n int32, rules []*common.SignaturePolicy) *common.SignaturePolicy {
+	return &common.SignaturePolicy{
+		Type: &common.SignaturePolicy_NOutOf_{
+			NOutOf: &common.SignaturePolicy_NOutOf{
+				N:     n,
+				Rules: rules,
+			},
+		},
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policydsl
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func AssertMarshal(t *testing.T, m protoreflect.ProtoMessage) []byte {
+	result, err := proto.Marshal(m)
+	require.NoError(t, err)
+	return result
+}
+
+// AssertProtoEqual ensures an expected protobuf message matches an actual message
+func AssertProtoEqual(t *testing.T, expected protoreflect.ProtoMessage, actual protoreflect.ProtoMessage) {
+	require.True(t, proto.Equal(expected, actual), "Expected %v, got %v", expected, actual)
+}
+
+func NewRolePrincipal(t *testing.T, mspID string, role msp.MSPRole_MSPRoleType) *msp.MSPPrincipal {
+	return &msp.MSPPrincipal{
+		PrincipalClassification: msp.MSPPrincipal_ROLE,
+		Principal: AssertMarshal(t, &msp.MSPRole{
+			MspIdentifier: mspID,
+			Role:          role,
+		}),
+	}
+}
+
+func SignedBy(index int32) *common.SignaturePolicy {
+	return &common.SignaturePolicy{
+		Type: &common.SignaturePolicy_SignedBy{
+			SignedBy: index,
+		},
+	}
+}
+
+func TestFromString(t *testing.T) {
+	t.Run("Single principal", func(t *testing.T) {
+		actual, err := FromString("'Org1MSP.peer'")
+		require.NoError(t, err)
+
+		expected := &common.SignaturePolicyEnvelope{
+			Rule:       SignedBy(0),
+			Identities: []*msp.MSPPrincipal{NewRolePrincipal(t, "Org1MSP", msp.MSPRole_PEER)},
+		}
+		AssertProtoEqual(t, expected, actual)
+	})
+
+	t.Run("Nested gates", func(t *testing.T) {
+		actual, err := FromString(`OR('Org1MSP.member', AND("Org2MSP.admin", 'Org3MSP.client'), OutOf(1, 'Org1MSP.member', 'Org4MSP.orderer'))`)
+		require.NoError(t, err)
+
+		expected := &common.SignaturePolicyEnvelope{
+			Rule: nOutOf(1, []*common.SignaturePolicy{
+				SignedBy(0),
+				nOutOf(2, []*common.SignaturePolicy{SignedBy(1), SignedBy(2)}),
+				nOutOf(1, []*common.SignaturePolicy{SignedBy(0), SignedBy(3)}),
+			}),
+			Identities: []*msp.MSPPrincipal{
+				NewRolePrincipal(t, "Org1MSP", msp.MSPRole_MEMBER),
+				NewRolePrincipal(t, "Org2MSP", msp.MSPRole_ADMIN),
+				NewRolePrincipal(t, "Org3MSP", msp.MSPRole_CLIENT),
+				NewRolePrincipal(t, "Org4MSP", msp.MSPRole_ORDERER),
+			},
+		}
+		AssertProtoEqual(t, expected, actual)
+	})
+
+	t.Run("Function names are case insensitive", func(t *testing.T) {
+		_, err := FromString("and('Org1MSP.peer', or('Org2MSP.peer'), outof(1, 'Org3MSP.peer'))")
+		require.NoError(t, err)
+	})
+
+	t.Run("MSP ID may contain dots", func(t *testing.T) {
+		actual, err := FromString("'org1.example.com.admin'")
+		require.NoError(t, err)
+
+		expected := NewRolePrincipal(t, "org1.example.com", msp.MSPRole_ADMIN)
+		AssertProtoEqual(t, expected, actual.GetIdentities()[0])
+	})
+
+	invalidPolicies := map[string]string{
+		"Unknown role":          "'Org1MSP.superuser'",
+		"Missing role":          "'Org1MSP'",
+		"Unknown function":      "XOR('Org1MSP.peer')",
+		"Unterminated quote":    "'Org1MSP.peer",
+		"Missing close bracket": "OR('Org1MSP.peer'",
+		"Trailing input":        "'Org1MSP.peer' 'Org2MSP.peer'",
+		"OutOf count too large": "OutOf(3, 'Org1MSP.peer', 'Org2MSP.peer')",
+		"OutOf count missing":   "OutOf('Org1MSP.peer')",
+		"Empty":                 "",
+	}
+	for name, policy := range invalidPolicies {
+		t.Run("Invalid policy gives error: "+name, func(t *testing.T) {
+			_, err := FromString(policy)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestPolicyFromString(t *testing.T) {
+	actual, err := PolicyFromString("'Org1MSP.peer'")
+	require.NoError(t, err)
+
+	require.EqualValues(t, common.Policy_SIGNATURE, actual.GetType())
+
+	envelope := &common.SignaturePolicyEnvelope{}
+	require.NoError(t, proto.Unmarshal(actual.GetValue(), envelope))
+	require.Len(t, envelope.GetIdentities(), 1)
+}
@@ -0,0 +1,231 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proposal
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// Difference is an element of proposal responses that is not the same for all endpoints.
+type Difference struct {
+	// Path identifies the differing element, such as response.payload or results.ns["mycc"].writes["key"].
+	Path string
+	// Values holds the serialized value of the element for each endpoint. Endpoints whose response does not contain
+	// the element have no entry.
+	Values map[string][]byte
+	// Endpoints are all the compared endpoints, in sorted order.
+	Endpoints []string
+}
+
+// String describes the difference, grouping endpoints that share the same value.
+func (d *Difference) String() string {
+	var groups []string
+	for _, endpoints := range d.EndpointGroups() {
+		groups = append(groups, "["+strings.Join(endpoints, ", ")+"]")
+	}
+
+	return fmt.Sprintf("%s differs between endpoints %s", d.Path, strings.Join(groups, " and "))
+}
+
+// EndpointGroups returns the endpoints grouped by value, with endpoints that lack the element in their own group.
+// Groups and the endpoints within them are in sorted order.
+func (d *Difference) EndpointGroups() [][]string {
+	var groups [][]string
+	var absent []string
+	values := make(map[string]int)
+
+	for _, endpoint := range d.Endpoints {
+		value, ok := d.Values[endpoint]
+		if !ok {
+			absent = append(absent, endpoint)
+			continue
+		}
+
+		index, exists := values[string(value)]
+		if !exists {
+			index = len(groups)
+			values[string(value)] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], endpoint)
+	}
+
+	if len(absent) > 0 {
+		groups = append(groups, absent)
+	}
+
+	return groups
+}
+
+// CompareResponses compares proposal responses for the same proposal from several endpoints, keyed by endpoint. The
+// response status, message and payload, proposal hash, chaincode ID, chaincode event, and the read/write set of the
+// chaincode action are compared. Differences are returned in path order; no differences indicates that all responses
+// are consistent.
+func CompareResponses(responses map[string]*peer.ProposalResponse) ([]*Difference, error) {
+	elements := make(map[string]map[string][]byte)
+	endpoints := sortedKeys(responses)
+
+	for _, endpoint := range endpoints {
+		responseElements, err := flattenResponse(responses[endpoint])
+		if err != nil {
+			return nil, fmt.Errorf("invalid proposal response from %s: %w", endpoint, err)
+		}
+
+		for path, value := range responseElements {
+			if elements[path] == nil {
+				elements[path] = make(map[string][]byte)
+			}
+			elements[path][endpoint] = value
+		}
+	}
+
+	var results []*Difference
+	for _, path := range sortedKeys(elements) {
+		values := elements[path]
+		if isConsistent(values, len(endpoints)) {
+			continue
+		}
+
+		difference := &Difference{
+			Path:      path,
+			Values:    make(map[string][]byte, len(endpoints)),
+			Endpoints: endpoints,
+		}
+		for _, endpoint := range endpoints {
+			if value, ok := values[endpoint]; ok {
+				difference.Values[endpoint] = value
+			}
+		}
+		results = append(results, difference)
+	}
+
+	return results, nil
+}
+
+func isConsistent(values map[string][]byte, endpointCount int) bool {
+	if len(values) != endpointCount {
+		return false
+	}
+
+	var first []byte
+	checked := false
+	for _, value := range values {
+		if checked && !bytes.Equal(first, value) {
+			return false
+		}
+		first = value
+		checked = true
+	}
+
+	return true
+}
+
+func flattenResponse(response *peer.ProposalResponse) (map[string][]byte, error) {
+	elements := map[string][]byte{
+		"response.status":  []byte(fmt.Sprint(response.GetResponse().GetStatus())),
+		"response.message": []byte(response.GetResponse().GetMessage()),
+		"response.payload": response.GetResponse().GetPayload(),
+	}
+
+	responsePayload := &peer.ProposalResponsePayload{}
+	if err := proto.Unmarshal(response.GetPayload(), responsePayload); err != nil {
+		return nil, fmt.Errorf("failed to deserialize proposal response payload: %w", err)
+	}
+	elements["proposalHash"] = responsePayload.GetProposalHash()
+
+	chaincodeAction := &peer.ChaincodeAction{}
+	if err := proto.Unmarshal(responsePayload.GetExtension(), chaincodeAction); err != nil {
+		return nil, fmt.Errorf("failed to deserialize chaincode action: %w", err)
+	}
+
+	elements["chaincodeId"] = []byte(chaincodeAction.GetChaincodeId().GetName() + ":" + chaincodeAction.GetChaincodeId().GetVersion())
+	elements["events"] = chaincodeAction.GetEvents()
+
+	if err := flattenResults(chaincodeAction.GetResults(), elements); err != nil {
+		return nil, err
+	}
+
+	return elements, nil
+}
+
+func flattenResults(resultsBytes []byte, elements map[string][]byte) error {
+	results := &rwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(resultsBytes, results); err != nil {
+		return fmt.Errorf("failed to deserialize read/write set: %w", err)
+	}
+
+	for _, nsReadWriteSet := range results.GetNsRwset() {
+		prefix := fmt.Sprintf("results.ns[%q]", nsReadWriteSet.GetNamespace())
+
+		kvReadWriteSet := &kvrwset.KVRWSet{}
+		if err := proto.Unmarshal(nsReadWriteSet.GetRwset(), kvReadWriteSet); err != nil {
+			return fmt.Errorf("failed to deserialize read/write set for namespace %s: %w", nsReadWriteSet.GetNamespace(), err)
+		}
+
+		for _, read := range kvReadWriteSet.GetReads() {
+			elements[fmt.Sprintf("%s.reads[%q]", prefix, read.GetKey())] = versionBytes(read.GetVersion())
+		}
+
+		for _, write := range kvReadWriteSet.GetWrites() {
+			if write.GetIsDelete() {
+				elements[fmt.Sprintf("%s.deletes[%q]", prefix, write.GetKey())] = []byte{}
+			} else {
+				elements[fmt.Sprintf("%s.writes[%q]", prefix, write.GetKey())] = write.GetValue()
+			}
+		}
+
+		for _, rangeQuery := range kvReadWriteSet.GetRangeQueriesInfo() {
+			path := fmt.Sprintf("%s.rangeQueries[%q:%q]", prefix, rangeQuery.GetStartKey(), rangeQuery.GetEndKey())
+			value, err := proto.MarshalOptions{Deterministic: true}.Marshal(rangeQuery)
+			if err != nil {
+				return err
+			}
+			elements[path] = value
+		}
+
+		for _, metadataWrite := range kvReadWriteSet.GetMetadataWrites() {
+			path := fmt.Sprintf("%s.metadataWrites[%q]", prefix, metadataWrite.GetKey())
+			value, err := proto.MarshalOptions{Deterministic: true}.Marshal(metadataWrite)
+			if err != nil {
+				return err
+			}
+			elements[path] = value
+		}
+
+		for _, collection := range nsReadWriteSet.GetCollectionHashedRwset() {
+			collectionPrefix := fmt.Sprintf("%s.collections[%q]", prefix, collection.GetCollectionName())
+			elements[collectionPrefix+".hashedRwset"] = collection.GetHashedRwset()
+			elements[collectionPrefix+".pvtRwsetHash"] = collection.GetPvtRwsetHash()
+		}
+	}
+
+	return nil
+}
+
+func versionBytes(version *kvrwset.Version) []byte {
+	if version == nil {
+		return []byte("none")
+	}
+
+	return []byte(fmt.Sprintf("%d:%d", version.GetBlockNum(), version.GetTxNum()))
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
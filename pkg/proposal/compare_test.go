@@ -0,0 +1,138 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proposal
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func AssertMarshal(t *testing.T, m protoreflect.ProtoMessage) []byte {
+	result, err := proto.Marshal(m)
+	require.NoError(t, err)
+	return result
+}
+
+func NewResponse(t *testing.T, payload string, kvReadWriteSet *kvrwset.KVRWSet) *peer.ProposalResponse {
+	results := &rwset.TxReadWriteSet{
+		DataModel: rwset.TxReadWriteSet_KV,
+		NsRwset: []*rwset.NsReadWriteSet{
+			{
+				Namespace: "basic",
+				Rwset:     AssertMarshal(t, kvReadWriteSet),
+			},
+		},
+	}
+
+	chaincodeAction := &peer.ChaincodeAction{
+		Results:     AssertMarshal(t, results),
+		ChaincodeId: &peer.ChaincodeID{Name: "basic", Version: "1.0"},
+	}
+
+	responsePayload := &peer.ProposalResponsePayload{
+		ProposalHash: []byte("PROPOSAL_HASH"),
+		Extension:    AssertMarshal(t, chaincodeAction),
+	}
+
+	return &peer.ProposalResponse{
+		Response: &peer.Response{
+			Status:  int32(common.Status_SUCCESS),
+			Payload: []byte(payload),
+		},
+		Payload: AssertMarshal(t, responsePayload),
+	}
+}
+
+func NewReadWriteSet(version uint64, writes map[string]string) *kvrwset.KVRWSet {
+	result := &kvrwset.KVRWSet{
+		Reads: []*kvrwset.KVRead{
+			{Key: "asset1", Version: &kvrwset.Version{BlockNum: version}},
+		},
+	}
+
+	for key, value := range writes {
+		result.Writes = append(result.Writes, &kvrwset.KVWrite{Key: key, Value: []byte(value)})
+	}
+
+	return result
+}
+
+func TestCompareResponses(t *testing.T) {
+	t.Run("Consistent responses give no differences", func(t *testing.T) {
+		actual, err := CompareResponses(map[string]*peer.ProposalResponse{
+			"peer0.org1:7051": NewResponse(t, "RESULT", NewReadWriteSet(1, map[string]string{"asset1": "A"})),
+			"peer0.org2:9051": NewResponse(t, "RESULT", NewReadWriteSet(1, map[string]string{"asset1": "A"})),
+		})
+		require.NoError(t, err)
+		require.Empty(t, actual)
+	})
+
+	t.Run("Reports differing response payload by endpoint", func(t *testing.T) {
+		actual, err := CompareResponses(map[string]*peer.ProposalResponse{
+			"peer0.org1:7051": NewResponse(t, "RESULT", NewReadWriteSet(1, nil)),
+			"peer1.org1:8051": NewResponse(t, "RESULT", NewReadWriteSet(1, nil)),
+			"peer0.org2:9051": NewResponse(t, "OTHER", NewReadWriteSet(1, nil)),
+		})
+		require.NoError(t, err)
+
+		require.Len(t, actual, 1)
+		require.Equal(t, "response.payload", actual[0].Path)
+		require.Equal(t, []byte("OTHER"), actual[0].Values["peer0.org2:9051"])
+		require.Equal(t, [][]string{{"peer0.org1:7051", "peer1.org1:8051"}, {"peer0.org2:9051"}}, actual[0].EndpointGroups())
+	})
+
+	t.Run("Reports differing read versions", func(t *testing.T) {
+		actual, err := CompareResponses(map[string]*peer.ProposalResponse{
+			"peer0.org1:7051": NewResponse(t, "RESULT", NewReadWriteSet(1, nil)),
+			"peer0.org2:9051": NewResponse(t, "RESULT", NewReadWriteSet(2, nil)),
+		})
+		require.NoError(t, err)
+
+		require.Len(t, actual, 1)
+		require.Equal(t, `results.ns["basic"].reads["asset1"]`, actual[0].Path)
+		require.Equal(t, []byte("1:0"), actual[0].Values["peer0.org1:7051"])
+		require.Equal(t, []byte("2:0"), actual[0].Values["peer0.org2:9051"])
+	})
+
+	t.Run("Reports writes missing from some endpoints", func(t *testing.T) {
+		actual, err := CompareResponses(map[string]*peer.ProposalResponse{
+			"peer0.org1:7051": NewResponse(t, "RESULT", NewReadWriteSet(1, map[string]string{"asset1": "A", "asset2": "B"})),
+			"peer0.org2:9051": NewResponse(t, "RESULT", NewReadWriteSet(1, map[string]string{"asset1": "A"})),
+		})
+		require.NoError(t, err)
+
+		require.Len(t, actual, 1)
+		require.Equal(t, `results.ns["basic"].writes["asset2"]`, actual[0].Path)
+		require.NotContains(t, actual[0].Values, "peer0.org2:9051")
+		require.Equal(t, [][]string{{"peer0.org1:7051"}, {"peer0.org2:9051"}}, actual[0].EndpointGroups())
+		require.Contains(t, actual[0].String(), "[peer0.org1:7051] and [peer0.org2:9051]")
+	})
+
+	t.Run("Empty and non-empty values differ", func(t *testing.T) {
+		actual, err := CompareResponses(map[string]*peer.ProposalResponse{
+			"peer0.org1:7051": NewResponse(t, "", NewReadWriteSet(1, nil)),
+			"peer0.org2:9051": NewResponse(t, "RESULT", NewReadWriteSet(1, nil)),
+		})
+		require.NoError(t, err)
+
+		require.Len(t, actual, 1)
+		require.Equal(t, "response.payload", actual[0].Path)
+	})
+
+	t.Run("Invalid response payload gives error naming endpoint", func(t *testing.T) {
+		_, err := CompareResponses(map[string]*peer.ProposalResponse{
+			"peer0.org1:7051": {Payload: []byte("INVALID")},
+		})
+		require.ErrorContains(t, err, "peer0.org1:7051")
+	})
+}
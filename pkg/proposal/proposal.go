@@ -0,0 +1,30 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package proposal provides support for working with proposals and proposal responses outside of the admin commands
+// that create and submit them.
+//
+// Proposals can be signed separately from the admin commands that create and submit them, such as on an offline
+// machine holding the signing key. An unsigned proposal is created using a command's NewProposal function, the bytes
+// returned by Bytes are signed, and the proposal and signature are combined using NewSignedProposal for submission
+// using the command's Submit function.
+package proposal
+
+import (
+	"github.com/bestbeforetoday/fabric-admin/internal/proposal"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+)
+
+// Bytes returns the serialized proposal that must be signed. Serialization is deterministic, so the same proposal
+// always gives the same bytes.
+func Bytes(p *peer.Proposal) ([]byte, error) {
+	return proposal.Bytes(p)
+}
+
+// NewSignedProposal combines a proposal with a signature of its serialized bytes, obtained using Bytes, to create a
+// signed proposal that can be submitted.
+func NewSignedProposal(p *peer.Proposal, signature []byte) (*peer.SignedProposal, error) {
+	return proposal.NewSigned(p, signature)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proposal
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBytes(t *testing.T) {
+	t.Run("Serialization is repeatable", func(t *testing.T) {
+		proposal := &peer.Proposal{
+			Header:  []byte("HEADER"),
+			Payload: []byte("PAYLOAD"),
+		}
+
+		first, err := Bytes(proposal)
+		require.NoError(t, err)
+		second, err := Bytes(proposal)
+		require.NoError(t, err)
+
+		require.Equal(t, first, second)
+
+		actual := &peer.Proposal{}
+		require.NoError(t, proto.Unmarshal(first, actual))
+		require.True(t, proto.Equal(proposal, actual))
+	})
+}
+
+func TestNewSignedProposal(t *testing.T) {
+	t.Run("Combines proposal bytes and signature", func(t *testing.T) {
+		proposal := &peer.Proposal{
+			Header:  []byte("HEADER"),
+			Payload: []byte("PAYLOAD"),
+		}
+		expectedBytes, err := Bytes(proposal)
+		require.NoError(t, err)
+
+		actual, err := NewSignedProposal(proposal, []byte("SIGNATURE"))
+		require.NoError(t, err)
+
+		require.Equal(t, expectedBytes, actual.GetProposalBytes())
+		require.Equal(t, []byte("SIGNATURE"), actual.GetSignature())
+	})
+}
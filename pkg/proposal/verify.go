@@ -0,0 +1,215 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proposal
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// VerifyEndorsement checks that a proposal response carries a valid endorsement from a member of one of the expected
+// MSPs. The endorser's MSP ID must match the name of one of the supplied MSP definitions, its certificate must chain
+// to a root certificate of that MSP without any certificate in the chain being revoked by the MSP's revocation lists,
+// and the endorsement signature over the response payload must be valid for the endorser's certificate. As with
+// Fabric, ECDSA signatures must be in low-S form.
+func VerifyEndorsement(response *peer.ProposalResponse, expectedMSPs ...*msp.FabricMSPConfig) error {
+	endorsement := response.GetEndorsement()
+	if endorsement == nil {
+		return errors.New("proposal response has no endorsement")
+	}
+
+	endorser := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(endorsement.GetEndorser(), endorser); err != nil {
+		return fmt.Errorf("failed to deserialize endorser identity: %w", err)
+	}
+
+	mspConfig := findMSP(endorser.GetMspid(), expectedMSPs)
+	if mspConfig == nil {
+		return fmt.Errorf("endorser MSP ID is not expected: %s", endorser.GetMspid())
+	}
+
+	certificate, err := parseCertificate(endorser.GetIdBytes())
+	if err != nil {
+		return fmt.Errorf("invalid endorser certificate: %w", err)
+	}
+
+	if err := verifyChain(certificate, mspConfig); err != nil {
+		return fmt.Errorf("endorser certificate is not issued by MSP %s: %w", endorser.GetMspid(), err)
+	}
+
+	message := make([]byte, 0, len(response.GetPayload())+len(endorsement.GetEndorser()))
+	message = append(message, response.GetPayload()...)
+	message = append(message, endorsement.GetEndorser()...)
+
+	if err := verifySignature(certificate.PublicKey, message, endorsement.GetSignature()); err != nil {
+		return fmt.Errorf("invalid endorsement signature from MSP %s: %w", endorser.GetMspid(), err)
+	}
+
+	return nil
+}
+
+func findMSP(mspID string, mspConfigs []*msp.FabricMSPConfig) *msp.FabricMSPConfig {
+	for _, mspConfig := range mspConfigs {
+		if mspConfig.GetName() == mspID {
+			return mspConfig
+		}
+	}
+
+	return nil
+}
+
+func parseCertificate(certificatePEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certificatePEM)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func verifyChain(certificate *x509.Certificate, mspConfig *msp.FabricMSPConfig) error {
+	roots, err := newCertPool(mspConfig.GetRootCerts())
+	if err != nil {
+		return err
+	}
+
+	intermediates, err := newCertPool(mspConfig.GetIntermediateCerts())
+	if err != nil {
+		return err
+	}
+
+	chains, err := certificate.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return err
+	}
+
+	revocationLists, err := parseRevocationLists(mspConfig.GetRevocationList())
+	if err != nil {
+		return err
+	}
+
+	return checkRevocation(chains[0], revocationLists)
+}
+
+func parseRevocationLists(crlPEMs [][]byte) ([]*x509.RevocationList, error) {
+	results := make([]*x509.RevocationList, 0, len(crlPEMs))
+
+	for _, crlPEM := range crlPEMs {
+		block, _ := pem.Decode(crlPEM)
+		if block == nil {
+			return nil, errors.New("invalid MSP revocation list: no PEM data found")
+		}
+
+		revocationList, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MSP revocation list: %w", err)
+		}
+
+		results = append(results, revocationList)
+	}
+
+	return results, nil
+}
+
+// checkRevocation checks that no certificate in a verified chain, other than the root, is revoked by a revocation list
+// signed by its issuer.
+func checkRevocation(chain []*x509.Certificate, revocationLists []*x509.RevocationList) error {
+	for i := 0; i < len(chain)-1; i++ {
+		certificate := chain[i]
+		issuer := chain[i+1]
+
+		for _, revocationList := range revocationLists {
+			if !bytes.Equal(revocationList.RawIssuer, issuer.RawSubject) || revocationList.CheckSignatureFrom(issuer) != nil {
+				continue
+			}
+
+			for _, revoked := range revocationList.RevokedCertificates {
+				if revoked.SerialNumber.Cmp(certificate.SerialNumber) == 0 {
+					return fmt.Errorf("certificate with serial number %s has been revoked", certificate.SerialNumber)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func newCertPool(certificatePEMs [][]byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+
+	for _, certificatePEM := range certificatePEMs {
+		certificate, err := parseCertificate(certificatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MSP certificate: %w", err)
+		}
+		pool.AddCert(certificate)
+	}
+
+	return pool, nil
+}
+
+func verifySignature(publicKey crypto.PublicKey, message []byte, signature []byte) error {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if err := checkLowS(key, signature); err != nil {
+			return err
+		}
+		digest := sha256.Sum256(message)
+		if !ecdsa.VerifyASN1(key, digest[:], signature) {
+			return errors.New("signature verification failed for ECDSA key")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, message, signature) {
+			return errors.New("signature verification failed for Ed25519 key")
+		}
+		return nil
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	default:
+		return fmt.Errorf("unsupported public key type: %T", publicKey)
+	}
+}
+
+// checkLowS rejects ECDSA signatures whose S value is greater than half the curve order. Fabric accepts only the low-S
+// form, since (r, N-s) is also a valid signature and would otherwise make signatures malleable.
+func checkLowS(publicKey *ecdsa.PublicKey, signature []byte) error {
+	var ecdsaSignature struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(signature, &ecdsaSignature); err != nil {
+		return fmt.Errorf("invalid ECDSA signature: %w", err)
+	}
+	if ecdsaSignature.S == nil || ecdsaSignature.S.Sign() <= 0 {
+		return errors.New("invalid ECDSA signature: S must be positive")
+	}
+
+	halfOrder := new(big.Int).Rsh(publicKey.Curve.Params().N, 1)
+	if ecdsaSignature.S.Cmp(halfOrder) > 0 {
+		return errors.New("invalid ECDSA signature: S must not be greater than half the curve order")
+	}
+
+	return nil
+}
@@ -0,0 +1,260 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proposal
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+type testCA struct {
+	certificate *x509.Certificate
+	privateKey  crypto.Signer
+}
+
+func NewTestCA(t *testing.T) *testCA {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(certificateDER)
+	require.NoError(t, err)
+
+	return &testCA{certificate: certificate, privateKey: privateKey}
+}
+
+func (ca *testCA) CertificatePEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.certificate.Raw})
+}
+
+func (ca *testCA) MSP(mspID string) *msp.FabricMSPConfig {
+	return &msp.FabricMSPConfig{
+		Name:      mspID,
+		RootCerts: [][]byte{ca.CertificatePEM()},
+	}
+}
+
+// RevocationListPEM creates a CRL, signed by the CA, revoking the supplied serial numbers
+func (ca *testCA) RevocationListPEM(t *testing.T, serialNumbers ...int64) []byte {
+	template := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now(),
+		NextUpdate: time.Now().Add(time.Hour),
+	}
+	for _, serialNumber := range serialNumbers {
+		template.RevokedCertificates = append(template.RevokedCertificates, pkix.RevokedCertificate{
+			SerialNumber:   big.NewInt(serialNumber),
+			RevocationTime: time.Now(),
+		})
+	}
+
+	crl, err := x509.CreateRevocationList(rand.Reader, template, ca.certificate, ca.privateKey)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl})
+}
+
+func (ca *testCA) IssueCertificatePEM(t *testing.T, publicKey crypto.PublicKey) []byte {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "peer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, publicKey, ca.privateKey)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateDER})
+}
+
+// NewEndorsedResponse creates a proposal response endorsed by an ECDSA identity issued by the supplied CA
+func NewEndorsedResponse(t *testing.T, ca *testCA, mspID string, payload []byte) *peer.ProposalResponse {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	endorser := NewEndorser(t, mspID, ca.IssueCertificatePEM(t, privateKey.Public()))
+
+	digest := sha256.Sum256(append(append([]byte{}, payload...), endorser...))
+	r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest[:])
+	require.NoError(t, err)
+
+	// Fabric signers produce low-S signatures
+	curveOrder := privateKey.Curve.Params().N
+	if s.Cmp(new(big.Int).Rsh(curveOrder, 1)) > 0 {
+		s.Sub(curveOrder, s)
+	}
+
+	return &peer.ProposalResponse{
+		Payload: payload,
+		Endorsement: &peer.Endorsement{
+			Endorser:  endorser,
+			Signature: MarshalECDSASignature(t, r, s),
+		},
+	}
+}
+
+func MarshalECDSASignature(t *testing.T, r *big.Int, s *big.Int) []byte {
+	signature, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	require.NoError(t, err)
+	return signature
+}
+
+func NewEndorser(t *testing.T, mspID string, certificatePEM []byte) []byte {
+	endorser, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: certificatePEM,
+	})
+	require.NoError(t, err)
+	return endorser
+}
+
+func TestVerifyEndorsement(t *testing.T) {
+	payload := []byte("PAYLOAD")
+
+	t.Run("Valid endorsement", func(t *testing.T) {
+		ca := NewTestCA(t)
+		response := NewEndorsedResponse(t, ca, "Org1MSP", payload)
+
+		err := VerifyEndorsement(response, NewTestCA(t).MSP("Org2MSP"), ca.MSP("Org1MSP"))
+		require.NoError(t, err)
+	})
+
+	t.Run("Valid Ed25519 endorsement", func(t *testing.T) {
+		ca := NewTestCA(t)
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		endorser := NewEndorser(t, "Org1MSP", ca.IssueCertificatePEM(t, publicKey))
+		response := &peer.ProposalResponse{
+			Payload: payload,
+			Endorsement: &peer.Endorsement{
+				Endorser:  endorser,
+				Signature: ed25519.Sign(privateKey, append(append([]byte{}, payload...), endorser...)),
+			},
+		}
+
+		require.NoError(t, VerifyEndorsement(response, ca.MSP("Org1MSP")))
+	})
+
+	t.Run("Missing endorsement gives error", func(t *testing.T) {
+		err := VerifyEndorsement(&peer.ProposalResponse{}, NewTestCA(t).MSP("Org1MSP"))
+		require.ErrorContains(t, err, "no endorsement")
+	})
+
+	t.Run("Unexpected MSP ID gives error", func(t *testing.T) {
+		ca := NewTestCA(t)
+		response := NewEndorsedResponse(t, ca, "Org1MSP", payload)
+
+		err := VerifyEndorsement(response, ca.MSP("Org2MSP"))
+		require.ErrorContains(t, err, "Org1MSP")
+	})
+
+	t.Run("Certificate from another CA gives error", func(t *testing.T) {
+		response := NewEndorsedResponse(t, NewTestCA(t), "Org1MSP", payload)
+
+		err := VerifyEndorsement(response, NewTestCA(t).MSP("Org1MSP"))
+		require.ErrorContains(t, err, "not issued by MSP Org1MSP")
+	})
+
+	t.Run("Modified payload gives error", func(t *testing.T) {
+		ca := NewTestCA(t)
+		response := NewEndorsedResponse(t, ca, "Org1MSP", payload)
+		response.Payload = []byte("TAMPERED")
+
+		err := VerifyEndorsement(response, ca.MSP("Org1MSP"))
+		require.ErrorContains(t, err, "invalid endorsement signature")
+	})
+
+	t.Run("Invalid endorser certificate gives error", func(t *testing.T) {
+		response := &peer.ProposalResponse{
+			Endorsement: &peer.Endorsement{
+				Endorser: NewEndorser(t, "Org1MSP", []byte("NOT_A_CERTIFICATE")),
+			},
+		}
+
+		err := VerifyEndorsement(response, NewTestCA(t).MSP("Org1MSP"))
+		require.ErrorContains(t, err, "invalid endorser certificate")
+	})
+	t.Run("Revoked endorser certificate gives error", func(t *testing.T) {
+		ca := NewTestCA(t)
+		response := NewEndorsedResponse(t, ca, "Org1MSP", payload)
+		mspConfig := ca.MSP("Org1MSP")
+		mspConfig.RevocationList = [][]byte{ca.RevocationListPEM(t, 2)}
+
+		err := VerifyEndorsement(response, mspConfig)
+		require.ErrorContains(t, err, "revoked")
+	})
+
+	t.Run("Revocation list for other serial numbers allows endorsement", func(t *testing.T) {
+		ca := NewTestCA(t)
+		response := NewEndorsedResponse(t, ca, "Org1MSP", payload)
+		mspConfig := ca.MSP("Org1MSP")
+		mspConfig.RevocationList = [][]byte{ca.RevocationListPEM(t, 3)}
+
+		require.NoError(t, VerifyEndorsement(response, mspConfig))
+	})
+
+	t.Run("Revocation list not signed by issuer is ignored", func(t *testing.T) {
+		ca := NewTestCA(t)
+		response := NewEndorsedResponse(t, ca, "Org1MSP", payload)
+		mspConfig := ca.MSP("Org1MSP")
+		mspConfig.RevocationList = [][]byte{NewTestCA(t).RevocationListPEM(t, 2)}
+
+		require.NoError(t, VerifyEndorsement(response, mspConfig))
+	})
+
+	t.Run("Invalid revocation list gives error", func(t *testing.T) {
+		ca := NewTestCA(t)
+		response := NewEndorsedResponse(t, ca, "Org1MSP", payload)
+		mspConfig := ca.MSP("Org1MSP")
+		mspConfig.RevocationList = [][]byte{[]byte("NOT_A_CRL")}
+
+		err := VerifyEndorsement(response, mspConfig)
+		require.ErrorContains(t, err, "revocation list")
+	})
+
+	t.Run("High-S ECDSA signature gives error", func(t *testing.T) {
+		ca := NewTestCA(t)
+		response := NewEndorsedResponse(t, ca, "Org1MSP", payload)
+
+		var signature struct{ R, S *big.Int }
+		_, err := asn1.Unmarshal(response.GetEndorsement().GetSignature(), &signature)
+		require.NoError(t, err)
+		highS := new(big.Int).Sub(elliptic.P256().Params().N, signature.S)
+		response.Endorsement.Signature = MarshalECDSASignature(t, signature.R, highS)
+
+		err = VerifyEndorsement(response, ca.MSP("Org1MSP"))
+		require.ErrorContains(t, err, "half the curve order")
+	})
+}
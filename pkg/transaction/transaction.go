@@ -0,0 +1,52 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package transaction provides support for assembling endorsed proposals into transactions that can be submitted to
+// an orderer.
+//
+// A transaction is created from a proposal and the endorsed proposal responses obtained from peers. The transaction
+// can either be signed directly using New, or signed separately, such as on an offline machine holding the signing
+// key. To sign separately, an unsigned payload is created using NewPayload, the bytes returned by PayloadBytes are
+// signed, and the payload and signature are combined using NewSignedEnvelope.
+package transaction
+
+import (
+	"context"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/proposal"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+)
+
+// New creates a transaction envelope from a proposal and the endorsed responses to that proposal, signed using the
+// supplied signing identity. The signing identity must be the creator of the proposal. All responses must be
+// successful and contain identical response payloads.
+func New(
+	ctx context.Context,
+	signingID identity.SigningIdentity,
+	p *peer.Proposal,
+	responses ...*peer.ProposalResponse,
+) (*common.Envelope, error) {
+	return proposal.NewTransaction(ctx, signingID, p, responses...)
+}
+
+// NewPayload creates an unsigned transaction payload from a proposal and the endorsed responses to that proposal.
+// Transient data included in the proposal is not included in the transaction.
+func NewPayload(p *peer.Proposal, responses ...*peer.ProposalResponse) (*common.Payload, error) {
+	return proposal.NewTransactionPayload(p, responses...)
+}
+
+// PayloadBytes returns the serialized transaction payload that must be signed. Serialization is deterministic, so the
+// same payload always gives the same bytes.
+func PayloadBytes(payload *common.Payload) ([]byte, error) {
+	return proposal.TransactionPayloadBytes(payload)
+}
+
+// NewSignedEnvelope combines a transaction payload with a signature of its serialized bytes, obtained using
+// PayloadBytes, to create a transaction envelope that can be submitted to an orderer.
+func NewSignedEnvelope(payload *common.Payload, signature []byte) (*common.Envelope, error) {
+	return proposal.NewSignedTransaction(payload, signature)
+}
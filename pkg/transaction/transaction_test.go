@@ -0,0 +1,239 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bestbeforetoday/fabric-admin/internal/proposal"
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:generate mockgen -destination ./signingidentity_mock_test.go -package ${GOPACKAGE} github.com/bestbeforetoday/fabric-admin/pkg/identity SigningIdentity
+
+func NewSigningIdentity(controller *gomock.Controller, mspID string, signature []byte) *MockSigningIdentity {
+	mockIdentity := NewMockSigningIdentity(controller)
+	mockIdentity.EXPECT().MspID().Return(mspID).AnyTimes()
+	mockIdentity.EXPECT().Credentials().Return([]byte("SIGNER_CREDENTIALS")).AnyTimes()
+	mockIdentity.EXPECT().Sign(gomock.Any()).Return(signature, nil).AnyTimes()
+
+	return mockIdentity
+}
+
+func NewProposal(t *testing.T, controller *gomock.Controller) *peer.Proposal {
+	result, err := proposal.New(
+		NewSigningIdentity(controller, "SIGNER_MSP", nil),
+		"CHAINCODE",
+		"TRANSACTION",
+		proposal.WithChannel("CHANNEL"),
+		proposal.WithArguments("ARG"),
+		proposal.WithTransient(map[string][]byte{"KEY": []byte("SECRET")}),
+	)
+	require.NoError(t, err)
+
+	return result
+}
+
+func NewResponse(payload []byte, endorser string) *peer.ProposalResponse {
+	return &peer.ProposalResponse{
+		Response: &peer.Response{
+			Status: int32(common.Status_SUCCESS),
+		},
+		Payload: payload,
+		Endorsement: &peer.Endorsement{
+			Endorser:  []byte(endorser),
+			Signature: []byte(endorser + "_SIGNATURE"),
+		},
+	}
+}
+
+// AssertUnmarshal ensures that a protobuf is umarshaled without error
+func AssertUnmarshal(t *testing.T, b []byte, m protoreflect.ProtoMessage) {
+	err := proto.Unmarshal(b, m)
+	require.NoError(t, err)
+}
+
+// AssertProtoEqual ensures an expected protobuf message matches an actual message
+func AssertProtoEqual(t *testing.T, expected protoreflect.ProtoMessage, actual protoreflect.ProtoMessage) {
+	require.True(t, proto.Equal(expected, actual), "Expected %v, got %v", expected, actual)
+}
+
+// AssertUnmarshalAction ensures that the chaincode action payload is unmarshaled from a transaction payload without
+// error
+func AssertUnmarshalAction(t *testing.T, payload *common.Payload) (*peer.TransactionAction, *peer.ChaincodeActionPayload) {
+	transaction := &peer.Transaction{}
+	AssertUnmarshal(t, payload.GetData(), transaction)
+	require.Len(t, transaction.GetActions(), 1)
+
+	actionPayload := &peer.ChaincodeActionPayload{}
+	AssertUnmarshal(t, transaction.GetActions()[0].GetPayload(), actionPayload)
+
+	return transaction.GetActions()[0], actionPayload
+}
+
+func TestNewPayload(t *testing.T) {
+	t.Run("No responses gives error", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		_, err := NewPayload(NewProposal(t, controller))
+		require.ErrorContains(t, err, "no proposal responses")
+	})
+
+	t.Run("Unsuccessful response gives error", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		failed := NewResponse([]byte("PAYLOAD"), "PEER2")
+		failed.Response = &peer.Response{
+			Status:  int32(common.Status_INTERNAL_SERVER_ERROR),
+			Message: "MESSAGE",
+		}
+
+		_, err := NewPayload(NewProposal(t, controller), NewResponse([]byte("PAYLOAD"), "PEER1"), failed)
+		require.ErrorContains(t, err, "MESSAGE")
+	})
+
+	t.Run("Mismatched response payloads gives error", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		_, err := NewPayload(
+			NewProposal(t, controller),
+			NewResponse([]byte("PAYLOAD"), "PEER1"),
+			NewResponse([]byte("DIFFERENT"), "PEER2"),
+		)
+		require.ErrorContains(t, err, "does not match")
+	})
+
+	t.Run("Missing endorsement gives error", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		response := NewResponse([]byte("PAYLOAD"), "PEER1")
+		response.Endorsement = nil
+
+		_, err := NewPayload(NewProposal(t, controller), response)
+		require.ErrorContains(t, err, "no endorsement")
+	})
+
+	t.Run("Uses proposal header", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		p := NewProposal(t, controller)
+
+		actual, err := NewPayload(p, NewResponse([]byte("PAYLOAD"), "PEER1"))
+		require.NoError(t, err)
+
+		expected := &common.Header{}
+		AssertUnmarshal(t, p.GetHeader(), expected)
+		AssertProtoEqual(t, expected, actual.GetHeader())
+
+		action, _ := AssertUnmarshalAction(t, actual)
+		require.Equal(t, expected.GetSignatureHeader(), action.GetHeader(), "action header")
+	})
+
+	t.Run("Includes response payload and all endorsements", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		responses := []*peer.ProposalResponse{
+			NewResponse([]byte("PAYLOAD"), "PEER1"),
+			NewResponse([]byte("PAYLOAD"), "PEER2"),
+		}
+
+		actual, err := NewPayload(NewProposal(t, controller), responses...)
+		require.NoError(t, err)
+
+		_, actionPayload := AssertUnmarshalAction(t, actual)
+		endorsedAction := actionPayload.GetAction()
+		require.Equal(t, []byte("PAYLOAD"), endorsedAction.GetProposalResponsePayload())
+		require.Len(t, endorsedAction.GetEndorsements(), len(responses))
+		for i, response := range responses {
+			AssertProtoEqual(t, response.GetEndorsement(), endorsedAction.GetEndorsements()[i])
+		}
+	})
+
+	t.Run("Transient data is removed from chaincode proposal payload", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		p := NewProposal(t, controller)
+
+		actual, err := NewPayload(p, NewResponse([]byte("PAYLOAD"), "PEER1"))
+		require.NoError(t, err)
+
+		_, actionPayload := AssertUnmarshalAction(t, actual)
+		chaincodeProposalPayload := &peer.ChaincodeProposalPayload{}
+		AssertUnmarshal(t, actionPayload.GetChaincodeProposalPayload(), chaincodeProposalPayload)
+
+		expected := &peer.ChaincodeProposalPayload{}
+		AssertUnmarshal(t, p.GetPayload(), expected)
+		require.NotEmpty(t, expected.GetTransientMap())
+
+		require.Empty(t, chaincodeProposalPayload.GetTransientMap())
+		require.Equal(t, expected.GetInput(), chaincodeProposalPayload.GetInput())
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("Signs transaction payload", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		p := NewProposal(t, controller)
+		response := NewResponse([]byte("PAYLOAD"), "PEER1")
+
+		actual, err := New(ctx, NewSigningIdentity(controller, "SIGNER_MSP", []byte("SIGNATURE")), p, response)
+		require.NoError(t, err)
+
+		require.Equal(t, []byte("SIGNATURE"), actual.GetSignature())
+
+		expected, err := NewPayload(p, response)
+		require.NoError(t, err)
+		payload := &common.Payload{}
+		AssertUnmarshal(t, actual.GetPayload(), payload)
+		AssertProtoEqual(t, expected, payload)
+	})
+
+	t.Run("Signer that is not proposal creator gives error", func(t *testing.T) {
+		controller, ctx := gomock.WithContext(context.Background(), t)
+		defer controller.Finish()
+
+		_, err := New(
+			ctx,
+			NewSigningIdentity(controller, "OTHER_MSP", []byte("SIGNATURE")),
+			NewProposal(t, controller),
+			NewResponse([]byte("PAYLOAD"), "PEER1"),
+		)
+		require.ErrorContains(t, err, "not the proposal creator")
+	})
+}
+
+func TestNewSignedEnvelope(t *testing.T) {
+	t.Run("Combines payload bytes and signature", func(t *testing.T) {
+		controller := gomock.NewController(t)
+		defer controller.Finish()
+
+		payload, err := NewPayload(NewProposal(t, controller), NewResponse([]byte("PAYLOAD"), "PEER1"))
+		require.NoError(t, err)
+		expectedBytes, err := PayloadBytes(payload)
+		require.NoError(t, err)
+
+		actual, err := NewSignedEnvelope(payload, []byte("SIGNATURE"))
+		require.NoError(t, err)
+
+		require.Equal(t, expectedBytes, actual.GetPayload())
+		require.Equal(t, []byte("SIGNATURE"), actual.GetSignature())
+	})
+}
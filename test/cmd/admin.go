@@ -13,7 +13,6 @@ import (
 	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/install"
 	"github.com/bestbeforetoday/fabric-admin/pkg/chaincode/queryinstalled"
 	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
-	"github.com/hyperledger/fabric-gateway/pkg/hash"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
@@ -26,7 +25,7 @@ func main() {
 
 	r := &runner{
 		grpcConnection: grpcConnection,
-		signingID:      identity.NewSigningIdentity(newIdentity(), newSign(), hash.SHA256),
+		signingID:      newSigningIdentity(),
 	}
 
 	r.install()
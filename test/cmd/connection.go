@@ -6,11 +6,11 @@ SPDX-License-Identifier: Apache-2.0
 package main
 
 import (
-	"crypto/x509"
 	"fmt"
 	"os"
 
-	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/bestbeforetoday/fabric-admin/pkg/connection"
+	"github.com/bestbeforetoday/fabric-admin/pkg/identity"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -19,71 +19,42 @@ const (
 	mspID         = "Org1MSP"
 	clientCertEnv = "CLIENT_CERT"
 	clientKeyEnv  = "CLIENT_KEY"
-	caCertEnv     = "CA_CERT"
+	tlsCACertEnv  = "TLS_CA_CERT"
+	tlsCertEnv    = "TLS_CLIENT_CERT"
+	tlsKeyEnv     = "TLS_CLIENT_KEY"
 	endpointEnv   = "ENDPOINT"
 )
 
-// newGrpcConnection creates a gRPC connection to the Gateway server.
+// newGrpcConnection creates a gRPC connection to the Gateway server. TLS is used if a TLS CA certificate is specified,
+// with mutual TLS if a client TLS certificate and key are also specified.
 func newGrpcConnection() *grpc.ClientConn {
-	// certificate, err := loadCertificate(os.Getenv(caCertEnv))
-	// if err != nil {
-	// 	panic(err)
-	// }
-
-	// certPool := x509.NewCertPool()
-	// certPool.AddCert(certificate)
-	// transportCredentials := credentials.NewClientTLSFromCert(certPool, "")
-
-	transportCredentials := insecure.NewCredentials()
-
-	connection, err := grpc.Dial(os.Getenv(endpointEnv), grpc.WithTransportCredentials(transportCredentials))
-	if err != nil {
-		panic(fmt.Errorf("failed to create gRPC connection: %w", err))
+	if tlsCACert := os.Getenv(tlsCACertEnv); tlsCACert != "" {
+		tlsConnection, err := connection.NewTLSConnection(os.Getenv(endpointEnv), connection.TLSFiles{
+			CACert:     tlsCACert,
+			ClientCert: os.Getenv(tlsCertEnv),
+			ClientKey:  os.Getenv(tlsKeyEnv),
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		return tlsConnection
 	}
 
-	return connection
-}
-
-// newIdentity creates a client identity for this Gateway connection using an X.509 certificate.
-func newIdentity() *identity.X509Identity {
-	certificate, err := loadCertificate(os.Getenv(clientCertEnv))
+	insecureConnection, err := grpc.Dial(os.Getenv(endpointEnv), grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
-		panic(err)
-	}
-
-	id, err := identity.NewX509Identity(mspID, certificate)
-	if err != nil {
-		panic(err)
+		panic(fmt.Errorf("failed to create gRPC connection: %w", err))
 	}
 
-	return id
+	return insecureConnection
 }
 
-func loadCertificate(filename string) (*x509.Certificate, error) {
-	certificatePEM, err := os.ReadFile(filename) //#nosec G304 -- test input
-	if err != nil {
-		return nil, fmt.Errorf("failed to read certificate file: %w", err)
-	}
-	return identity.CertificateFromPEM(certificatePEM)
-}
-
-// newSign creates a function that generates a digital signature from a message digest using a private key.
-func newSign() identity.Sign {
-	privateKeyPEM, err := os.ReadFile(os.Getenv(clientKeyEnv))
-
-	if err != nil {
-		panic(fmt.Errorf("failed to read private key file: %w", err))
-	}
-
-	privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)
-	if err != nil {
-		panic(err)
-	}
-
-	sign, err := identity.NewPrivateKeySign(privateKey)
+// newSigningIdentity creates a client signing identity using an X.509 certificate and private key.
+func newSigningIdentity() identity.SigningIdentity {
+	signingID, err := identity.NewSigningIdentityFromFiles(mspID, os.Getenv(clientCertEnv), os.Getenv(clientKeyEnv))
 	if err != nil {
 		panic(err)
 	}
 
-	return sign
+	return signingID
 }